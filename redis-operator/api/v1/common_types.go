@@ -4,10 +4,13 @@ import (
 	"bufio"
 	"encoding/json"
 	"fmt"
+	"strconv"
 	"strings"
 
 	"github.com/stretchr/objx"
 	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 )
 
@@ -27,6 +30,20 @@ func (r *StatefulSetConfiguration) GetReplicas() int {
 	}
 }
 
+// isNamespaceAllowed returns whether resources of namespace may reference a resource of owner allowing the
+// namespaces in allowed
+func isNamespaceAllowed(owner string, allowed []string, namespace string) bool {
+	if namespace == owner {
+		return true
+	}
+	for _, allowedNamespace := range allowed {
+		if allowedNamespace == "*" || allowedNamespace == namespace {
+			return true
+		}
+	}
+	return false
+}
+
 type RedisConfigurationData struct {
 	Data map[string]string `json:"data"`
 }
@@ -39,52 +56,69 @@ type TLSConfig struct {
 	PassPhrase string
 }
 
-func (r *RedisConfigurationData) GetConfigMapTLS() (*TLSConfig, error) {
+// getConfigMapTLS reads the tls files and the password from the given config file
+func (r *RedisConfigurationData) getConfigMapTLS(file string) (*TLSConfig, error) {
 	tls := TLSConfig{}
 	var err error
 
-	if tls.Cert, err = r.GetValue("redis.conf", "tls-client-cert-file"); err != nil {
+	if tls.Cert, err = r.GetValue(file, "tls-client-cert-file"); err != nil {
 		return nil, err
 	}
 	if tls.Cert == "" {
-		if tls.Cert, err = r.GetValue("redis.conf", "tls-cert-file"); err != nil {
+		if tls.Cert, err = r.GetValue(file, "tls-cert-file"); err != nil {
 			return nil, err
 		}
 	}
 
-	if tls.Key, err = r.GetValue("redis.conf", "tls-client-key-file"); err != nil {
+	if tls.Key, err = r.GetValue(file, "tls-client-key-file"); err != nil {
 		return nil, err
 	}
 	if tls.Key == "" {
-		if tls.Key, err = r.GetValue("redis.conf", "tls-key-file"); err != nil {
+		if tls.Key, err = r.GetValue(file, "tls-key-file"); err != nil {
 			return nil, err
 		}
 	}
 
-	if tls.PassPhrase, err = r.GetValue("redis.conf", "tls-client-passphrase"); err != nil {
+	if tls.PassPhrase, err = r.GetValue(file, "tls-client-passphrase"); err != nil {
 		return nil, err
 	}
 	if tls.PassPhrase == "" {
-		if tls.PassPhrase, err = r.GetValue("redis.conf", "tls-passphrase"); err != nil {
+		if tls.PassPhrase, err = r.GetValue(file, "tls-passphrase"); err != nil {
 			return nil, err
 		}
 	}
 
-	if tls.CACert, err = r.GetValue("redis.conf", "tls-ca-cert-file"); err != nil {
+	if tls.CACert, err = r.GetValue(file, "tls-ca-cert-file"); err != nil {
 		return nil, err
 	}
 
-	if tls.Password, err = r.GetValue("redis.conf", "requirepass"); err != nil {
+	if tls.Password, err = r.GetValue(file, "requirepass"); err != nil {
 		return nil, err
 	}
 
-	if tls.Cert == "" || tls.Key == "" || tls.CACert == "" || tls.Password == "" {
+	if tls.Cert == "" || tls.Key == "" || tls.CACert == "" {
 		return nil, fmt.Errorf("error, missing tls key. %v", tls)
 	}
 
 	return &tls, nil
 }
 
+func (r *RedisConfigurationData) GetConfigMapTLS() (*TLSConfig, error) {
+	tls, err := r.getConfigMapTLS("redis.conf")
+	if err != nil {
+		return nil, err
+	}
+	if tls.Password == "" {
+		return nil, fmt.Errorf("error, missing tls key. %v", *tls)
+	}
+	return tls, nil
+}
+
+// GetSentinelConfigMapTLS reads the tls files of a sentinel. Unlike redis, a sentinel may run without a password
+func (r *RedisConfigurationData) GetSentinelConfigMapTLS() (*TLSConfig, error) {
+	return r.getConfigMapTLS("sentinel.conf")
+}
+
 func (r *RedisConfigurationData) GetValues(key string, substr string) ([]string, error) {
 	if foundValue, ok := r.Data[key]; ok {
 		substrFields := strings.Fields(substr)
@@ -245,6 +279,189 @@ type StatefulSetMetadataWrapper struct {
 }
 
 type RedisTLSConfiguration struct {
-	Name       string `json:"name"`
-	SecretName string `json:"secretName"`
+	Name string `json:"name"`
+	// Defaults to <name>-tls when issuerRef is set
+	//+optional
+	SecretName string `json:"secretName,omitempty"`
+	// cert-manager issuer used to create a certificate for the instance. The operator owns the Certificate
+	// and keeps its SANs in line with the services of the instance
+	//+optional
+	IssuerRef *CertManagerIssuerReference `json:"issuerRef,omitempty"`
+	// Defaults to the issuer default
+	//+optional
+	Duration *metav1.Duration `json:"duration,omitempty"`
+	//+optional
+	RenewBefore *metav1.Duration `json:"renewBefore,omitempty"`
+	// tls-auth-clients of the server. With no, the operator connects without a client certificate
+	//+optional
+	//+kubebuilder:validation:Enum=yes;no;optional
+	AuthClients string `json:"authClients,omitempty"`
+	// lowest tls version accepted by the server and used by the operator. Defaults to TLSv1.2
+	//+optional
+	//+kubebuilder:validation:Enum=TLSv1.2;TLSv1.3
+	MinVersion string `json:"minVersion,omitempty"`
+	// IANA cipher suite names, e.g. TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256 or TLS_AES_128_GCM_SHA256.
+	// TLS 1.2 suites are written to tls-ciphers and TLS 1.3 suites to tls-ciphersuites
+	//+optional
+	CipherSuites []string `json:"cipherSuites,omitempty"`
+	// Dual keeps the plaintext port open next to tls-port and replicates without tls while clients migrate.
+	// Switching to TLS closes the plaintext port and enables tls-replication with a rolling restart.
+	// The config is left untouched when not set
+	//+optional
+	//+kubebuilder:validation:Enum=Dual;TLS
+	Mode string `json:"mode,omitempty"`
+	// plaintext port opened in Dual mode. Defaults to the port of the config, or the default port of the server
+	//+optional
+	//+kubebuilder:validation:Minimum=1
+	//+kubebuilder:validation:Maximum=65535
+	PlaintextPort *int32 `json:"plaintextPort,omitempty"`
+}
+
+const (
+	TLSModeDual = "Dual"
+	TLSModeTLS  = "TLS"
+
+	// pod template annotations, a changed mode rolls the pods so they start with the new ports and tls-replication
+	TLSModeAnnotation            = "redis.redis.operator/tls-mode"
+	TLSReplicationModeAnnotation = "redis.redis.operator/tls-replication-mode"
+)
+
+// GetMode returns the migration mode, empty when the ports are configured by the config only
+func (r *RedisTLSConfiguration) GetMode() string {
+	if r == nil {
+		return ""
+	}
+	return r.Mode
+}
+
+func (r *RedisTLSConfiguration) IsDualMode() bool {
+	return r.GetMode() == TLSModeDual
+}
+
+// getPlaintextPort returns the plaintext port used in Dual mode
+func (r *RedisTLSConfiguration) getPlaintextPort(configPort string, defaultPort string) string {
+	if r != nil && r.PlaintextPort != nil {
+		return strconv.Itoa(int(*r.PlaintextPort))
+	}
+	if configPort != "" && configPort != "0" {
+		return configPort
+	}
+	return defaultPort
+}
+
+func (r *RedisTLSConfiguration) GetAuthClients() string {
+	if r == nil || r.AuthClients == "" {
+		return "yes"
+	}
+	return r.AuthClients
+}
+
+func (r *RedisTLSConfiguration) GetMinVersion() string {
+	if r == nil || r.MinVersion == "" {
+		return "TLSv1.2"
+	}
+	return r.MinVersion
+}
+
+type CertManagerIssuerReference struct {
+	Name string `json:"name"`
+	// Issuer or ClusterIssuer. Defaults to Issuer
+	//+optional
+	Kind string `json:"kind,omitempty"`
+	// Defaults to cert-manager.io
+	//+optional
+	Group string `json:"group,omitempty"`
+}
+
+// RedisMonitoringConfiguration configures the Prometheus Operator resources created for instances with the exporter
+// enabled. The resources are skipped when the monitoring.coreos.com CRDs are not installed
+type RedisMonitoringConfiguration struct {
+	//+optional
+	ServiceMonitor *ServiceMonitorConfiguration `json:"serviceMonitor,omitempty"`
+	//+optional
+	PrometheusRule *PrometheusRuleConfiguration `json:"prometheusRule,omitempty"`
+}
+
+type ServiceMonitorConfiguration struct {
+	// Defaults to true
+	//+optional
+	Enabled *bool `json:"enabled,omitempty"`
+	// extra labels, usually needed to match the serviceMonitorSelector of the Prometheus instance
+	//+optional
+	Labels map[string]string `json:"labels,omitempty"`
+	// Defaults to 30s
+	//+optional
+	Interval string `json:"interval,omitempty"`
+	// Defaults to 10s
+	//+optional
+	ScrapeTimeout string `json:"scrapeTimeout,omitempty"`
+}
+
+type PrometheusRuleConfiguration struct {
+	// Defaults to true
+	//+optional
+	Enabled *bool `json:"enabled,omitempty"`
+	// extra labels, usually needed to match the ruleSelector of the Prometheus instance
+	//+optional
+	Labels map[string]string `json:"labels,omitempty"`
+	// used memory as a percentage of maxmemory before the memory alert fires. Defaults to 90
+	//+optional
+	//+kubebuilder:validation:Minimum=1
+	//+kubebuilder:validation:Maximum=100
+	MemoryThreshold *int `json:"memoryThreshold,omitempty"`
+	// how long a condition has to hold before the alert fires. Defaults to 5m
+	//+optional
+	For string `json:"for,omitempty"`
+	// severity label added to the alerts. Defaults to warning
+	//+optional
+	Severity string `json:"severity,omitempty"`
+}
+
+func (r *RedisMonitoringConfiguration) IsServiceMonitorEnabled() bool {
+	if r == nil || r.ServiceMonitor == nil || r.ServiceMonitor.Enabled == nil {
+		return true
+	}
+	return *r.ServiceMonitor.Enabled
+}
+
+func (r *RedisMonitoringConfiguration) IsPrometheusRuleEnabled() bool {
+	if r == nil || r.PrometheusRule == nil || r.PrometheusRule.Enabled == nil {
+		return true
+	}
+	return *r.PrometheusRule.Enabled
+}
+
+// RedisExporterConfiguration configures the redis-exporter sidecar
+type RedisExporterConfiguration struct {
+	// Defaults to the EXPORTER_IMAGE environment variable of the operator
+	//+optional
+	Image string `json:"image,omitempty"`
+	// Defaults to 100m cpu and 100Mi memory
+	//+optional
+	Resources *corev1.ResourceRequirements `json:"resources,omitempty"`
+	// arguments appended to the exporter command line
+	//+optional
+	ExtraArgs []string `json:"extraArgs,omitempty"`
+	// key patterns exported with --check-keys
+	//+optional
+	CheckKeys []string `json:"checkKeys,omitempty"`
+	// lua script run by the exporter to collect extra metrics
+	//+optional
+	Script *corev1.ConfigMapKeySelector `json:"script,omitempty"`
+	// Defaults to 9121
+	//+optional
+	//+kubebuilder:validation:Minimum=1
+	//+kubebuilder:validation:Maximum=65535
+	Port *int32 `json:"port,omitempty"`
+	// secret holding the password used by the exporter. Defaults to the operator managed <name>-auth secret
+	// which is filled from requirepass
+	//+optional
+	PasswordSecret *corev1.SecretKeySelector `json:"passwordSecret,omitempty"`
+}
+
+func (r *RedisExporterConfiguration) GetPort() int32 {
+	if r == nil || r.Port == nil {
+		return 9121
+	}
+	return *r.Port
 }
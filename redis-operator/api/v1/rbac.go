@@ -1,10 +1,15 @@
 package v1
 
-// +kubebuilder:rbac:groups=redis.redis.operator,resources=redisreplications;redissentinels,verbs=get;list;watch;create;update;patch;delete
-// +kubebuilder:rbac:groups=redis.redis.operator,resources=redisreplications/status;redissentinels/status,verbs=get;update;patch
-// +kubebuilder:rbac:groups=redis.redis.operator,resources=redisreplications/finalizers;redissentinels/finalizers,verbs=update
+// +kubebuilder:rbac:groups=redis.redis.operator,resources=redisreplications;redissentinels;redisclusters,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=redis.redis.operator,resources=redisreplications/status;redissentinels/status;redisclusters/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=redis.redis.operator,resources=redisreplications/finalizers;redissentinels/finalizers;redisclusters/finalizers,verbs=update
 // +kubebuilder:rbac:groups=core,resources=events,verbs=create;patch
 // +kubebuilder:rbac:groups=apps,resources=statefulsets;endpoints;pods;events;secrets;configmaps,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=core,resources=pods;configmaps;secrets;services,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=core,resources=secrets,verbs=get;list;watch
 // +kubebuilder:rbac:groups=core,resources=services,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=core,resources=nodes,verbs=get;list;watch
+// +kubebuilder:rbac:groups=core,resources=namespaces,verbs=get;list;watch
+// +kubebuilder:rbac:groups=authorization.k8s.io,resources=subjectaccessreviews,verbs=create
+// +kubebuilder:rbac:groups=monitoring.coreos.com,resources=servicemonitors;prometheusrules,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=cert-manager.io,resources=certificates,verbs=get;list;watch;create;update;patch;delete
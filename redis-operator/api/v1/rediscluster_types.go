@@ -0,0 +1,269 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+	"redis.operator/pkg/kube/configmap"
+	"redis.operator/pkg/kube/dns"
+)
+
+// RedisClusterSpec defines the desired state of RedisCluster
+type RedisClusterSpec struct {
+	// number of shards. Every shard runs in its own statefulset and owns a share of the hash slots
+	//+kubebuilder:validation:Minimum=3
+	Shards int32 `json:"shards"`
+	// replicas of every shard master
+	//+optional
+	//+kubebuilder:validation:Minimum=0
+	ReplicasPerShard int32 `json:"replicasPerShard,omitempty"`
+	//+optional
+	Resources *corev1.ResourceRequirements `json:"resources,omitempty"`
+	//+optional
+	VolumeMounts []corev1.VolumeMount `json:"volumeMounts,omitempty"`
+	// statefulset options of every shard. replicas is set from replicasPerShard
+	StatefulsetConfig StatefulSetConfiguration `json:"statefulSet,omitempty"`
+	// redis.conf of every node. cluster-enabled and cluster-config-file are set by the operator
+	RedisConfig RedisClusterConfiguration `json:"config,omitempty"`
+	//+optional
+	TLSConfig *RedisTLSConfiguration `json:"tls,omitempty"`
+	//+optional
+	EnableExporter bool `json:"enableExporter,omitempty"`
+	//+optional
+	Exporter *RedisExporterConfiguration `json:"exporter,omitempty"`
+	// throttling of the slot migrations after the number of shards changed
+	//+optional
+	Resharding *RedisClusterReshardingConfiguration `json:"resharding,omitempty"`
+}
+
+type RedisClusterReshardingConfiguration struct {
+	// keys moved by a single MIGRATE, defaults to 100
+	//+optional
+	//+kubebuilder:validation:Minimum=1
+	KeysPerBatch *int32 `json:"keysPerBatch,omitempty"`
+	// slots moved before the status is updated and the next reconcile is scheduled, defaults to 16
+	//+optional
+	//+kubebuilder:validation:Minimum=1
+	SlotsPerReconcile *int32 `json:"slotsPerReconcile,omitempty"`
+	// timeout of a single MIGRATE in milliseconds, defaults to 5000
+	//+optional
+	//+kubebuilder:validation:Minimum=1
+	TimeoutMilliseconds *int32 `json:"timeoutMilliseconds,omitempty"`
+}
+
+func (r *RedisClusterReshardingConfiguration) GetKeysPerBatch() int {
+	if r == nil || r.KeysPerBatch == nil {
+		return 100
+	}
+	return int(*r.KeysPerBatch)
+}
+
+func (r *RedisClusterReshardingConfiguration) GetSlotsPerReconcile() int {
+	if r == nil || r.SlotsPerReconcile == nil {
+		return 16
+	}
+	return int(*r.SlotsPerReconcile)
+}
+
+func (r *RedisClusterReshardingConfiguration) GetTimeout() time.Duration {
+	if r == nil || r.TimeoutMilliseconds == nil {
+		return 5 * time.Second
+	}
+	return time.Duration(*r.TimeoutMilliseconds) * time.Millisecond
+}
+
+type RedisClusterConfiguration struct {
+	RedisConfigurationData `json:",inline"`
+}
+
+// RedisClusterStatus defines the observed state of RedisCluster
+type RedisClusterStatus struct {
+	// cluster_state reported by CLUSTER INFO, ok once every slot is served
+	//+optional
+	State string `json:"state,omitempty"`
+	//+optional
+	SlotsAssigned int `json:"slotsAssigned,omitempty"`
+	//+optional
+	SlotsOK int `json:"slotsOk,omitempty"`
+	//+optional
+	KnownNodes int `json:"knownNodes,omitempty"`
+	// shards with a statefulset. Exceeds spec.shards while removed shards are drained of their slots
+	//+optional
+	Shards int32 `json:"shards,omitempty"`
+	// checkpoint of the slot migration in progress, resumed after a restart of the operator
+	//+optional
+	Resharding *RedisClusterReshardingStatus `json:"resharding,omitempty"`
+	// resourceVersion of the tls secret loaded by every node
+	//+optional
+	TLSSecretVersion string `json:"tlsSecretVersion,omitempty"`
+	// +optional
+	// +patchMergeKey=type
+	// +patchStrategy=merge
+	// +listType=map
+	// +listMapKey=type
+	Conditions []metav1.Condition `json:"conditions,omitempty" patchStrategy:"merge" patchMergeKey:"type"`
+}
+
+type RedisClusterReshardingStatus struct {
+	Slot        int    `json:"slot"`
+	SourceShard int    `json:"sourceShard"`
+	SourceID    string `json:"sourceId"`
+	TargetShard int    `json:"targetShard"`
+	TargetID    string `json:"targetId"`
+	// slots left to move after this one
+	PendingSlots int `json:"pendingSlots"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Shards",type=integer,JSONPath=`.spec.shards`
+// +kubebuilder:printcolumn:name="State",type=string,JSONPath=`.status.state`
+// +kubebuilder:printcolumn:name="Slots",type=integer,JSONPath=`.status.slotsOk`
+
+// RedisCluster is the Schema for the redisclusters API
+type RedisCluster struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   RedisClusterSpec   `json:"spec,omitempty"`
+	Status RedisClusterStatus `json:"status,omitempty"`
+}
+
+const (
+	RedisClusterFinalizer = "redis-operator.rediscluster.k8s.example.com/finalizer"
+
+	// number of hash slots of a redis cluster
+	ClusterSlots = 16384
+
+	// condition type set to true once every hash slot is served
+	ConditionSlotsCovered = "SlotsCovered"
+	// condition type set to true while slots are migrated between shards
+	ConditionResharding = "Resharding"
+)
+
+// GetShardName returns the name of the statefulset running shard
+func (r *RedisCluster) GetShardName(shard int) string {
+	return fmt.Sprintf("%s-shard-%d", r.Name, shard)
+}
+
+// GetPodDNS returns the name the pod at index of shard announces to the cluster
+func (r *RedisCluster) GetPodDNS(shard int, index int) string {
+	return dns.GetPodDNS(fmt.Sprintf("%s-%d", r.GetShardName(shard), index), r.GetHeadlessServiceName(), r.Namespace)
+}
+
+// GetDeployedShards returns the number of shards with a statefulset, including the shards being removed
+func (r *RedisCluster) GetDeployedShards() int {
+	if r.Status.Shards > r.Spec.Shards {
+		return int(r.Status.Shards)
+	}
+	return int(r.Spec.Shards)
+}
+
+// GetShardSize returns the number of pods of every shard, the master and its replicas
+func (r *RedisCluster) GetShardSize() int {
+	return int(r.Spec.ReplicasPerShard) + 1
+}
+
+func (r *RedisCluster) GetHeadlessServiceName() string {
+	return r.Name + "-headless"
+}
+
+func (r *RedisCluster) GetServiceName() string {
+	return r.Name + "-service"
+}
+
+func (r *RedisCluster) GetConfigName() string {
+	return r.Name + "-conf"
+}
+
+func (r *RedisCluster) GetRedisPort() string {
+	portQuery := "port"
+	if r.Spec.TLSConfig != nil {
+		portQuery = "tls-port"
+	}
+
+	if port := configmap.GetConfigMapValue(r.Spec.RedisConfig.Data, "redis.conf", portQuery); port != "" {
+		return port
+	}
+	return "6379"
+}
+
+func (r *RedisCluster) GetRedisPortInt32() int32 {
+	port, err := strconv.Atoi(r.GetRedisPort())
+	if err != nil {
+		return 0
+	}
+	return int32(port)
+}
+
+// GetPlaintextPort returns the port kept open next to tls-port in Dual mode
+func (r *RedisCluster) GetPlaintextPort() string {
+	return r.Spec.TLSConfig.getPlaintextPort(configmap.GetConfigMapValue(r.Spec.RedisConfig.Data, "redis.conf", "port"), "6379")
+}
+
+// GetTLSSecretName returns the secret mounted for tls, empty if tls is not enabled
+func (r *RedisCluster) GetTLSSecretName() string {
+	if r.Spec.TLSConfig == nil {
+		return ""
+	}
+	if r.Spec.TLSConfig.SecretName == "" && r.Spec.TLSConfig.IssuerRef != nil {
+		return r.GetCertificateName()
+	}
+	return r.Spec.TLSConfig.SecretName
+}
+
+func (r *RedisCluster) GetCertificateName() string {
+	return r.Name + "-tls"
+}
+
+// IsExporterEnabled returns true when the exporter sidecar is enabled or configured
+func (r *RedisCluster) IsExporterEnabled() bool {
+	return r.Spec.EnableExporter || r.Spec.Exporter != nil
+}
+
+func (r *RedisCluster) GetAuthSecretName() string {
+	return r.Name + "-auth"
+}
+
+func (r *RedisCluster) GetOwnerReference() metav1.OwnerReference {
+	return metav1.OwnerReference{
+		APIVersion: r.APIVersion,
+		Kind:       r.Kind,
+		Name:       r.Name,
+		UID:        r.UID,
+		Controller: ptr.To(true),
+	}
+}
+
+// +kubebuilder:object:root=true
+
+// RedisClusterList contains a list of RedisCluster
+type RedisClusterList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []RedisCluster `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&RedisCluster{}, &RedisClusterList{})
+}
@@ -18,13 +18,16 @@ package v1
 
 import (
 	"context"
+	"fmt"
 	"strconv"
 
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/utils/ptr"
 	"redis.operator/pkg/kube/configmap"
+	"redis.operator/pkg/kube/dns"
 )
 
 // EDIT THIS FILE!  THIS IS SCAFFOLDING FOR YOU TO OWN!
@@ -32,6 +35,11 @@ import (
 
 // RedisReplicationSpec defines the desired state of RedisReplication
 type RedisReplicationSpec struct {
+	// standalone runs a single redis pod without replication, master election or sentinels. Persistence, tls,
+	// auth and the exporter are configured as for a replication
+	//+optional
+	//+kubebuilder:validation:Enum=replication;standalone
+	Mode string `json:"mode,omitempty"`
 	//+optional
 	Resources *corev1.ResourceRequirements `json:"resources,omitempty"`
 	//+optional
@@ -42,15 +50,48 @@ type RedisReplicationSpec struct {
 	TLSConfig      *RedisTLSConfiguration `json:"tls,omitempty"`
 	EnableExporter bool                   `json:"enableExporter,omitempty"`
 	//+optional
+	Exporter *RedisExporterConfiguration `json:"exporter,omitempty"`
+	//+optional
 	RedisSentinelConfig *RedisReplicationSentinelConfig `json:"sentinelConfig,omitempty"`
+	//+optional
+	ZoneConfig *RedisReplicationZoneConfig `json:"zoneConfig,omitempty"`
+	//+optional
+	Monitoring *RedisMonitoringConfiguration `json:"monitoring,omitempty"`
+	// namespaces whose sentinels may monitor this replication, "*" allows every namespace. Sentinels of the
+	// namespace of the replication are always allowed
+	//+optional
+	AllowedNamespaces []string `json:"allowedNamespaces,omitempty"`
 }
 
 type RedisReplicationSentinelConfig struct {
 	RedisSentinelName string `json:"redisSentinelName,omitempty"`
+	// namespace of the sentinel, defaults to the namespace of the replication. The sentinel must allow the
+	// namespace of the replication in its allowedNamespaces
 	//+optional
+	RedisSentinelNamespace string `json:"redisSentinelNamespace,omitempty"`
+	// down-after-milliseconds of the master, overrides the settings of the sentinel but not the ones of its monitor
+	//+optional
+	//+kubebuilder:validation:Minimum=1
 	RedisSentinelDowntime *int `json:"redisSentinelDowntime,omitempty"`
 }
 
+// RedisReplicationZoneConfig prefers a zone for the master. Every pod is given a replica-priority based on the
+// zone of the node it runs on, so replicas outside of the preferred zone become the preferred failover candidates.
+type RedisReplicationZoneConfig struct {
+	PreferredZone string `json:"preferredZone"`
+	// node label used to find the zone of a pod. Defaults to topology.kubernetes.io/zone
+	//+optional
+	TopologyKey string `json:"topologyKey,omitempty"`
+	// replica-priority given to pods in the preferred zone. Defaults to 100
+	//+optional
+	//+kubebuilder:validation:Minimum=0
+	PreferredZonePriority *int `json:"preferredZonePriority,omitempty"`
+	// replica-priority given to pods outside of the preferred zone. Defaults to 50
+	//+optional
+	//+kubebuilder:validation:Minimum=0
+	OtherZonePriority *int `json:"otherZonePriority,omitempty"`
+}
+
 type RedisReplicationConfiguration struct {
 	RedisConfigurationData `json:",inline"`
 }
@@ -59,9 +100,25 @@ type RedisReplicationConfiguration struct {
 type RedisReplicationStatus struct {
 	// INSERT ADDITIONAL STATUS FIELD - define observed state of cluster
 	// Important: Run "make" to regenerate code after modifying this file
+	// dns of the pod last seen as the only master
 	MasterDns string `json:"masterNode,omitempty"`
+	// resourceVersion of the tls secret loaded by every pod
+	//+optional
+	TLSSecretVersion string `json:"tlsSecretVersion,omitempty"`
+	// +optional
+	// +patchMergeKey=type
+	// +patchStrategy=merge
+	// +listType=map
+	// +listMapKey=type
+	Conditions []metav1.Condition `json:"conditions,omitempty" patchStrategy:"merge" patchMergeKey:"type"`
 }
 
+const (
+	// ConditionSplitBrain is true while several masters accept writes. Once resolved, the message lists the writes
+	// discarded by the demoted masters
+	ConditionSplitBrain = "SplitBrain"
+)
+
 // +kubebuilder:object:root=true
 // +kubebuilder:subresource:status
 
@@ -76,8 +133,24 @@ type RedisReplication struct {
 
 const (
 	RedisReplicationFinalizer = "redis-operator.redisreplication.k8s.example.com/finalizer"
+
+	ReplicationModeReplication = "replication"
+	ReplicationModeStandalone  = "standalone"
 )
 
+// IsStandalone returns true when a single pod runs without replication
+func (r *RedisReplication) IsStandalone() bool {
+	return r.Spec.Mode == ReplicationModeStandalone
+}
+
+// GetReplicas returns the number of redis pods, always 1 in standalone mode
+func (r *RedisReplication) GetReplicas() int {
+	if r.IsStandalone() {
+		return 1
+	}
+	return r.Spec.StatefulsetConfig.GetReplicas()
+}
+
 func (r *RedisReplication) GetConfigName() string {
 	return r.Name + "-config"
 }
@@ -114,6 +187,28 @@ func (r *RedisReplication) GetRedisPort() string {
 	return "6379"
 }
 
+// GetPlaintextPort returns the plaintext port kept open next to tls-port in Dual mode
+func (r *RedisReplication) GetPlaintextPort() string {
+	return r.Spec.TLSConfig.getPlaintextPort(configmap.GetConfigMapValue(r.Spec.RedisConfig.Data, "redis.conf", "port"), "6379")
+}
+
+// GetReplicationPort returns the port replicas and sentinels connect to. Replication stays on the plaintext port
+// in Dual mode, pods and sentinels still running tls-replication no are switched before connecting to tls-port
+func (r *RedisReplication) GetReplicationPort() string {
+	if r.Spec.TLSConfig.IsDualMode() {
+		return r.GetPlaintextPort()
+	}
+	return r.GetRedisPort()
+}
+
+func (r *RedisReplication) GetPlaintextPortInt32() int32 {
+	port, err := strconv.Atoi(r.GetPlaintextPort())
+	if err != nil {
+		return 0
+	}
+	return int32(port)
+}
+
 func (r *RedisReplication) GetRedisPortInt32() int32 {
 	portStr := r.GetRedisPort()
 	port, err := strconv.Atoi(portStr)
@@ -123,14 +218,99 @@ func (r *RedisReplication) GetRedisPortInt32() int32 {
 	return int32(port)
 }
 
+func (r *RedisReplication) GetPreferredZone() string {
+	if r.Spec.ZoneConfig == nil {
+		return ""
+	}
+	return r.Spec.ZoneConfig.PreferredZone
+}
+
+func (r *RedisReplication) GetZoneTopologyKey() string {
+	if r.Spec.ZoneConfig == nil || r.Spec.ZoneConfig.TopologyKey == "" {
+		return "topology.kubernetes.io/zone"
+	}
+	return r.Spec.ZoneConfig.TopologyKey
+}
+
+// GetReplicaPriority returns the replica-priority for a pod running in zone. Lower values are preferred by the sentinels
+func (r *RedisReplication) GetReplicaPriority(zone string) int {
+	if r.Spec.ZoneConfig == nil {
+		return 100
+	}
+	if zone != "" && zone == r.Spec.ZoneConfig.PreferredZone {
+		if r.Spec.ZoneConfig.PreferredZonePriority != nil {
+			return *r.Spec.ZoneConfig.PreferredZonePriority
+		}
+		return 100
+	}
+	if r.Spec.ZoneConfig.OtherZonePriority != nil {
+		return *r.Spec.ZoneConfig.OtherZonePriority
+	}
+	return 50
+}
+
 func (r *RedisReplication) GetHeadlessServiceName() string {
 	return r.Name + "-headless"
 }
 
+// GetSentinelReference returns the sentinel of the replication, an empty name when it has none
+func (r *RedisReplication) GetSentinelReference() types.NamespacedName {
+	if r.Spec.RedisSentinelConfig == nil || r.Spec.RedisSentinelConfig.RedisSentinelName == "" {
+		return types.NamespacedName{}
+	}
+	namespace := r.Spec.RedisSentinelConfig.RedisSentinelNamespace
+	if namespace == "" {
+		namespace = r.Namespace
+	}
+	return types.NamespacedName{Name: r.Spec.RedisSentinelConfig.RedisSentinelName, Namespace: namespace}
+}
+
+// AllowsNamespace returns whether sentinels of namespace may monitor the replication
+func (r *RedisReplication) AllowsNamespace(namespace string) bool {
+	return isNamespaceAllowed(r.Namespace, r.Spec.AllowedNamespaces, namespace)
+}
+
+// GetPodDNS returns the address of the pod at index
+func (r *RedisReplication) GetPodDNS(index int) string {
+	return dns.GetPodDNS(fmt.Sprintf("%s-%d", r.Name, index), r.GetHeadlessServiceName(), r.Namespace)
+}
+
 func (r *RedisReplication) GetServiceName() string {
 	return r.Name + "-service"
 }
 
+func (r *RedisReplication) GetServiceMonitorName() string {
+	return r.Name + "-servicemonitor"
+}
+
+func (r *RedisReplication) GetPrometheusRuleName() string {
+	return r.Name + "-rules"
+}
+
+// GetTLSSecretName returns the secret mounted for tls, empty if tls is not enabled
+func (r *RedisReplication) GetTLSSecretName() string {
+	if r.Spec.TLSConfig == nil {
+		return ""
+	}
+	if r.Spec.TLSConfig.SecretName == "" && r.Spec.TLSConfig.IssuerRef != nil {
+		return r.GetCertificateName()
+	}
+	return r.Spec.TLSConfig.SecretName
+}
+
+func (r *RedisReplication) GetCertificateName() string {
+	return r.Name + "-tls"
+}
+
+// IsExporterEnabled returns true when the exporter sidecar is enabled or configured
+func (r *RedisReplication) IsExporterEnabled() bool {
+	return r.Spec.EnableExporter || r.Spec.Exporter != nil
+}
+
+func (r *RedisReplication) GetAuthSecretName() string {
+	return r.Name + "-auth"
+}
+
 func (r *RedisReplication) GetOwnerReference() metav1.OwnerReference {
 	return metav1.OwnerReference{
 		APIVersion: r.APIVersion,
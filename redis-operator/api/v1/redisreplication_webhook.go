@@ -17,6 +17,9 @@ limitations under the License.
 package v1
 
 import (
+	"context"
+	"fmt"
+
 	"k8s.io/apimachinery/pkg/runtime"
 	ctrl "sigs.k8s.io/controller-runtime"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
@@ -31,6 +34,7 @@ var redisreplicationlog = logf.Log.WithName("redisreplication-resource")
 func (r *RedisReplication) SetupWebhookWithManager(mgr ctrl.Manager) error {
 	return ctrl.NewWebhookManagedBy(mgr).
 		For(r).
+		WithValidator(&redisReplicationValidator{referenceChecker{client: mgr.GetClient()}}).
 		Complete()
 }
 
@@ -52,28 +56,58 @@ func (r *RedisReplication) Default() {
 // Modifying the path for an invalid path can cause API server errors; failing to locate the webhook.
 // +kubebuilder:webhook:path=/validate-redis-redis-operator-v1-redisreplication,mutating=false,failurePolicy=fail,sideEffects=None,groups=redis.redis.operator,resources=redisreplications,verbs=create;update,versions=v1,name=vredisreplication.kb.io,admissionReviewVersions=v1
 
-var _ webhook.Validator = &RedisReplication{}
+// redisReplicationValidator validates the mode of a replication and the access of the user to the sentinel of
+// another namespace it references
+type redisReplicationValidator struct {
+	referenceChecker
+}
+
+var _ webhook.CustomValidator = &redisReplicationValidator{}
 
-// ValidateCreate implements webhook.Validator so a webhook will be registered for the type
-func (r *RedisReplication) ValidateCreate() (admission.Warnings, error) {
+// ValidateCreate implements webhook.CustomValidator so a webhook will be registered for the type
+func (v *redisReplicationValidator) ValidateCreate(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
+	r := obj.(*RedisReplication)
 	redisreplicationlog.Info("validate create", "name", r.Name)
 
-	// TODO(user): fill in your validation logic upon object creation.
-	return nil, nil
+	return nil, v.validate(ctx, r)
 }
 
-// ValidateUpdate implements webhook.Validator so a webhook will be registered for the type
-func (r *RedisReplication) ValidateUpdate(old runtime.Object) (admission.Warnings, error) {
+// ValidateUpdate implements webhook.CustomValidator so a webhook will be registered for the type
+func (v *redisReplicationValidator) ValidateUpdate(ctx context.Context, oldObj runtime.Object, newObj runtime.Object) (admission.Warnings, error) {
+	r := newObj.(*RedisReplication)
 	redisreplicationlog.Info("validate update", "name", r.Name)
 
-	// TODO(user): fill in your validation logic upon object update.
-	return nil, nil
+	return nil, v.validate(ctx, r)
 }
 
-// ValidateDelete implements webhook.Validator so a webhook will be registered for the type
-func (r *RedisReplication) ValidateDelete() (admission.Warnings, error) {
-	redisreplicationlog.Info("validate delete", "name", r.Name)
+// ValidateDelete implements webhook.CustomValidator so a webhook will be registered for the type
+func (v *redisReplicationValidator) ValidateDelete(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
+	redisreplicationlog.Info("validate delete", "name", obj.(*RedisReplication).Name)
 
 	// TODO(user): fill in your validation logic upon object deletion.
 	return nil, nil
 }
+
+func (v *redisReplicationValidator) validate(ctx context.Context, r *RedisReplication) error {
+	if err := r.validateMode(); err != nil {
+		return err
+	}
+	return v.checkReference(ctx, r.Namespace, "redissentinels", r.GetSentinelReference())
+}
+
+// validateMode rejects settings which need replication in standalone mode
+func (r *RedisReplication) validateMode() error {
+	if !r.IsStandalone() {
+		return nil
+	}
+	if r.Spec.StatefulsetConfig.GetReplicas() != 1 {
+		return fmt.Errorf("standalone mode runs a single pod, statefulSet.spec.replicas must be 1")
+	}
+	if r.Spec.RedisSentinelConfig != nil {
+		return fmt.Errorf("standalone mode doesn't support sentinelConfig")
+	}
+	if r.Spec.ZoneConfig != nil {
+		return fmt.Errorf("standalone mode doesn't support zoneConfig")
+	}
+	return nil
+}
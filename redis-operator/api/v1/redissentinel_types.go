@@ -17,12 +17,16 @@ limitations under the License.
 package v1
 
 import (
+	"fmt"
 	"strconv"
+	"time"
 
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/utils/ptr"
 	"redis.operator/pkg/kube/configmap"
+	"redis.operator/pkg/kube/dns"
 )
 
 // EDIT THIS FILE!  THIS IS SCAFFOLDING FOR YOU TO OWN!
@@ -36,7 +40,108 @@ type RedisSentinelSpec struct {
 	MasterName           string                       `json:"masterName,omitempty"`
 	RedisReplicationName string                       `json:"redisReplicationName,omitempty"`
 	RedisSentinelQuorum  int                          `json:"redisSentinelQuorum,omitempty"`
-	RedisConfig          RedisSentinelConfiguration   `json:"config,omitempty"`
+	// replications monitored by the sentinels, added and removed at runtime. masterName and redisReplicationName
+	// are monitored when empty. The sentinels use the tls settings of the first replication
+	//+optional
+	Monitors []RedisSentinelMonitor `json:"monitors,omitempty"`
+	// settings of every monitored master, applied at runtime with SENTINEL SET
+	//+optional
+	Settings *RedisSentinelSettings `json:"settings,omitempty"`
+	// repair of sentinels seeing a reachable master down
+	//+optional
+	Repair      *RedisSentinelRepairConfiguration `json:"repair,omitempty"`
+	RedisConfig RedisSentinelConfiguration        `json:"config,omitempty"`
+	// namespaces whose replications may use these sentinels, "*" allows every namespace. Replications of the
+	// namespace of the sentinel are always allowed
+	//+optional
+	AllowedNamespaces []string `json:"allowedNamespaces,omitempty"`
+	// tls of the sentinels. Defaults to the tls settings and secret of the replication
+	//+optional
+	TLSConfig *RedisTLSConfiguration `json:"tls,omitempty"`
+	//+optional
+	VolumeMounts []corev1.VolumeMount `json:"volumeMounts,omitempty"`
+	//+optional
+	EnableExporter bool `json:"enableExporter,omitempty"`
+	//+optional
+	Exporter *RedisExporterConfiguration `json:"exporter,omitempty"`
+}
+
+// RedisSentinelMonitor is a replication monitored by the sentinels under a master name
+type RedisSentinelMonitor struct {
+	MasterName           string `json:"masterName"`
+	RedisReplicationName string `json:"redisReplicationName"`
+	// namespace of the replication, defaults to the namespace of the sentinel. The replication must allow the
+	// namespace of the sentinel in its allowedNamespaces. Sentinels monitoring a replication of another namespace
+	// first need tls settings of their own, the secret of the replication can't be mounted
+	//+optional
+	RedisReplicationNamespace string `json:"redisReplicationNamespace,omitempty"`
+	// sentinels agreeing on a failure of the master. Defaults to redisSentinelQuorum
+	//+optional
+	//+kubebuilder:validation:Minimum=1
+	Quorum int `json:"quorum,omitempty"`
+	// overrides the settings of the sentinel for this master
+	//+optional
+	Settings *RedisSentinelSettings `json:"settings,omitempty"`
+}
+
+// RedisSentinelSettings are the tunables of a monitored master. Unset fields keep the value of sentinel.conf
+type RedisSentinelSettings struct {
+	// time a master has to be unreachable before it is considered down
+	//+optional
+	//+kubebuilder:validation:Minimum=1
+	DownAfterMilliseconds *int `json:"downAfterMilliseconds,omitempty"`
+	// time in milliseconds before a failed failover is retried
+	//+optional
+	//+kubebuilder:validation:Minimum=1
+	FailoverTimeout *int `json:"failoverTimeout,omitempty"`
+	// replicas resynchronizing with the new master at once after a failover
+	//+optional
+	//+kubebuilder:validation:Minimum=1
+	ParallelSyncs *int `json:"parallelSyncs,omitempty"`
+}
+
+// GetOptions returns the settings as SENTINEL SET options
+func (s *RedisSentinelSettings) GetOptions() map[string]string {
+	options := map[string]string{}
+	if s == nil {
+		return options
+	}
+	if s.DownAfterMilliseconds != nil {
+		options["down-after-milliseconds"] = strconv.Itoa(*s.DownAfterMilliseconds)
+	}
+	if s.FailoverTimeout != nil {
+		options["failover-timeout"] = strconv.Itoa(*s.FailoverTimeout)
+	}
+	if s.ParallelSyncs != nil {
+		options["parallel-syncs"] = strconv.Itoa(*s.ParallelSyncs)
+	}
+	return options
+}
+
+// RedisSentinelRepairConfiguration controls the repair of sentinels reporting a master down while the operator
+// reaches it. A sick sentinel is reset first, made to monitor the master again next and restarted last
+type RedisSentinelRepairConfiguration struct {
+	// time a sentinel has to report a reachable master down before the next repair step, defaults to 20000
+	//+optional
+	//+kubebuilder:validation:Minimum=1
+	DownTimeThresholdMilliseconds *int32 `json:"downTimeThresholdMilliseconds,omitempty"`
+	// minimum time between two sentinel restarts, defaults to 5m
+	//+optional
+	RestartInterval *metav1.Duration `json:"restartInterval,omitempty"`
+}
+
+func (r *RedisSentinelRepairConfiguration) GetDownTimeThreshold() time.Duration {
+	if r == nil || r.DownTimeThresholdMilliseconds == nil {
+		return 20 * time.Second
+	}
+	return time.Duration(*r.DownTimeThresholdMilliseconds) * time.Millisecond
+}
+
+func (r *RedisSentinelRepairConfiguration) GetRestartInterval() time.Duration {
+	if r == nil || r.RestartInterval == nil {
+		return 5 * time.Minute
+	}
+	return r.RestartInterval.Duration
 }
 
 type RedisSentinelConfiguration struct {
@@ -47,6 +152,29 @@ type RedisSentinelConfiguration struct {
 type RedisSentinelStatus struct {
 	// INSERT ADDITIONAL STATUS FIELD - define observed state of cluster
 	// Important: Run "make" to regenerate code after modifying this file
+
+	// resourceVersion of the tls secret loaded by every sentinel
+	//+optional
+	TLSSecretVersion string `json:"tlsSecretVersion,omitempty"`
+	// master names added to the sentinels by the operator, removed again once their replication is no longer
+	// monitored
+	//+optional
+	MonitoredMasters []string `json:"monitoredMasters,omitempty"`
+	// sentinels being repaired
+	//+optional
+	Repairs []RedisSentinelRepairStatus `json:"repairs,omitempty"`
+	// time of the last restart of a sentinel by the repair
+	//+optional
+	LastRestart *metav1.Time `json:"lastRestart,omitempty"`
+}
+
+// RedisSentinelRepairStatus is a sentinel reporting a reachable master down
+type RedisSentinelRepairStatus struct {
+	PodIndex   int    `json:"podIndex"`
+	MasterName string `json:"masterName"`
+	// repair steps taken, reset, monitor again and restart
+	Attempts    int         `json:"attempts"`
+	LastAttempt metav1.Time `json:"lastAttempt"`
 }
 
 // +kubebuilder:object:root=true
@@ -69,6 +197,11 @@ func (r *RedisSentinel) GetHeadlessServiceName() string {
 	return r.Name + "-headless"
 }
 
+// GetPodDNS returns the address of the pod at index
+func (r *RedisSentinel) GetPodDNS(index int) string {
+	return dns.GetPodDNS(fmt.Sprintf("%s-%d", r.Name, index), r.GetHeadlessServiceName(), r.Namespace)
+}
+
 func (r *RedisSentinel) GetServiceName() string {
 	return r.Name + "-service"
 }
@@ -88,6 +221,19 @@ func (r *RedisSentinel) GetRedisPort() string {
 	return "26379"
 }
 
+// GetPlaintextPort returns the plaintext port kept open next to tls-port in Dual mode
+func (r *RedisSentinel) GetPlaintextPort() string {
+	return r.Spec.TLSConfig.getPlaintextPort(configmap.GetConfigMapValue(r.Spec.RedisConfig.Data, "sentinel.conf", "port"), "26379")
+}
+
+func (r *RedisSentinel) GetPlaintextPortInt32() int32 {
+	port, err := strconv.Atoi(r.GetPlaintextPort())
+	if err != nil {
+		return 26379
+	}
+	return int32(port)
+}
+
 func (r *RedisSentinel) GetRedisPortInt32() int32 {
 	portStr := r.GetRedisPort()
 	port, err := strconv.Atoi(portStr)
@@ -97,6 +243,38 @@ func (r *RedisSentinel) GetRedisPortInt32() int32 {
 	return int32(port)
 }
 
+// GetTLSConfig returns the tls settings of the sentinel, falling back to the ones of the replication
+func (r *RedisSentinel) GetTLSConfig(replicaInstance *RedisReplication) *RedisTLSConfiguration {
+	if r.Spec.TLSConfig != nil {
+		return r.Spec.TLSConfig
+	}
+	return replicaInstance.Spec.TLSConfig
+}
+
+// GetTLSSecretName returns the secret mounted for tls, empty if tls is not enabled
+func (r *RedisSentinel) GetTLSSecretName(replicaInstance *RedisReplication) string {
+	if r.Spec.TLSConfig == nil {
+		return replicaInstance.GetTLSSecretName()
+	}
+	if r.Spec.TLSConfig.SecretName == "" && r.Spec.TLSConfig.IssuerRef != nil {
+		return r.GetCertificateName()
+	}
+	return r.Spec.TLSConfig.SecretName
+}
+
+func (r *RedisSentinel) GetCertificateName() string {
+	return r.Name + "-tls"
+}
+
+// IsExporterEnabled returns true when the exporter sidecar is enabled or configured
+func (r *RedisSentinel) IsExporterEnabled() bool {
+	return r.Spec.EnableExporter || r.Spec.Exporter != nil
+}
+
+func (r *RedisSentinel) GetAuthSecretName() string {
+	return r.Name + "-auth"
+}
+
 func (r *RedisSentinel) GetOwnerReference() metav1.OwnerReference {
 	return metav1.OwnerReference{
 		APIVersion: r.APIVersion,
@@ -107,6 +285,60 @@ func (r *RedisSentinel) GetOwnerReference() metav1.OwnerReference {
 	}
 }
 
+// GetMonitors returns the replications monitored by the sentinels, the quorum defaults to redisSentinelQuorum and
+// the namespace of the replications to the namespace of the sentinel
+func (r *RedisSentinel) GetMonitors() []RedisSentinelMonitor {
+	monitors := r.Spec.Monitors
+	if len(monitors) == 0 {
+		if r.Spec.RedisReplicationName == "" {
+			return nil
+		}
+		monitors = []RedisSentinelMonitor{{MasterName: r.Spec.MasterName, RedisReplicationName: r.Spec.RedisReplicationName}}
+	}
+
+	defaulted := make([]RedisSentinelMonitor, 0, len(monitors))
+	for _, monitor := range monitors {
+		if monitor.Quorum == 0 {
+			monitor.Quorum = r.Spec.RedisSentinelQuorum
+		}
+		if monitor.RedisReplicationNamespace == "" {
+			monitor.RedisReplicationNamespace = r.Namespace
+		}
+		defaulted = append(defaulted, monitor)
+	}
+	return defaulted
+}
+
+// GetMonitor returns the monitor of a replication, nil when the sentinels do not monitor it
+func (r *RedisSentinel) GetMonitor(replication types.NamespacedName) *RedisSentinelMonitor {
+	for _, monitor := range r.GetMonitors() {
+		if monitor.GetReplicationReference() == replication {
+			return &monitor
+		}
+	}
+	return nil
+}
+
+// GetPrimaryReplication returns the replication providing the default tls settings of the sentinels, an empty name
+// when no replication is monitored
+func (r *RedisSentinel) GetPrimaryReplication() types.NamespacedName {
+	if monitors := r.GetMonitors(); len(monitors) > 0 {
+		return monitors[0].GetReplicationReference()
+	}
+	return types.NamespacedName{}
+}
+
+// AllowsNamespace returns whether replications of namespace may use the sentinels
+func (r *RedisSentinel) AllowsNamespace(namespace string) bool {
+	return isNamespaceAllowed(r.Namespace, r.Spec.AllowedNamespaces, namespace)
+}
+
+// GetReplicationReference returns the monitored replication. The namespace is only set on monitors returned by
+// RedisSentinel.GetMonitors
+func (m RedisSentinelMonitor) GetReplicationReference() types.NamespacedName {
+	return types.NamespacedName{Name: m.RedisReplicationName, Namespace: m.RedisReplicationNamespace}
+}
+
 func (r *RedisSentinel) GetSentinelName() string {
 	return r.Name + "-sentinel"
 }
@@ -17,7 +17,11 @@ limitations under the License.
 package v1
 
 import (
+	"context"
+	"fmt"
+
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
 	ctrl "sigs.k8s.io/controller-runtime"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/webhook"
@@ -31,6 +35,7 @@ var redissentinellog = logf.Log.WithName("redissentinel-resource")
 func (r *RedisSentinel) SetupWebhookWithManager(mgr ctrl.Manager) error {
 	return ctrl.NewWebhookManagedBy(mgr).
 		For(r).
+		WithValidator(&redisSentinelValidator{referenceChecker{client: mgr.GetClient()}}).
 		Complete()
 }
 
@@ -52,27 +57,63 @@ func (r *RedisSentinel) Default() {
 // Modifying the path for an invalid path can cause API server errors; failing to locate the webhook.
 // +kubebuilder:webhook:path=/validate-redis-redis-operator-v1-redissentinel,mutating=false,failurePolicy=fail,sideEffects=None,groups=redis.redis.operator,resources=redissentinels,verbs=create;update,versions=v1,name=vredissentinel.kb.io,admissionReviewVersions=v1
 
-var _ webhook.Validator = &RedisSentinel{}
+// redisSentinelValidator validates the monitors of a sentinel and the access of the user to the replications of
+// other namespaces it monitors
+type redisSentinelValidator struct {
+	referenceChecker
+}
+
+var _ webhook.CustomValidator = &redisSentinelValidator{}
 
-// ValidateCreate implements webhook.Validator so a webhook will be registered for the type
-func (r *RedisSentinel) ValidateCreate() (admission.Warnings, error) {
+// ValidateCreate implements webhook.CustomValidator so a webhook will be registered for the type
+func (v *redisSentinelValidator) ValidateCreate(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
+	r := obj.(*RedisSentinel)
 	redissentinellog.Info("validate create", "name", r.Name)
-	// TODO(user): fill in your validation logic upon object creation
-	return nil, nil
+
+	return nil, v.validate(ctx, r)
 }
 
-// ValidateUpdate implements webhook.Validator so a webhook will be registered for the type
-func (r *RedisSentinel) ValidateUpdate(old runtime.Object) (admission.Warnings, error) {
+// ValidateUpdate implements webhook.CustomValidator so a webhook will be registered for the type
+func (v *redisSentinelValidator) ValidateUpdate(ctx context.Context, oldObj runtime.Object, newObj runtime.Object) (admission.Warnings, error) {
+	r := newObj.(*RedisSentinel)
 	redissentinellog.Info("validate update", "name", r.Name)
 
-	// TODO(user): fill in your validation logic upon object update.
-	return nil, nil
+	return nil, v.validate(ctx, r)
 }
 
-// ValidateDelete implements webhook.Validator so a webhook will be registered for the type
-func (r *RedisSentinel) ValidateDelete() (admission.Warnings, error) {
-	redissentinellog.Info("validate delete", "name", r.Name)
+// ValidateDelete implements webhook.CustomValidator so a webhook will be registered for the type
+func (v *redisSentinelValidator) ValidateDelete(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
+	redissentinellog.Info("validate delete", "name", obj.(*RedisSentinel).Name)
 
 	// TODO(user): fill in your validation logic upon object deletion.
 	return nil, nil
 }
+
+func (v *redisSentinelValidator) validate(ctx context.Context, r *RedisSentinel) error {
+	if err := r.validateMonitors(); err != nil {
+		return err
+	}
+	for _, monitor := range r.GetMonitors() {
+		if err := v.checkReference(ctx, r.Namespace, "redisreplications", monitor.GetReplicationReference()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// validateMonitors rejects master names and replications monitored twice
+func (r *RedisSentinel) validateMonitors() error {
+	masterNames := map[string]bool{}
+	replications := map[types.NamespacedName]bool{}
+	for _, monitor := range r.GetMonitors() {
+		if masterNames[monitor.MasterName] {
+			return fmt.Errorf("master name %s is monitored more than once", monitor.MasterName)
+		}
+		if replications[monitor.GetReplicationReference()] {
+			return fmt.Errorf("replication %s is monitored more than once", monitor.GetReplicationReference())
+		}
+		masterNames[monitor.MasterName] = true
+		replications[monitor.GetReplicationReference()] = true
+	}
+	return nil
+}
@@ -0,0 +1,58 @@
+package v1
+
+import (
+	"context"
+	"fmt"
+
+	authorizationv1 "k8s.io/api/authorization/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+// referenceChecker rejects references to resources of another namespace the requesting user can't get, so a
+// reference doesn't grant access to resources the user has no permissions on. The allow-list of the referenced
+// resource is checked by the controllers, the resource may not exist yet
+type referenceChecker struct {
+	client client.Client
+}
+
+// checkReference returns an error when the user of the admission request in ctx may not get the resource of
+// reference. References within namespace are not checked
+func (c *referenceChecker) checkReference(ctx context.Context, namespace string, resource string, reference types.NamespacedName) error {
+	if reference.Name == "" || reference.Namespace == namespace {
+		return nil
+	}
+
+	req, err := admission.RequestFromContext(ctx)
+	if err != nil {
+		return err
+	}
+
+	extra := map[string]authorizationv1.ExtraValue{}
+	for key, value := range req.UserInfo.Extra {
+		extra[key] = authorizationv1.ExtraValue(value)
+	}
+	review := &authorizationv1.SubjectAccessReview{
+		Spec: authorizationv1.SubjectAccessReviewSpec{
+			User:   req.UserInfo.Username,
+			Groups: req.UserInfo.Groups,
+			UID:    req.UserInfo.UID,
+			Extra:  extra,
+			ResourceAttributes: &authorizationv1.ResourceAttributes{
+				Namespace: reference.Namespace,
+				Verb:      "get",
+				Group:     GroupVersion.Group,
+				Resource:  resource,
+				Name:      reference.Name,
+			},
+		},
+	}
+	if err := c.client.Create(ctx, review); err != nil {
+		return fmt.Errorf("failed to review the access to %s %s: %v", resource, reference, err)
+	}
+	if !review.Status.Allowed {
+		return fmt.Errorf("%s may not get %s %s referenced from namespace %s", req.UserInfo.Username, resource, reference, namespace)
+	}
+	return nil
+}
@@ -22,15 +22,254 @@ package v1
 
 import (
 	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CertManagerIssuerReference) DeepCopyInto(out *CertManagerIssuerReference) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CertManagerIssuerReference.
+func (in *CertManagerIssuerReference) DeepCopy() *CertManagerIssuerReference {
+	if in == nil {
+		return nil
+	}
+	out := new(CertManagerIssuerReference)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *MapWrapper) DeepCopyInto(out *MapWrapper) {
 	clone := in.DeepCopy()
 	*out = *clone
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PrometheusRuleConfiguration) DeepCopyInto(out *PrometheusRuleConfiguration) {
+	*out = *in
+	if in.Enabled != nil {
+		in, out := &in.Enabled, &out.Enabled
+		*out = new(bool)
+		**out = **in
+	}
+	if in.Labels != nil {
+		in, out := &in.Labels, &out.Labels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.MemoryThreshold != nil {
+		in, out := &in.MemoryThreshold, &out.MemoryThreshold
+		*out = new(int)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PrometheusRuleConfiguration.
+func (in *PrometheusRuleConfiguration) DeepCopy() *PrometheusRuleConfiguration {
+	if in == nil {
+		return nil
+	}
+	out := new(PrometheusRuleConfiguration)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RedisCluster) DeepCopyInto(out *RedisCluster) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RedisCluster.
+func (in *RedisCluster) DeepCopy() *RedisCluster {
+	if in == nil {
+		return nil
+	}
+	out := new(RedisCluster)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *RedisCluster) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RedisClusterConfiguration) DeepCopyInto(out *RedisClusterConfiguration) {
+	*out = *in
+	in.RedisConfigurationData.DeepCopyInto(&out.RedisConfigurationData)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RedisClusterConfiguration.
+func (in *RedisClusterConfiguration) DeepCopy() *RedisClusterConfiguration {
+	if in == nil {
+		return nil
+	}
+	out := new(RedisClusterConfiguration)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RedisClusterList) DeepCopyInto(out *RedisClusterList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]RedisCluster, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RedisClusterList.
+func (in *RedisClusterList) DeepCopy() *RedisClusterList {
+	if in == nil {
+		return nil
+	}
+	out := new(RedisClusterList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *RedisClusterList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RedisClusterReshardingConfiguration) DeepCopyInto(out *RedisClusterReshardingConfiguration) {
+	*out = *in
+	if in.KeysPerBatch != nil {
+		in, out := &in.KeysPerBatch, &out.KeysPerBatch
+		*out = new(int32)
+		**out = **in
+	}
+	if in.SlotsPerReconcile != nil {
+		in, out := &in.SlotsPerReconcile, &out.SlotsPerReconcile
+		*out = new(int32)
+		**out = **in
+	}
+	if in.TimeoutMilliseconds != nil {
+		in, out := &in.TimeoutMilliseconds, &out.TimeoutMilliseconds
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RedisClusterReshardingConfiguration.
+func (in *RedisClusterReshardingConfiguration) DeepCopy() *RedisClusterReshardingConfiguration {
+	if in == nil {
+		return nil
+	}
+	out := new(RedisClusterReshardingConfiguration)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RedisClusterReshardingStatus) DeepCopyInto(out *RedisClusterReshardingStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RedisClusterReshardingStatus.
+func (in *RedisClusterReshardingStatus) DeepCopy() *RedisClusterReshardingStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(RedisClusterReshardingStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RedisClusterSpec) DeepCopyInto(out *RedisClusterSpec) {
+	*out = *in
+	if in.Resources != nil {
+		in, out := &in.Resources, &out.Resources
+		*out = new(corev1.ResourceRequirements)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.VolumeMounts != nil {
+		in, out := &in.VolumeMounts, &out.VolumeMounts
+		*out = make([]corev1.VolumeMount, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	in.StatefulsetConfig.DeepCopyInto(&out.StatefulsetConfig)
+	in.RedisConfig.DeepCopyInto(&out.RedisConfig)
+	if in.TLSConfig != nil {
+		in, out := &in.TLSConfig, &out.TLSConfig
+		*out = new(RedisTLSConfiguration)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Exporter != nil {
+		in, out := &in.Exporter, &out.Exporter
+		*out = new(RedisExporterConfiguration)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Resharding != nil {
+		in, out := &in.Resharding, &out.Resharding
+		*out = new(RedisClusterReshardingConfiguration)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RedisClusterSpec.
+func (in *RedisClusterSpec) DeepCopy() *RedisClusterSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(RedisClusterSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RedisClusterStatus) DeepCopyInto(out *RedisClusterStatus) {
+	*out = *in
+	if in.Resharding != nil {
+		in, out := &in.Resharding, &out.Resharding
+		*out = new(RedisClusterReshardingStatus)
+		**out = **in
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]metav1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RedisClusterStatus.
+func (in *RedisClusterStatus) DeepCopy() *RedisClusterStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(RedisClusterStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *RedisConfigMapWrapper) DeepCopyInto(out *RedisConfigMapWrapper) {
 	*out = *in
@@ -69,13 +308,83 @@ func (in *RedisConfigurationData) DeepCopy() *RedisConfigurationData {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RedisExporterConfiguration) DeepCopyInto(out *RedisExporterConfiguration) {
+	*out = *in
+	if in.Resources != nil {
+		in, out := &in.Resources, &out.Resources
+		*out = new(corev1.ResourceRequirements)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ExtraArgs != nil {
+		in, out := &in.ExtraArgs, &out.ExtraArgs
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.CheckKeys != nil {
+		in, out := &in.CheckKeys, &out.CheckKeys
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Script != nil {
+		in, out := &in.Script, &out.Script
+		*out = new(corev1.ConfigMapKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Port != nil {
+		in, out := &in.Port, &out.Port
+		*out = new(int32)
+		**out = **in
+	}
+	if in.PasswordSecret != nil {
+		in, out := &in.PasswordSecret, &out.PasswordSecret
+		*out = new(corev1.SecretKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RedisExporterConfiguration.
+func (in *RedisExporterConfiguration) DeepCopy() *RedisExporterConfiguration {
+	if in == nil {
+		return nil
+	}
+	out := new(RedisExporterConfiguration)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RedisMonitoringConfiguration) DeepCopyInto(out *RedisMonitoringConfiguration) {
+	*out = *in
+	if in.ServiceMonitor != nil {
+		in, out := &in.ServiceMonitor, &out.ServiceMonitor
+		*out = new(ServiceMonitorConfiguration)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.PrometheusRule != nil {
+		in, out := &in.PrometheusRule, &out.PrometheusRule
+		*out = new(PrometheusRuleConfiguration)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RedisMonitoringConfiguration.
+func (in *RedisMonitoringConfiguration) DeepCopy() *RedisMonitoringConfiguration {
+	if in == nil {
+		return nil
+	}
+	out := new(RedisMonitoringConfiguration)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *RedisReplication) DeepCopyInto(out *RedisReplication) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
 	in.Spec.DeepCopyInto(&out.Spec)
-	out.Status = in.Status
+	in.Status.DeepCopyInto(&out.Status)
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RedisReplication.
@@ -184,13 +493,33 @@ func (in *RedisReplicationSpec) DeepCopyInto(out *RedisReplicationSpec) {
 	if in.TLSConfig != nil {
 		in, out := &in.TLSConfig, &out.TLSConfig
 		*out = new(RedisTLSConfiguration)
-		**out = **in
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Exporter != nil {
+		in, out := &in.Exporter, &out.Exporter
+		*out = new(RedisExporterConfiguration)
+		(*in).DeepCopyInto(*out)
 	}
 	if in.RedisSentinelConfig != nil {
 		in, out := &in.RedisSentinelConfig, &out.RedisSentinelConfig
 		*out = new(RedisReplicationSentinelConfig)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.ZoneConfig != nil {
+		in, out := &in.ZoneConfig, &out.ZoneConfig
+		*out = new(RedisReplicationZoneConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Monitoring != nil {
+		in, out := &in.Monitoring, &out.Monitoring
+		*out = new(RedisMonitoringConfiguration)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.AllowedNamespaces != nil {
+		in, out := &in.AllowedNamespaces, &out.AllowedNamespaces
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RedisReplicationSpec.
@@ -206,6 +535,13 @@ func (in *RedisReplicationSpec) DeepCopy() *RedisReplicationSpec {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *RedisReplicationStatus) DeepCopyInto(out *RedisReplicationStatus) {
 	*out = *in
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]metav1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RedisReplicationStatus.
@@ -218,13 +554,38 @@ func (in *RedisReplicationStatus) DeepCopy() *RedisReplicationStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RedisReplicationZoneConfig) DeepCopyInto(out *RedisReplicationZoneConfig) {
+	*out = *in
+	if in.PreferredZonePriority != nil {
+		in, out := &in.PreferredZonePriority, &out.PreferredZonePriority
+		*out = new(int)
+		**out = **in
+	}
+	if in.OtherZonePriority != nil {
+		in, out := &in.OtherZonePriority, &out.OtherZonePriority
+		*out = new(int)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RedisReplicationZoneConfig.
+func (in *RedisReplicationZoneConfig) DeepCopy() *RedisReplicationZoneConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(RedisReplicationZoneConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *RedisSentinel) DeepCopyInto(out *RedisSentinel) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
 	in.Spec.DeepCopyInto(&out.Spec)
-	out.Status = in.Status
+	in.Status.DeepCopyInto(&out.Status)
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RedisSentinel.
@@ -293,6 +654,97 @@ func (in *RedisSentinelList) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RedisSentinelMonitor) DeepCopyInto(out *RedisSentinelMonitor) {
+	*out = *in
+	if in.Settings != nil {
+		in, out := &in.Settings, &out.Settings
+		*out = new(RedisSentinelSettings)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RedisSentinelMonitor.
+func (in *RedisSentinelMonitor) DeepCopy() *RedisSentinelMonitor {
+	if in == nil {
+		return nil
+	}
+	out := new(RedisSentinelMonitor)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RedisSentinelRepairConfiguration) DeepCopyInto(out *RedisSentinelRepairConfiguration) {
+	*out = *in
+	if in.DownTimeThresholdMilliseconds != nil {
+		in, out := &in.DownTimeThresholdMilliseconds, &out.DownTimeThresholdMilliseconds
+		*out = new(int32)
+		**out = **in
+	}
+	if in.RestartInterval != nil {
+		in, out := &in.RestartInterval, &out.RestartInterval
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RedisSentinelRepairConfiguration.
+func (in *RedisSentinelRepairConfiguration) DeepCopy() *RedisSentinelRepairConfiguration {
+	if in == nil {
+		return nil
+	}
+	out := new(RedisSentinelRepairConfiguration)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RedisSentinelRepairStatus) DeepCopyInto(out *RedisSentinelRepairStatus) {
+	*out = *in
+	in.LastAttempt.DeepCopyInto(&out.LastAttempt)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RedisSentinelRepairStatus.
+func (in *RedisSentinelRepairStatus) DeepCopy() *RedisSentinelRepairStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(RedisSentinelRepairStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RedisSentinelSettings) DeepCopyInto(out *RedisSentinelSettings) {
+	*out = *in
+	if in.DownAfterMilliseconds != nil {
+		in, out := &in.DownAfterMilliseconds, &out.DownAfterMilliseconds
+		*out = new(int)
+		**out = **in
+	}
+	if in.FailoverTimeout != nil {
+		in, out := &in.FailoverTimeout, &out.FailoverTimeout
+		*out = new(int)
+		**out = **in
+	}
+	if in.ParallelSyncs != nil {
+		in, out := &in.ParallelSyncs, &out.ParallelSyncs
+		*out = new(int)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RedisSentinelSettings.
+func (in *RedisSentinelSettings) DeepCopy() *RedisSentinelSettings {
+	if in == nil {
+		return nil
+	}
+	out := new(RedisSentinelSettings)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *RedisSentinelSpec) DeepCopyInto(out *RedisSentinelSpec) {
 	*out = *in
@@ -302,7 +754,46 @@ func (in *RedisSentinelSpec) DeepCopyInto(out *RedisSentinelSpec) {
 		(*in).DeepCopyInto(*out)
 	}
 	in.StatefulsetConfig.DeepCopyInto(&out.StatefulsetConfig)
+	if in.Monitors != nil {
+		in, out := &in.Monitors, &out.Monitors
+		*out = make([]RedisSentinelMonitor, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Settings != nil {
+		in, out := &in.Settings, &out.Settings
+		*out = new(RedisSentinelSettings)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Repair != nil {
+		in, out := &in.Repair, &out.Repair
+		*out = new(RedisSentinelRepairConfiguration)
+		(*in).DeepCopyInto(*out)
+	}
 	in.RedisConfig.DeepCopyInto(&out.RedisConfig)
+	if in.AllowedNamespaces != nil {
+		in, out := &in.AllowedNamespaces, &out.AllowedNamespaces
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.TLSConfig != nil {
+		in, out := &in.TLSConfig, &out.TLSConfig
+		*out = new(RedisTLSConfiguration)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.VolumeMounts != nil {
+		in, out := &in.VolumeMounts, &out.VolumeMounts
+		*out = make([]corev1.VolumeMount, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Exporter != nil {
+		in, out := &in.Exporter, &out.Exporter
+		*out = new(RedisExporterConfiguration)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RedisSentinelSpec.
@@ -318,6 +809,22 @@ func (in *RedisSentinelSpec) DeepCopy() *RedisSentinelSpec {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *RedisSentinelStatus) DeepCopyInto(out *RedisSentinelStatus) {
 	*out = *in
+	if in.MonitoredMasters != nil {
+		in, out := &in.MonitoredMasters, &out.MonitoredMasters
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Repairs != nil {
+		in, out := &in.Repairs, &out.Repairs
+		*out = make([]RedisSentinelRepairStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.LastRestart != nil {
+		in, out := &in.LastRestart, &out.LastRestart
+		*out = (*in).DeepCopy()
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RedisSentinelStatus.
@@ -333,6 +840,31 @@ func (in *RedisSentinelStatus) DeepCopy() *RedisSentinelStatus {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *RedisTLSConfiguration) DeepCopyInto(out *RedisTLSConfiguration) {
 	*out = *in
+	if in.IssuerRef != nil {
+		in, out := &in.IssuerRef, &out.IssuerRef
+		*out = new(CertManagerIssuerReference)
+		**out = **in
+	}
+	if in.Duration != nil {
+		in, out := &in.Duration, &out.Duration
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.RenewBefore != nil {
+		in, out := &in.RenewBefore, &out.RenewBefore
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.CipherSuites != nil {
+		in, out := &in.CipherSuites, &out.CipherSuites
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.PlaintextPort != nil {
+		in, out := &in.PlaintextPort, &out.PlaintextPort
+		*out = new(int32)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RedisTLSConfiguration.
@@ -345,6 +877,33 @@ func (in *RedisTLSConfiguration) DeepCopy() *RedisTLSConfiguration {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ServiceMonitorConfiguration) DeepCopyInto(out *ServiceMonitorConfiguration) {
+	*out = *in
+	if in.Enabled != nil {
+		in, out := &in.Enabled, &out.Enabled
+		*out = new(bool)
+		**out = **in
+	}
+	if in.Labels != nil {
+		in, out := &in.Labels, &out.Labels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ServiceMonitorConfiguration.
+func (in *ServiceMonitorConfiguration) DeepCopy() *ServiceMonitorConfiguration {
+	if in == nil {
+		return nil
+	}
+	out := new(ServiceMonitorConfiguration)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *StatefulSetConfiguration) DeepCopyInto(out *StatefulSetConfiguration) {
 	*out = *in
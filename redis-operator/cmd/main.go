@@ -20,7 +20,7 @@ import (
 	"crypto/tls"
 	"flag"
 	"os"
-	"strings"
+	"time"
 
 	// Import all Kubernetes client auth plugins (e.g. Azure, GCP, OIDC, etc.)
 	// to ensure that exec-entrypoint and run can make use of them.
@@ -30,6 +30,7 @@ import (
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/clientcmd"
 
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
@@ -45,6 +46,9 @@ import (
 	//redisv1 "redis-operator/api/v1"
 	redisv1 "redis.operator/api/v1"
 	"redis.operator/internal/controller"
+	"redis.operator/pkg/config"
+	"redis.operator/pkg/kube/dns"
+	k8sredis "redis.operator/pkg/redis"
 	// +kubebuilder:scaffold:imports
 )
 
@@ -90,6 +94,9 @@ func main() {
 	var probeAddr string
 	var secureMetrics bool
 	var enableHTTP2 bool
+	var configFile string
+	var clusterDomain string
+	var announcePodIP bool
 	var tlsOpts []func(*tls.Config)
 	flag.StringVar(&metricsAddr, "metrics-bind-address", ":8080", "The address the metrics endpoint binds to.")
 	flag.StringVar(&probeAddr, "health-probe-bind-address", ":8081", "The address the probe endpoint binds to.")
@@ -100,6 +107,14 @@ func main() {
 		"If set, the metrics endpoint is served securely via HTTPS. Use --metrics-secure=false to use HTTP instead.")
 	flag.BoolVar(&enableHTTP2, "enable-http2", false,
 		"If set, HTTP/2 will be enabled for the metrics and webhook servers")
+	flag.StringVar(&configFile, "config", "",
+		"The operator configuration file, reloaded when it changes. "+
+			"Without it the defaults and the legacy environment variables are used.")
+	flag.StringVar(&clusterDomain, "cluster-domain", "",
+		"The domain of the cluster used in pod and service addresses, overrides the configuration file. "+
+			"Detected from /etc/resolv.conf when neither sets it.")
+	flag.BoolVar(&announcePodIP, "announce-pod-ip", false,
+		"If set, replication and sentinel pods announce their ip instead of their hostname, overrides the configuration file.")
 	opts := zap.Options{
 		Development: true,
 	}
@@ -108,6 +123,29 @@ func main() {
 
 	ctrl.SetLogger(zap.New(zap.UseFlagOptions(&opts)))
 
+	operatorConfig := config.NewDefault()
+	if configFile != "" {
+		var err error
+		if operatorConfig, err = config.Load(configFile); err != nil {
+			setupLog.Error(err, "unable to load the configuration file")
+			os.Exit(1)
+		}
+	}
+	overrides := config.DNSOverrides{}
+	flag.Visit(func(f *flag.Flag) {
+		switch f.Name {
+		case "cluster-domain":
+			overrides.ClusterDomain = &clusterDomain
+		case "announce-pod-ip":
+			overrides.AnnouncePodIP = &announcePodIP
+		}
+	})
+	config.SetDNSOverrides(overrides)
+	if err := config.Set(operatorConfig); err != nil {
+		setupLog.Error(err, "failed to apply the dns settings")
+	}
+	setupLog.Info("addressing pods", "domain", dns.GetClusterDomain(), "announcePodIP", dns.IsAnnouncingPodIP())
+
 	// if the enable-http2 flag is false (the default), http/2 should be disabled
 	// due to its vulnerabilities. More specifically, disabling http/2 will
 	// prevent from being vulnerable to the HTTP/2 Stream Cancellation and
@@ -149,13 +187,13 @@ func main() {
 		"redis-operator-system": cache.Config{},
 	}
 
-	if namespaces := os.Getenv("NAMESPACES"); namespaces != "" {
-		for _, nsSplit := range strings.Split(namespaces, ",") {
-			if namespace := strings.ReplaceAll(nsSplit, " ", ""); namespace != "" {
-				setupLog.Info("adding", "namespace:", namespace)
-				defaultNamespaces[namespace] = cache.Config{}
-			}
-		}
+	for _, namespace := range operatorConfig.Namespaces {
+		setupLog.Info("adding", "namespace:", namespace)
+		defaultNamespaces[namespace] = cache.Config{}
+	}
+	if operatorConfig.IsClusterScoped() {
+		setupLog.Info("watching all namespaces", "selector", operatorConfig.NamespaceSelector)
+		defaultNamespaces = nil
 	}
 
 	mgr, err := ctrl.NewManager(ctrl.GetConfigOrDie(), ctrl.Options{
@@ -184,41 +222,82 @@ func main() {
 		setupLog.Error(err, "unable to create dynamic k8s client")
 		os.Exit(1)
 	}
+	redisClient := k8sredis.NewRedisTopologyClient(k8sClient)
+
+	var namespaceSelector *controller.NamespaceSelector
+	if operatorConfig.NamespaceSelector != nil {
+		selector, err := metav1.LabelSelectorAsSelector(operatorConfig.NamespaceSelector)
+		if err != nil {
+			setupLog.Error(err, "invalid namespace selector")
+			os.Exit(1)
+		}
+		namespaceSelector = &controller.NamespaceSelector{Client: mgr.GetClient(), Selector: selector}
+	}
 
 	if err = (&controller.RedisReplicationReconciler{
-		Client:    mgr.GetClient(),
-		Scheme:    mgr.GetScheme(),
-		K8Client:  k8sClient,
-		Dk8Client: dk8sClient,
-		Log:       ctrl.Log.WithName("controllers").WithName("RedisReplication"),
+		Client:     mgr.GetClient(),
+		Scheme:     mgr.GetScheme(),
+		K8Client:   k8sClient,
+		Dk8Client:  dk8sClient,
+		Redis:      redisClient,
+		Log:        ctrl.Log.WithName("controllers").WithName("RedisReplication"),
+		Recorder:   mgr.GetEventRecorderFor("redisreplication-controller"),
+		Namespaces: namespaceSelector,
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "RedisReplication")
 		os.Exit(1)
 	}
-	if os.Getenv("ENABLE_WEBHOOKS") != "false" {
+	if operatorConfig.IsEnabled(config.FeatureWebhooks) {
 		if err = (&v1.RedisReplication{}).SetupWebhookWithManager(mgr); err != nil {
 			setupLog.Error(err, "unable to create webhook", "webhook", "RedisReplication")
 			os.Exit(1)
 		}
 	}
 	if err = (&controller.RedisSentinelReconciler{
-		Client:    mgr.GetClient(),
-		Scheme:    mgr.GetScheme(),
-		K8Client:  k8sClient,
-		Dk8Client: dk8sClient,
-		Log:       ctrl.Log.WithName("controllers").WithName("RedisSentinel"),
+		Client:     mgr.GetClient(),
+		Scheme:     mgr.GetScheme(),
+		K8Client:   k8sClient,
+		Dk8Client:  dk8sClient,
+		Redis:      redisClient,
+		Log:        ctrl.Log.WithName("controllers").WithName("RedisSentinel"),
+		Recorder:   mgr.GetEventRecorderFor("redissentinel-controller"),
+		Namespaces: namespaceSelector,
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "RedisSentinel")
 		os.Exit(1)
 	}
-	if os.Getenv("ENABLE_WEBHOOKS") != "false" {
+	if operatorConfig.IsEnabled(config.FeatureWebhooks) {
 		if err = (&redisv1.RedisSentinel{}).SetupWebhookWithManager(mgr); err != nil {
 			setupLog.Error(err, "unable to create webhook", "webhook", "RedisSentinel")
 			os.Exit(1)
 		}
 	}
+	if err = (&controller.RedisClusterReconciler{
+		Client:     mgr.GetClient(),
+		Scheme:     mgr.GetScheme(),
+		K8Client:   k8sClient,
+		Dk8Client:  dk8sClient,
+		Log:        ctrl.Log.WithName("controllers").WithName("RedisCluster"),
+		Recorder:   mgr.GetEventRecorderFor("rediscluster-controller"),
+		Namespaces: namespaceSelector,
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "RedisCluster")
+		os.Exit(1)
+	}
 	// +kubebuilder:scaffold:builder
 
+	if configFile != "" {
+		watcher, err := config.NewWatcher(configFile, 10*time.Second, ctrl.Log.WithName("config"))
+		if err != nil {
+			setupLog.Error(err, "unable to watch the configuration file")
+			os.Exit(1)
+		}
+		if err := mgr.Add(watcher); err != nil {
+			setupLog.Error(err, "unable to add the configuration watcher")
+			os.Exit(1)
+		}
+	}
+
 	if err := mgr.AddHealthzCheck("healthz", healthz.Ping); err != nil {
 		setupLog.Error(err, "unable to set up health check")
 		os.Exit(1)
@@ -0,0 +1,100 @@
+// Command rbacgen writes the bindings granting the operator its permissions over the namespaces it watches. A
+// cluster-scoped operator is bound to its ClusterRole cluster-wide, an operator watching a list of namespaces is
+// bound in each of them
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"redis.operator/pkg/config"
+	"sigs.k8s.io/yaml"
+)
+
+func main() {
+	var configFile string
+	var namespace string
+	var namePrefix string
+	flag.StringVar(&configFile, "config", "config/manager/operator_config.yaml", "The operator configuration file.")
+	flag.StringVar(&namespace, "namespace", "redis-operator-system", "The namespace the operator is deployed to.")
+	flag.StringVar(&namePrefix, "name-prefix", "project-", "The prefix kustomize adds to the names of the resources.")
+	flag.Parse()
+
+	operatorConfig, err := config.Load(configFile)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	var out bytes.Buffer
+	out.WriteString("# Code generated by hack/rbacgen from " + configFile + ". DO NOT EDIT.\n")
+	for _, object := range getBindings(operatorConfig, namespace, namePrefix) {
+		data, err := yaml.Marshal(object)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		out.WriteString("---\n")
+		out.Write(bytes.ReplaceAll(data, []byte("  creationTimestamp: null\n"), nil))
+	}
+	os.Stdout.Write(out.Bytes())
+}
+
+func getBindings(operatorConfig *config.OperatorConfig, namespace string, namePrefix string) []interface{} {
+	subjects := []rbacv1.Subject{{Kind: rbacv1.ServiceAccountKind, Name: namePrefix + "controller-manager", Namespace: namespace}}
+	managerRole := rbacv1.RoleRef{APIGroup: rbacv1.GroupName, Kind: "ClusterRole", Name: namePrefix + "manager-role"}
+
+	if operatorConfig.IsClusterScoped() {
+		return []interface{}{&rbacv1.ClusterRoleBinding{
+			TypeMeta:   metav1.TypeMeta{APIVersion: rbacv1.SchemeGroupVersion.String(), Kind: "ClusterRoleBinding"},
+			ObjectMeta: metav1.ObjectMeta{Name: namePrefix + "manager-rolebinding"},
+			RoleRef:    managerRole,
+			Subjects:   subjects,
+		}}
+	}
+
+	// nodes are read for the zones of the pods and subject access reviews are created by the webhooks checking
+	// references across namespaces, both are cluster-scoped
+	clusterRole := namePrefix + "manager-cluster-role"
+	objects := []interface{}{
+		&rbacv1.ClusterRole{
+			TypeMeta:   metav1.TypeMeta{APIVersion: rbacv1.SchemeGroupVersion.String(), Kind: "ClusterRole"},
+			ObjectMeta: metav1.ObjectMeta{Name: clusterRole},
+			Rules: []rbacv1.PolicyRule{
+				{APIGroups: []string{""}, Resources: []string{"nodes"}, Verbs: []string{"get", "list", "watch"}},
+				{APIGroups: []string{"authorization.k8s.io"}, Resources: []string{"subjectaccessreviews"}, Verbs: []string{"create"}},
+			},
+		},
+		&rbacv1.ClusterRoleBinding{
+			TypeMeta:   metav1.TypeMeta{APIVersion: rbacv1.SchemeGroupVersion.String(), Kind: "ClusterRoleBinding"},
+			ObjectMeta: metav1.ObjectMeta{Name: clusterRole + "binding"},
+			RoleRef:    rbacv1.RoleRef{APIGroup: rbacv1.GroupName, Kind: "ClusterRole", Name: clusterRole},
+			Subjects:   subjects,
+		},
+	}
+
+	namespaces := map[string]bool{namespace: true}
+	for _, watched := range operatorConfig.Namespaces {
+		namespaces[watched] = true
+	}
+	sorted := make([]string, 0, len(namespaces))
+	for watched := range namespaces {
+		sorted = append(sorted, watched)
+	}
+	sort.Strings(sorted)
+
+	for _, watched := range sorted {
+		objects = append(objects, &rbacv1.RoleBinding{
+			TypeMeta:   metav1.TypeMeta{APIVersion: rbacv1.SchemeGroupVersion.String(), Kind: "RoleBinding"},
+			ObjectMeta: metav1.ObjectMeta{Name: namePrefix + "manager-rolebinding", Namespace: watched},
+			RoleRef:    managerRole,
+			Subjects:   subjects,
+		})
+	}
+	return objects
+}
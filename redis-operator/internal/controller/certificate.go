@@ -0,0 +1,41 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-logr/logr"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+	"redis.operator/pkg/kube/custom"
+)
+
+// reconcileCertificate creates or updates certificate. A nil certificate removes the one left over from a previous
+// issuerRef. Returns true if the certificate was created
+func reconcileCertificate(ctx context.Context, k8Client kubernetes.Interface, dk8Client dynamic.Interface, namespace string, name string, certificate *unstructured.Unstructured, reqLogger logr.Logger) (bool, error) {
+	available, err := custom.HasResource(k8Client.Discovery(), custom.CertificateResource)
+	if err != nil {
+		return false, err
+	}
+	if !available {
+		if certificate != nil {
+			return false, fmt.Errorf("tls.issuerRef is set but the cert-manager CRDs are not installed")
+		}
+		return false, nil
+	}
+
+	if certificate == nil {
+		deleted, err := custom.Delete(ctx, dk8Client, custom.CertificateResource, namespace, name)
+		if deleted {
+			reqLogger.Info("Deleted certificate", "name", name)
+		}
+		return false, err
+	}
+
+	created, err := custom.CreateOrUpdate(ctx, dk8Client, custom.CertificateResource, certificate)
+	if created {
+		reqLogger.Info("Created certificate", "name", name)
+	}
+	return created, err
+}
@@ -0,0 +1,61 @@
+package controller
+
+import (
+	"sync"
+
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// reasons used for the events recorded against RedisReplication, RedisSentinel and RedisCluster instances
+const (
+	EventReasonCreated             = "Created"
+	EventReasonConfigUpdated       = "ConfigUpdated"
+	EventReasonPromoted            = "Promoted"
+	EventReasonDemoted             = "Demoted"
+	EventReasonSentinelRestart     = "SentinelRestarted"
+	EventReasonQuorumLost          = "QuorumLost"
+	EventReasonConnectionFailed    = "ConnectionFailed"
+	EventReasonCertReloaded        = "CertificateReloaded"
+	EventReasonElectionRefused     = "ElectionRefused"
+	EventReasonSplitBrain          = "SplitBrain"
+	EventReasonNodeJoined          = "NodeJoined"
+	EventReasonNodeForgotten       = "NodeForgotten"
+	EventReasonSlotsAssigned       = "SlotsAssigned"
+	EventReasonSlotsMigrated       = "SlotsMigrated"
+	EventReasonShardRemoved        = "ShardRemoved"
+	EventReasonMonitorAdded        = "MonitorAdded"
+	EventReasonMonitorRemoved      = "MonitorRemoved"
+	EventReasonSentinelReset       = "SentinelReset"
+	EventReasonSentinelRemonitored = "SentinelRemonitored"
+	EventReasonReferenceDenied     = "ReferenceDenied"
+)
+
+// unreachablePods remembers the pods found unreachable by the previous reconcile of every instance, so the
+// ConnectionFailed event is recorded when a pod becomes unreachable rather than on every requeue
+type unreachablePods struct {
+	pods sync.Map // set of unreachable pod names, keyed by instance
+}
+
+// update stores the unreachable pods of an instance and returns the ones that were reachable at the previous
+// reconcile
+func (u *unreachablePods) update(key types.NamespacedName, pods []string) []string {
+	current := make(map[string]bool, len(pods))
+	for _, pod := range pods {
+		current[pod] = true
+	}
+	value, _ := u.pods.Swap(key, current)
+	previous, _ := value.(map[string]bool)
+
+	transitioned := []string{}
+	for _, pod := range pods {
+		if !previous[pod] {
+			transitioned = append(transitioned, pod)
+		}
+	}
+	return transitioned
+}
+
+// forget drops the pods of a deleted instance
+func (u *unreachablePods) forget(key types.NamespacedName) {
+	u.pods.Delete(key)
+}
@@ -0,0 +1,22 @@
+package controller
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+var _ = Describe("Unreachable pods", func() {
+	It("only reports the pods that became unreachable", func() {
+		unreachable := unreachablePods{}
+		key := types.NamespacedName{Name: "redis", Namespace: "default"}
+
+		Expect(unreachable.update(key, []string{"redis-0"})).To(ConsistOf("redis-0"))
+		Expect(unreachable.update(key, []string{"redis-0", "redis-1"})).To(ConsistOf("redis-1"))
+		Expect(unreachable.update(key, []string{"redis-1"})).To(BeEmpty())
+		Expect(unreachable.update(key, []string{"redis-0", "redis-1"})).To(ConsistOf("redis-0"))
+
+		unreachable.forget(key)
+		Expect(unreachable.update(key, []string{"redis-0"})).To(ConsistOf("redis-0"))
+	})
+})
@@ -0,0 +1,341 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-logr/logr"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	redisv1 "redis.operator/api/v1"
+	k8sredis "redis.operator/pkg/redis"
+	"redis.operator/pkg/redis/fake"
+)
+
+// receivedEvents drains the events recorded since the last call
+func receivedEvents(recorder *record.FakeRecorder) []string {
+	events := []string{}
+	for {
+		select {
+		case event := <-recorder.Events:
+			events = append(events, event)
+		default:
+			return events
+		}
+	}
+}
+
+// scenario drives the replication and sentinel reconcilers against envtest, the redis and sentinel pods are
+// simulated by a fake topology
+type scenario struct {
+	topology    *fake.Topology
+	recorder    *record.FakeRecorder
+	replication *RedisReplicationReconciler
+	sentinel    *RedisSentinelReconciler
+	cleanup     []func()
+}
+
+func newScenario() *scenario {
+	s := &scenario{
+		topology: fake.NewTopology(),
+		recorder: record.NewFakeRecorder(1000),
+	}
+	s.replication = &RedisReplicationReconciler{
+		Client:    k8sClient,
+		K8Client:  k8sClientset,
+		Dk8Client: dynamicClient,
+		Redis:     s.topology,
+		Scheme:    k8sClient.Scheme(),
+		Log:       logr.Discard(),
+		Recorder:  s.recorder,
+	}
+	s.sentinel = &RedisSentinelReconciler{
+		Client:    k8sClient,
+		K8Client:  k8sClientset,
+		Dk8Client: dynamicClient,
+		Redis:     s.topology,
+		Scheme:    k8sClient.Scheme(),
+		Log:       logr.Discard(),
+		Recorder:  s.recorder,
+	}
+	return s
+}
+
+func (s *scenario) createReplication(ctx context.Context, name string, replicas int32, sentinelName string) *redisv1.RedisReplication {
+	instance := &redisv1.RedisReplication{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+		Spec: redisv1.RedisReplicationSpec{
+			RedisConfig: redisv1.RedisReplicationConfiguration{
+				RedisConfigurationData: redisv1.RedisConfigurationData{Data: map[string]string{"redis.conf": "port 6379"}},
+			},
+		},
+	}
+	instance.Spec.StatefulsetConfig.Wrapper.Spec.Replicas = ptr.To(replicas)
+	if sentinelName != "" {
+		instance.Spec.RedisSentinelConfig = &redisv1.RedisReplicationSentinelConfig{RedisSentinelName: sentinelName}
+	}
+	Expect(k8sClient.Create(ctx, instance)).To(Succeed())
+
+	s.cleanup = append(s.cleanup, func() {
+		Expect(k8sClient.Delete(ctx, instance)).To(Succeed())
+		s.reconcileReplication(ctx, instance)
+	})
+	return instance
+}
+
+func (s *scenario) createSentinel(ctx context.Context, name string, replicationName string) *redisv1.RedisSentinel {
+	instance := &redisv1.RedisSentinel{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+		Spec: redisv1.RedisSentinelSpec{
+			MasterName:           "mymaster",
+			RedisReplicationName: replicationName,
+			RedisSentinelQuorum:  2,
+			RedisConfig:          *k8sredis.GetSentinelConfigMap(nil),
+		},
+	}
+	instance.Spec.StatefulsetConfig.Wrapper.Spec.Replicas = ptr.To(int32(3))
+	Expect(k8sClient.Create(ctx, instance)).To(Succeed())
+
+	s.cleanup = append(s.cleanup, func() {
+		Expect(k8sClient.Delete(ctx, instance)).To(Succeed())
+		s.reconcileSentinel(ctx, instance)
+	})
+	return instance
+}
+
+func (s *scenario) reconcileReplication(ctx context.Context, instance *redisv1.RedisReplication) {
+	_, err := s.replication.Reconcile(ctx, reconcile.Request{NamespacedName: types.NamespacedName{Namespace: instance.Namespace, Name: instance.Name}})
+	Expect(err).NotTo(HaveOccurred())
+}
+
+func (s *scenario) reconcileSentinel(ctx context.Context, instance *redisv1.RedisSentinel) {
+	_, err := s.sentinel.Reconcile(ctx, reconcile.Request{NamespacedName: types.NamespacedName{Namespace: instance.Namespace, Name: instance.Name}})
+	Expect(err).NotTo(HaveOccurred())
+}
+
+// getReplication returns the stored replication, with its latest spec and status
+func (s *scenario) getReplication(ctx context.Context, instance *redisv1.RedisReplication) *redisv1.RedisReplication {
+	stored := &redisv1.RedisReplication{}
+	Expect(k8sClient.Get(ctx, types.NamespacedName{Namespace: instance.Namespace, Name: instance.Name}, stored)).To(Succeed())
+	return stored
+}
+
+func (s *scenario) getSentinel(ctx context.Context, instance *redisv1.RedisSentinel) *redisv1.RedisSentinel {
+	stored := &redisv1.RedisSentinel{}
+	Expect(k8sClient.Get(ctx, types.NamespacedName{Namespace: instance.Namespace, Name: instance.Name}, stored)).To(Succeed())
+	return stored
+}
+
+func (s *scenario) close() {
+	for i := len(s.cleanup) - 1; i >= 0; i-- {
+		s.cleanup[i]()
+	}
+}
+
+// slaveOf returns the command making a pod of instance replicate the pod at index master
+func slaveOf(instance *redisv1.RedisReplication, pod int, master int) string {
+	return fmt.Sprintf("%s SLAVEOF %s %s", fake.ReplicationPodDNS(instance, pod), fake.ReplicationPodDNS(instance, master), instance.GetReplicationPort())
+}
+
+func promote(instance *redisv1.RedisReplication, pod int) string {
+	return fmt.Sprintf("%s SLAVEOF NO ONE", fake.ReplicationPodDNS(instance, pod))
+}
+
+var _ = Describe("Failover scenarios", func() {
+	ctx := context.Background()
+	var s *scenario
+
+	BeforeEach(func() {
+		s = newScenario()
+	})
+
+	AfterEach(func() {
+		s.close()
+	})
+
+	It("elects the first pod on bootstrap", func() {
+		instance := s.createReplication(ctx, "bootstrap", 3, "")
+		s.topology.AddReplication(instance, -1)
+
+		s.reconcileReplication(ctx, instance)
+		Expect(s.topology.Commands()).To(ConsistOf(promote(instance, 0), slaveOf(instance, 1, 0), slaveOf(instance, 2, 0)))
+
+		s.reconcileReplication(ctx, instance)
+		Expect(s.getReplication(ctx, instance).Status.MasterDns).To(Equal(fake.ReplicationPodDNS(instance, 0)))
+		Expect(receivedEvents(s.recorder)).To(ContainElement(ContainSubstring(EventReasonDemoted)))
+	})
+
+	It("promotes a replica once the master is lost without sentinel and demotes the old master when it returns", func() {
+		instance := s.createReplication(ctx, "failover", 3, "")
+		s.topology.AddReplication(instance, 0)
+		Expect(s.topology.Write(fake.ReplicationPodDNS(instance, 0), 100)).To(Succeed())
+		s.reconcileReplication(ctx, instance)
+		Expect(s.topology.Commands()).To(BeEmpty())
+
+		s.topology.KillPod(fake.ReplicationPodDNS(instance, 0))
+		s.reconcileReplication(ctx, instance)
+		Expect(s.topology.Commands()).To(ConsistOf(promote(instance, 1), slaveOf(instance, 2, 1)))
+		Expect(receivedEvents(s.recorder)).To(ContainElement(ContainSubstring(EventReasonPromoted)))
+
+		s.reconcileReplication(ctx, instance)
+		Expect(s.getReplication(ctx, instance).Status.MasterDns).To(Equal(fake.ReplicationPodDNS(instance, 1)))
+
+		s.topology.ResetCommands()
+		s.topology.RestorePod(fake.ReplicationPodDNS(instance, 0))
+		s.reconcileReplication(ctx, instance)
+		Expect(s.topology.Commands()).To(ContainElements(
+			fake.ReplicationPodDNS(instance, 0)+" CLIENT PAUSE WRITE",
+			slaveOf(instance, 0, 1),
+			fake.ReplicationPodDNS(instance, 0)+" CLIENT UNPAUSE",
+		))
+		condition := meta.FindStatusCondition(s.getReplication(ctx, instance).Status.Conditions, redisv1.ConditionSplitBrain)
+		Expect(condition).NotTo(BeNil())
+		Expect(condition.Reason).To(Equal("StaleMastersDemoted"))
+	})
+
+	It("breaks ties between replicas by replica-priority, then by pod index", func() {
+		instance := s.createReplication(ctx, "tie", 4, "")
+		s.topology.AddReplication(instance, 0)
+		Expect(s.topology.SetReplicaPriority(ctx, instance, map[int]int{1: 100, 2: 50, 3: 50}, logr.Discard())).To(Succeed())
+		s.reconcileReplication(ctx, instance)
+		s.topology.ResetCommands()
+
+		s.topology.KillPod(fake.ReplicationPodDNS(instance, 0))
+		s.reconcileReplication(ctx, instance)
+		Expect(s.topology.Commands()).To(ConsistOf(promote(instance, 2), slaveOf(instance, 1, 2), slaveOf(instance, 3, 2)))
+	})
+
+	It("promotes the replica holding the most writes", func() {
+		instance := s.createReplication(ctx, "lagging", 3, "")
+		s.topology.AddReplication(instance, 0)
+		s.reconcileReplication(ctx, instance)
+
+		s.topology.KillPod(fake.ReplicationPodDNS(instance, 1))
+		Expect(s.topology.Write(fake.ReplicationPodDNS(instance, 0), 100)).To(Succeed())
+		s.reconcileReplication(ctx, instance)
+		s.topology.KillPod(fake.ReplicationPodDNS(instance, 0))
+		s.topology.RestorePod(fake.ReplicationPodDNS(instance, 1))
+		s.topology.ResetCommands()
+
+		s.reconcileReplication(ctx, instance)
+		Expect(s.topology.Commands()).To(ConsistOf(promote(instance, 2), slaveOf(instance, 1, 2)))
+	})
+
+	It("elects a new master when the master is scaled down", func() {
+		instance := s.createReplication(ctx, "scaledown", 3, "")
+		s.topology.AddReplication(instance, 2)
+		s.reconcileReplication(ctx, instance)
+		Expect(s.getReplication(ctx, instance).Status.MasterDns).To(Equal(fake.ReplicationPodDNS(instance, 2)))
+
+		stored := s.getReplication(ctx, instance)
+		stored.Spec.StatefulsetConfig.Wrapper.Spec.Replicas = ptr.To(int32(2))
+		Expect(k8sClient.Update(ctx, stored)).To(Succeed())
+		s.topology.KillPod(fake.ReplicationPodDNS(instance, 2))
+
+		s.reconcileReplication(ctx, instance)
+		Expect(s.topology.Commands()).To(ConsistOf(promote(instance, 0), slaveOf(instance, 1, 0)))
+
+		s.reconcileReplication(ctx, instance)
+		Expect(s.getReplication(ctx, instance).Status.MasterDns).To(Equal(fake.ReplicationPodDNS(instance, 0)))
+	})
+
+	It("promotes the master agreed by the sentinels", func() {
+		instance := s.createReplication(ctx, "sentinel-failover", 3, "sentinel-failover-sentinel")
+		sentinel := s.createSentinel(ctx, "sentinel-failover-sentinel", instance.Name)
+		s.topology.AddReplication(instance, 0)
+		s.topology.AddSentinel(sentinel)
+
+		s.reconcileSentinel(ctx, sentinel)
+		for i := 0; i < 3; i++ {
+			Expect(s.topology.Commands()).To(ContainElement(fmt.Sprintf("%s SENTINEL MONITOR mymaster %s %s 2", fake.SentinelPodDNS(sentinel, i), fake.ReplicationPodDNS(instance, 0), instance.GetReplicationPort())))
+		}
+		Expect(s.getSentinel(ctx, sentinel).Status.MonitoredMasters).To(Equal([]string{"mymaster"}))
+		Expect(receivedEvents(s.recorder)).To(ContainElement(ContainSubstring(EventReasonMonitorAdded)))
+
+		s.topology.KillPod(fake.ReplicationPodDNS(instance, 0))
+		Expect(s.topology.SetSentinelMaster(fake.SentinelPodDNS(sentinel, 0), "mymaster", fake.ReplicationPodDNS(instance, 2))).To(Succeed())
+		Expect(s.topology.SetSentinelMaster(fake.SentinelPodDNS(sentinel, 1), "mymaster", fake.ReplicationPodDNS(instance, 2))).To(Succeed())
+		s.topology.ResetCommands()
+
+		s.reconcileReplication(ctx, instance)
+		Expect(s.topology.Commands()).To(ConsistOf(promote(instance, 2), slaveOf(instance, 1, 2)))
+
+		s.reconcileReplication(ctx, instance)
+		Expect(s.getReplication(ctx, instance).Status.MasterDns).To(Equal(fake.ReplicationPodDNS(instance, 2)))
+	})
+
+	It("promotes nothing while the sentinels disagree", func() {
+		instance := s.createReplication(ctx, "disagreement", 3, "disagreement-sentinel")
+		sentinel := s.createSentinel(ctx, "disagreement-sentinel", instance.Name)
+		s.topology.AddReplication(instance, 0)
+		s.topology.AddSentinel(sentinel)
+		s.reconcileSentinel(ctx, sentinel)
+		s.reconcileReplication(ctx, instance)
+
+		s.topology.KillPod(fake.ReplicationPodDNS(instance, 0))
+		Expect(s.topology.SetSentinelMaster(fake.SentinelPodDNS(sentinel, 0), "mymaster", fake.ReplicationPodDNS(instance, 1))).To(Succeed())
+		Expect(s.topology.SetSentinelMaster(fake.SentinelPodDNS(sentinel, 1), "mymaster", fake.ReplicationPodDNS(instance, 2))).To(Succeed())
+		s.topology.ResetCommands()
+		receivedEvents(s.recorder)
+
+		s.reconcileReplication(ctx, instance)
+		Expect(s.topology.Commands()).To(BeEmpty())
+		Expect(receivedEvents(s.recorder)).To(ContainElement(ContainSubstring(EventReasonQuorumLost)))
+		Expect(s.getReplication(ctx, instance).Status.MasterDns).To(Equal(fake.ReplicationPodDNS(instance, 0)))
+	})
+
+	It("applies configuration changes", func() {
+		instance := s.createReplication(ctx, "config", 3, "config-sentinel")
+		sentinel := s.createSentinel(ctx, "config-sentinel", instance.Name)
+		s.topology.AddReplication(instance, 0)
+		s.topology.AddSentinel(sentinel)
+		s.reconcileReplication(ctx, instance)
+		s.reconcileSentinel(ctx, sentinel)
+		s.topology.ResetCommands()
+		receivedEvents(s.recorder)
+
+		storedReplication := s.getReplication(ctx, instance)
+		storedReplication.Spec.RedisConfig.Data["redis.conf"] = "port 6379\nmaxmemory 100mb"
+		Expect(k8sClient.Update(ctx, storedReplication)).To(Succeed())
+		s.reconcileReplication(ctx, instance)
+
+		configMap, err := k8sClientset.CoreV1().ConfigMaps(instance.Namespace).Get(ctx, instance.GetConfigName(), metav1.GetOptions{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(configMap.Data["redis.conf"]).To(ContainSubstring("maxmemory 100mb"))
+		Expect(receivedEvents(s.recorder)).To(ContainElement(ContainSubstring(EventReasonConfigUpdated)))
+
+		storedSentinel := s.getSentinel(ctx, sentinel)
+		storedSentinel.Spec.Settings = &redisv1.RedisSentinelSettings{DownAfterMilliseconds: ptr.To(5000)}
+		Expect(k8sClient.Update(ctx, storedSentinel)).To(Succeed())
+		s.reconcileSentinel(ctx, sentinel)
+
+		for i := 0; i < 3; i++ {
+			Expect(s.topology.Commands()).To(ContainElement(fake.SentinelPodDNS(sentinel, i) + " SENTINEL SET mymaster down-after-milliseconds 5000"))
+			Expect(s.topology.SentinelMaster(fake.SentinelPodDNS(sentinel, i), "mymaster")).To(HaveKeyWithValue("down-after-milliseconds", "5000"))
+		}
+		Expect(receivedEvents(s.recorder)).To(ContainElement(ContainSubstring("Updated the settings of master mymaster")))
+	})
+})
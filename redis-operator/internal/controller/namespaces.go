@@ -0,0 +1,67 @@
+package controller
+
+import (
+	"context"
+	"errors"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// errReferenceDenied is returned when the allowedNamespaces of a referenced resource don't contain the namespace of
+// the referencing one
+var errReferenceDenied = errors.New("reference denied")
+
+// NamespaceSelector restricts the reconciled resources to the namespaces whose labels match Selector. A nil
+// NamespaceSelector matches every namespace the cache of the manager watches
+type NamespaceSelector struct {
+	Client   client.Client
+	Selector labels.Selector
+}
+
+// Matches returns whether the resources of namespace are reconciled
+func (s *NamespaceSelector) Matches(ctx context.Context, namespace string) (bool, error) {
+	if s == nil {
+		return true, nil
+	}
+
+	ns := &corev1.Namespace{}
+	if err := s.Client.Get(ctx, types.NamespacedName{Name: namespace}, ns); err != nil {
+		return false, err
+	}
+	return s.Selector.Matches(labels.Set(ns.Labels)), nil
+}
+
+// watchNamespaces enqueues every resource of list in a namespace whose labels change, so namespaces labelled after
+// the operator started are picked up
+func (s *NamespaceSelector) watchNamespaces(b *builder.Builder, list client.ObjectList) *builder.Builder {
+	if s == nil {
+		return b
+	}
+
+	return b.Watches(&corev1.Namespace{}, handler.EnqueueRequestsFromMapFunc(func(ctx context.Context, obj client.Object) []reconcile.Request {
+		resources := list.DeepCopyObject().(client.ObjectList)
+		if err := s.Client.List(ctx, resources, client.InNamespace(obj.GetName())); err != nil {
+			ctrl.LoggerFrom(ctx).Error(err, "failed to list the resources of a namespace", "namespace", obj.GetName())
+			return nil
+		}
+
+		requests := []reconcile.Request{}
+		_ = meta.EachListItem(resources, func(item runtime.Object) error {
+			if resource, ok := item.(client.Object); ok {
+				requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(resource)})
+			}
+			return nil
+		})
+		return requests
+	}), builder.WithPredicates(predicate.LabelChangedPredicate{}))
+}
@@ -0,0 +1,67 @@
+package controller
+
+import (
+	"context"
+
+	"github.com/go-logr/logr"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	redisv1 "redis.operator/api/v1"
+	"redis.operator/pkg/redis/fake"
+)
+
+var _ = Describe("Namespace selection", func() {
+	ctx := context.Background()
+
+	It("only reconciles the resources of labelled namespaces", func() {
+		namespace := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "selected-namespace"}}
+		Expect(k8sClient.Create(ctx, namespace)).To(Succeed())
+
+		key := types.NamespacedName{Name: "selected", Namespace: namespace.Name}
+		Expect(k8sClient.Create(ctx, &redisv1.RedisReplication{
+			ObjectMeta: metav1.ObjectMeta{Name: key.Name, Namespace: key.Namespace},
+		})).To(Succeed())
+
+		reconciler := &RedisReplicationReconciler{
+			Client:    k8sClient,
+			K8Client:  k8sClientset,
+			Dk8Client: dynamicClient,
+			Redis:     fake.NewTopology(),
+			Scheme:    k8sClient.Scheme(),
+			Log:       logr.Discard(),
+			Recorder:  record.NewFakeRecorder(100),
+			Namespaces: &NamespaceSelector{
+				Client:   k8sClient,
+				Selector: labels.SelectorFromSet(labels.Set{"redis.operator/watched": "true"}),
+			},
+		}
+
+		By("skipping the resource while its namespace is not labelled")
+		_, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+		Expect(err).NotTo(HaveOccurred())
+		instance := &redisv1.RedisReplication{}
+		Expect(k8sClient.Get(ctx, key, instance)).To(Succeed())
+		Expect(controllerutil.ContainsFinalizer(instance, redisv1.RedisReplicationFinalizer)).To(BeFalse())
+
+		By("reconciling the resource once its namespace is labelled")
+		namespace.Labels = map[string]string{"redis.operator/watched": "true"}
+		Expect(k8sClient.Update(ctx, namespace)).To(Succeed())
+		_, err = reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(k8sClient.Get(ctx, key, instance)).To(Succeed())
+		Expect(controllerutil.ContainsFinalizer(instance, redisv1.RedisReplicationFinalizer)).To(BeTrue())
+
+		By("releasing the finalizer of the deleted resource")
+		Expect(k8sClient.Delete(ctx, instance)).To(Succeed())
+		_, err = reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+		Expect(err).NotTo(HaveOccurred())
+	})
+})
@@ -0,0 +1,556 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strconv"
+	"time"
+
+	"github.com/go-logr/logr"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/record"
+	v1 "redis.operator/api/v1"
+	"redis.operator/pkg/config"
+	"redis.operator/pkg/metrics"
+	k8sredis "redis.operator/pkg/redis"
+	"redis.operator/pkg/rediscluster"
+	"redis.operator/pkg/util/result"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+// RedisClusterReconciler reconciles a RedisCluster object
+type RedisClusterReconciler struct {
+	client.Client
+	K8Client  kubernetes.Interface
+	Dk8Client dynamic.Interface
+	Scheme    *runtime.Scheme
+	Log       logr.Logger
+	Recorder  record.EventRecorder
+
+	Namespaces *NamespaceSelector // reconciles every watched namespace when nil
+
+	unreachable unreachablePods
+}
+
+func (r *RedisClusterReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	reqLogger := r.Log.WithValues("Request.Namespace", req.Namespace, "Request.Name", req.Name)
+	instance := &v1.RedisCluster{}
+
+	err := r.Client.Get(ctx, req.NamespacedName, instance)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return result.ReconciledWithMessage(reqLogger, "Failed to get instance. Assumming it was deleted")
+		}
+		return result.FailedWithError(err, reqLogger, "Error reconciling instance")
+	}
+
+	if instance.ObjectMeta.GetDeletionTimestamp() != nil {
+		if controllerutil.ContainsFinalizer(instance, v1.RedisClusterFinalizer) {
+			controllerutil.RemoveFinalizer(instance, v1.RedisClusterFinalizer)
+			if err = r.Client.Update(ctx, instance); err != nil {
+				return result.RetryWithError(err, reqLogger, "Failed to handle finalizer")
+			}
+		}
+		r.unreachable.forget(req.NamespacedName)
+		metrics.DeleteInstanceMetrics(metrics.KindCluster, instance.Namespace, instance.Name)
+		k8sredis.InvalidateClients(instance.GetHeadlessServiceName(), instance.Namespace)
+		return result.Ok()
+	}
+
+	// resources of namespaces no longer selected still release their finalizer above
+	selected, err := r.Namespaces.Matches(ctx, instance.Namespace)
+	if err != nil {
+		return result.RetryWithError(err, reqLogger, "Failed to get namespace")
+	}
+	if !selected {
+		return result.ReconciledWithMessage(reqLogger, "Namespace not selected by the operator. Skipping")
+	}
+
+	if !controllerutil.ContainsFinalizer(instance, v1.RedisClusterFinalizer) {
+		controllerutil.AddFinalizer(instance, v1.RedisClusterFinalizer)
+		if err = r.Client.Update(ctx, instance); err != nil {
+			return result.RetryWithError(err, reqLogger, "Failed to create finalizer")
+		}
+	}
+
+	start := time.Now()
+	if err = r.CreateOrUpdateServices(ctx, instance, reqLogger); err != nil {
+		return result.RetryWithError(err, reqLogger, "Failed to create service for redis cluster")
+	}
+	metrics.ObserveStep(metrics.KindCluster, instance.Namespace, instance.Name, metrics.StepService, start)
+
+	start = time.Now()
+	if err = r.CreateOrUpdateConfigMap(ctx, instance, reqLogger); err != nil {
+		return result.RetryWithError(err, reqLogger, "Failed to create configmap for redis cluster")
+	}
+	metrics.ObserveStep(metrics.KindCluster, instance.Namespace, instance.Name, metrics.StepConfigMap, start)
+
+	start = time.Now()
+	if err = r.CreateOrUpdateCertificate(ctx, instance, reqLogger); err != nil {
+		return result.RetryWithError(err, reqLogger, "Failed to create certificate for redis cluster")
+	}
+
+	if err = r.CreateOrUpdateAuthSecret(ctx, instance, reqLogger); err != nil {
+		return result.RetryWithError(err, reqLogger, "Failed to create auth secret for redis cluster")
+	}
+
+	if err = r.CreateOrUpdateStatefulSets(ctx, instance, reqLogger); err != nil {
+		return result.RetryWithError(err, reqLogger, "Failed to create statefulsets for redis cluster")
+	}
+	metrics.ObserveStep(metrics.KindCluster, instance.Namespace, instance.Name, metrics.StepStatefulSet, start)
+
+	if err = r.ReloadTLSCertificates(ctx, instance, reqLogger); err != nil {
+		return result.RetryWithError(err, reqLogger, "Failed to reload tls certificates")
+	}
+
+	start = time.Now()
+	nodes, err := r.UpdateCluster(ctx, instance, reqLogger)
+	if err != nil {
+		return result.RetryWithError(err, reqLogger, "Failed to update redis cluster")
+	}
+	metrics.ObserveStep(metrics.KindCluster, instance.Namespace, instance.Name, metrics.StepCluster, start)
+
+	start = time.Now()
+	if err = r.Reshard(ctx, instance, nodes, reqLogger); err != nil {
+		return result.RetryWithError(err, reqLogger, "Failed to reshard redis cluster")
+	}
+	metrics.ObserveStep(metrics.KindCluster, instance.Namespace, instance.Name, metrics.StepResharding, start)
+
+	return result.RequeueAfter(config.Get().RequeueInterval.Duration)
+}
+
+// ReloadTLSCertificates makes the nodes load the certificate again after the tls secret changed, one shard per
+// reconcile. The resourceVersion of the secret is only recorded once all reachable nodes serve the new certificate
+func (r *RedisClusterReconciler) ReloadTLSCertificates(ctx context.Context, instance *v1.RedisCluster, reqLogger logr.Logger) error {
+	if instance.Spec.TLSConfig == nil {
+		return nil
+	}
+
+	secret, err := r.K8Client.CoreV1().Secrets(instance.Namespace).Get(ctx, instance.GetTLSSecretName(), metav1.GetOptions{})
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil // not issued yet
+		}
+		return err
+	}
+	if secret.ResourceVersion == instance.Status.TLSSecretVersion {
+		return nil
+	}
+
+	// nodes started after the secret was created have already loaded it
+	if instance.Status.TLSSecretVersion != "" {
+		reloaded, err := k8sredis.ReloadClusterCertificates(ctx, r.K8Client, instance, reqLogger)
+		if err != nil {
+			return err
+		}
+		if !reloaded {
+			return nil
+		}
+		reqLogger.Info("Reloaded tls certificates", "secret", secret.Name)
+		r.Recorder.Eventf(instance, corev1.EventTypeNormal, EventReasonCertReloaded, "Reloaded certificate from secret %s", secret.Name)
+	}
+
+	instance.Status.TLSSecretVersion = secret.ResourceVersion
+	return r.Client.Status().Update(ctx, instance)
+}
+
+func (r *RedisClusterReconciler) CreateOrUpdateServices(ctx context.Context, instance *v1.RedisCluster, reqLogger logr.Logger) error {
+	for _, newService := range []corev1.Service{
+		rediscluster.CreateHeadlessClusterService(instance),
+		rediscluster.CreateClusterService(instance),
+	} {
+		_, err := r.K8Client.CoreV1().Services(instance.Namespace).Get(ctx, newService.Name, metav1.GetOptions{})
+		if err != nil {
+			if !apierrors.IsNotFound(err) {
+				return err
+			}
+			reqLogger.Info("Creating service", "name", newService.Name)
+			if _, err := r.K8Client.CoreV1().Services(instance.Namespace).Create(ctx, &newService, metav1.CreateOptions{}); err != nil {
+				return err
+			}
+			r.Recorder.Eventf(instance, corev1.EventTypeNormal, EventReasonCreated, "Created service %s", newService.Name)
+			continue
+		}
+
+		if _, err = r.K8Client.CoreV1().Services(instance.Namespace).Update(ctx, &newService, metav1.UpdateOptions{}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *RedisClusterReconciler) CreateOrUpdateConfigMap(ctx context.Context, instance *v1.RedisCluster, reqLogger logr.Logger) error {
+	configMap, err := rediscluster.CreateConfigMap(instance)
+	if err != nil {
+		return err
+	}
+
+	currentConfigMap, err := r.K8Client.CoreV1().ConfigMaps(instance.Namespace).Get(ctx, instance.GetConfigName(), metav1.GetOptions{})
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			reqLogger.Info("Creating configmap")
+			if _, err := r.K8Client.CoreV1().ConfigMaps(instance.Namespace).Create(ctx, configMap, metav1.CreateOptions{}); err != nil {
+				return err
+			}
+			r.Recorder.Eventf(instance, corev1.EventTypeNormal, EventReasonCreated, "Created configmap %s", configMap.Name)
+			return nil
+		}
+		return err
+	}
+
+	if _, err = r.K8Client.CoreV1().ConfigMaps(instance.Namespace).Update(ctx, configMap, metav1.UpdateOptions{}); err != nil {
+		return err
+	}
+	if !reflect.DeepEqual(currentConfigMap.Data, configMap.Data) {
+		r.Recorder.Eventf(instance, corev1.EventTypeNormal, EventReasonConfigUpdated, "Updated configmap %s", configMap.Name)
+	}
+	return nil
+}
+
+// CreateOrUpdateCertificate manages the cert-manager Certificate of an instance with tls.issuerRef set
+func (r *RedisClusterReconciler) CreateOrUpdateCertificate(ctx context.Context, instance *v1.RedisCluster, reqLogger logr.Logger) error {
+	var certificate *unstructured.Unstructured
+	if instance.Spec.TLSConfig != nil && instance.Spec.TLSConfig.IssuerRef != nil {
+		certificate = rediscluster.CreateCertificate(instance)
+	}
+
+	created, err := reconcileCertificate(ctx, r.K8Client, r.Dk8Client, instance.Namespace, instance.GetCertificateName(), certificate, reqLogger)
+	if err != nil {
+		return err
+	}
+	if created {
+		r.Recorder.Eventf(instance, corev1.EventTypeNormal, EventReasonCreated, "Created certificate %s", instance.GetCertificateName())
+	}
+	return nil
+}
+
+// CreateOrUpdateAuthSecret stores requirepass in the secret read by the exporter. Skipped when the exporter is
+// disabled or uses a secret of its own
+func (r *RedisClusterReconciler) CreateOrUpdateAuthSecret(ctx context.Context, instance *v1.RedisCluster, reqLogger logr.Logger) error {
+	if !instance.IsExporterEnabled() {
+		return nil
+	}
+
+	passwordSecret, err := rediscluster.GetExporterPasswordSecret(instance)
+	if err != nil {
+		return err
+	}
+	if passwordSecret == nil || passwordSecret.Name != instance.GetAuthSecretName() {
+		return nil
+	}
+
+	secret, err := rediscluster.CreateAuthSecret(instance)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.K8Client.CoreV1().Secrets(instance.Namespace).Get(ctx, secret.Name, metav1.GetOptions{})
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			reqLogger.Info("Creating auth secret")
+			if _, err := r.K8Client.CoreV1().Secrets(instance.Namespace).Create(ctx, secret, metav1.CreateOptions{}); err != nil {
+				return err
+			}
+			r.Recorder.Eventf(instance, corev1.EventTypeNormal, EventReasonCreated, "Created secret %s", secret.Name)
+			return nil
+		}
+		return err
+	}
+	_, err = r.K8Client.CoreV1().Secrets(instance.Namespace).Update(ctx, secret, metav1.UpdateOptions{})
+	return err
+}
+
+// CreateOrUpdateStatefulSets manages the statefulset of every shard, including the shards being drained
+func (r *RedisClusterReconciler) CreateOrUpdateStatefulSets(ctx context.Context, instance *v1.RedisCluster, reqLogger logr.Logger) error {
+	initContainer, err := rediscluster.CreateContainer(instance)
+	if err != nil {
+		return err
+	}
+
+	redisContainers, err := rediscluster.CreateContainers(instance)
+	if err != nil {
+		return err
+	}
+
+	for shard := 0; shard < instance.GetDeployedShards(); shard++ {
+		statefulSet := rediscluster.CreateStatefulSet(instance, shard, redisContainers, initContainer)
+
+		_, err = r.K8Client.AppsV1().StatefulSets(instance.Namespace).Get(ctx, statefulSet.Name, metav1.GetOptions{})
+		if err != nil {
+			if !apierrors.IsNotFound(err) {
+				return err
+			}
+			reqLogger.Info("Creating statefulset", "shard", shard)
+			if _, err = r.K8Client.AppsV1().StatefulSets(instance.Namespace).Create(ctx, statefulSet, metav1.CreateOptions{}); err != nil {
+				return err
+			}
+			r.Recorder.Eventf(instance, corev1.EventTypeNormal, EventReasonCreated, "Created statefulset %s", statefulSet.Name)
+			continue
+		}
+
+		if _, err = r.K8Client.AppsV1().StatefulSets(instance.Namespace).Update(ctx, statefulSet, metav1.UpdateOptions{}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// UpdateCluster joins the reachable nodes, assigns the slots and replicas of every shard and forgets replaced
+// nodes. The status reports the slot coverage seen by the node knowing the most nodes. Returns the nodes once
+// every pod is reachable and the cluster needs no change, nil otherwise
+func (r *RedisClusterReconciler) UpdateCluster(ctx context.Context, instance *v1.RedisCluster, reqLogger logr.Logger) ([]k8sredis.ClusterNodeInfo, error) {
+	nodes, err := k8sredis.GetClusterNodes(ctx, r.K8Client, instance, reqLogger)
+	if err != nil {
+		return nil, err
+	}
+
+	r.RecordUnreachablePods(instance, nodes)
+
+	if len(nodes) == 0 {
+		return nil, nil
+	}
+
+	plan := k8sredis.PlanCluster(nodes, int(instance.Spec.Shards))
+	if !plan.IsEmpty() {
+		if err := k8sredis.ApplyClusterPlan(ctx, r.K8Client, instance, nodes, plan, reqLogger); err != nil {
+			return nil, err
+		}
+		r.RecordClusterPlan(instance, plan)
+	}
+
+	seed := k8sredis.GetSeedNode(nodes)
+	if err := r.UpdateClusterStatus(ctx, instance, seed); err != nil {
+		return nil, err
+	}
+
+	if !plan.IsEmpty() || len(nodes) < instance.GetDeployedShards()*instance.GetShardSize() || seed.Info["cluster_state"] != "ok" {
+		return nil, nil
+	}
+	return nodes, nil
+}
+
+// records an event for every change made to the cluster
+func (r *RedisClusterReconciler) RecordClusterPlan(instance *v1.RedisCluster, plan *k8sredis.ClusterPlan) {
+	for _, meet := range plan.Meet {
+		r.Recorder.Eventf(instance, corev1.EventTypeNormal, EventReasonNodeJoined, "Added %s to the cluster", meet.To)
+	}
+	for _, forget := range plan.Forget {
+		r.Recorder.Eventf(instance, corev1.EventTypeWarning, EventReasonNodeForgotten, "Removed failed node %s %s from the cluster", forget.ID, forget.Hostname)
+	}
+	for _, assignment := range plan.AddSlots {
+		slots := 0
+		for _, slotRange := range assignment.Slots {
+			slots += slotRange.Count()
+		}
+		r.Recorder.Eventf(instance, corev1.EventTypeNormal, EventReasonSlotsAssigned, "Assigned %d slots to %s", slots, assignment.DNS)
+	}
+	for _, replicate := range plan.Replicate {
+		r.Recorder.Eventf(instance, corev1.EventTypeNormal, EventReasonDemoted, "Made %s a replica of %s", replicate.DNS, replicate.MasterDNS)
+	}
+}
+
+// counts the pods that could not be reached and records a warning for the ones reachable at the previous reconcile
+func (r *RedisClusterReconciler) RecordUnreachablePods(instance *v1.RedisCluster, nodes []k8sredis.ClusterNodeInfo) {
+	reachable := make(map[string]bool, len(nodes))
+	for _, node := range nodes {
+		reachable[node.DNS] = true
+	}
+
+	unreachable := []string{}
+	for shard := 0; shard < instance.GetDeployedShards(); shard++ {
+		for i := 0; i < instance.GetShardSize(); i++ {
+			if !reachable[instance.GetPodDNS(shard, i)] {
+				metrics.RedisConnectionErrors.WithLabelValues(metrics.KindCluster, instance.Namespace, instance.Name).Inc()
+				unreachable = append(unreachable, fmt.Sprintf("%s-%d", instance.GetShardName(shard), i))
+			}
+		}
+	}
+	for _, pod := range r.unreachable.update(client.ObjectKeyFromObject(instance), unreachable) {
+		r.Recorder.Eventf(instance, corev1.EventTypeWarning, EventReasonConnectionFailed, "Failed to connect to redis pod %s", pod)
+	}
+}
+
+func (r *RedisClusterReconciler) UpdateClusterStatus(ctx context.Context, instance *v1.RedisCluster, seed *k8sredis.ClusterNodeInfo) error {
+	status := instance.Status.DeepCopy()
+	status.State = seed.Info["cluster_state"]
+	status.SlotsAssigned, _ = strconv.Atoi(seed.Info["cluster_slots_assigned"])
+	status.SlotsOK, _ = strconv.Atoi(seed.Info["cluster_slots_ok"])
+	status.KnownNodes, _ = strconv.Atoi(seed.Info["cluster_known_nodes"])
+	if status.Shards < instance.Spec.Shards {
+		status.Shards = instance.Spec.Shards // statefulsets of new shards were created
+	}
+
+	condition := metav1.Condition{
+		Type:               v1.ConditionSlotsCovered,
+		Status:             metav1.ConditionTrue,
+		Reason:             "AllSlotsServed",
+		Message:            fmt.Sprintf("%d of %d slots are served", status.SlotsOK, v1.ClusterSlots),
+		ObservedGeneration: instance.Generation,
+	}
+	if status.SlotsOK < v1.ClusterSlots {
+		condition.Status = metav1.ConditionFalse
+		condition.Reason = "SlotsMissing"
+	}
+	meta.SetStatusCondition(&status.Conditions, condition)
+
+	if reflect.DeepEqual(status, &instance.Status) {
+		return nil
+	}
+	instance.Status = *status
+	return r.Client.Status().Update(ctx, instance)
+}
+
+// Reshard moves slots until every shard owns its share, then removes the shards beyond spec.shards. At most
+// slotsPerReconcile slots are moved per reconcile. Every slot is checkpointed in the status before it is moved,
+// so a migration interrupted by a restart of the operator is finished first
+func (r *RedisClusterReconciler) Reshard(ctx context.Context, instance *v1.RedisCluster, nodes []k8sredis.ClusterNodeInfo, reqLogger logr.Logger) error {
+	if nodes == nil {
+		return nil // not settled
+	}
+
+	migrations, err := k8sredis.PlanSlotMigrations(nodes, int(instance.Spec.Shards), instance.GetDeployedShards())
+	if err != nil {
+		return err
+	}
+
+	if checkpoint := instance.Status.Resharding; checkpoint != nil {
+		if migration := k8sredis.ResumeSlotMigration(nodes, checkpoint); migration != nil {
+			if migration.Target == nil {
+				reqLogger.Info("aborting slot migration, the target left the cluster", "slot", checkpoint.Slot)
+				if err := k8sredis.AbortSlotMigration(ctx, r.K8Client, instance, migration.Source, checkpoint.Slot); err != nil {
+					return err
+				}
+			} else {
+				resumed := []k8sredis.SlotMigration{*migration}
+				for _, planned := range migrations {
+					if planned.Slot != checkpoint.Slot {
+						resumed = append(resumed, planned)
+					}
+				}
+				migrations = resumed
+			}
+		}
+	}
+
+	if len(migrations) == 0 {
+		if instance.Status.Resharding != nil {
+			instance.Status.Resharding = nil
+			meta.SetStatusCondition(&instance.Status.Conditions, metav1.Condition{
+				Type:               v1.ConditionResharding,
+				Status:             metav1.ConditionFalse,
+				Reason:             "Balanced",
+				Message:            fmt.Sprintf("slots are balanced over %d shards", instance.Spec.Shards),
+				ObservedGeneration: instance.Generation,
+			})
+			if err := r.Client.Status().Update(ctx, instance); err != nil {
+				return err
+			}
+		}
+		return r.RemoveDrainedShards(ctx, instance, nodes, reqLogger)
+	}
+
+	moved, keys := 0, 0
+	for i, migration := range migrations {
+		if i == instance.Spec.Resharding.GetSlotsPerReconcile() {
+			break
+		}
+		if err := r.SetReshardingCheckpoint(ctx, instance, migration, len(migrations)-i-1); err != nil {
+			return err
+		}
+
+		migrated, err := k8sredis.MigrateSlot(ctx, r.K8Client, instance, nodes, migration, reqLogger)
+		keys += migrated
+		if err != nil {
+			return err
+		}
+		moved++
+	}
+
+	reqLogger.Info("migrated slots", "slots", moved, "keys", keys, "pending", len(migrations)-moved)
+	r.Recorder.Eventf(instance, corev1.EventTypeNormal, EventReasonSlotsMigrated, "Migrated %d slots holding %d keys, %d slots left", moved, keys, len(migrations)-moved)
+	return nil
+}
+
+// records the slot about to be migrated
+func (r *RedisClusterReconciler) SetReshardingCheckpoint(ctx context.Context, instance *v1.RedisCluster, migration k8sredis.SlotMigration, pending int) error {
+	instance.Status.Resharding = &v1.RedisClusterReshardingStatus{
+		Slot:         migration.Slot,
+		SourceShard:  migration.SourceShard,
+		SourceID:     migration.Source.Myself.ID,
+		TargetShard:  migration.TargetShard,
+		TargetID:     migration.Target.Myself.ID,
+		PendingSlots: pending,
+	}
+	meta.SetStatusCondition(&instance.Status.Conditions, metav1.Condition{
+		Type:               v1.ConditionResharding,
+		Status:             metav1.ConditionTrue,
+		Reason:             "MigratingSlots",
+		Message:            fmt.Sprintf("migrating slot %d from shard %d to shard %d, %d slots left", migration.Slot, migration.SourceShard, migration.TargetShard, pending),
+		ObservedGeneration: instance.Generation,
+	})
+	return r.Client.Status().Update(ctx, instance)
+}
+
+// RemoveDrainedShards deletes the statefulsets of the shards beyond spec.shards once none of their nodes owns a
+// slot. The cluster forgets their nodes after they failed
+func (r *RedisClusterReconciler) RemoveDrainedShards(ctx context.Context, instance *v1.RedisCluster, nodes []k8sredis.ClusterNodeInfo, reqLogger logr.Logger) error {
+	if instance.Status.Shards <= instance.Spec.Shards {
+		return nil
+	}
+
+	for _, node := range nodes {
+		if node.Shard >= int(instance.Spec.Shards) && node.Myself.SlotCount() > 0 {
+			return nil // still draining
+		}
+	}
+
+	for shard := int(instance.Spec.Shards); shard < int(instance.Status.Shards); shard++ {
+		name := instance.GetShardName(shard)
+		if err := r.K8Client.AppsV1().StatefulSets(instance.Namespace).Delete(ctx, name, metav1.DeleteOptions{}); err != nil {
+			if !apierrors.IsNotFound(err) {
+				return err
+			}
+			continue
+		}
+		reqLogger.Info("Deleted drained shard", "shard", shard)
+		r.Recorder.Eventf(instance, corev1.EventTypeNormal, EventReasonShardRemoved, "Removed drained shard %s", name)
+	}
+
+	instance.Status.Shards = instance.Spec.Shards
+	return r.Client.Status().Update(ctx, instance)
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *RedisClusterReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	b := ctrl.NewControllerManagedBy(mgr).
+		For(&v1.RedisCluster{}).
+		Owns(&appsv1.StatefulSet{})
+	return r.Namespaces.watchNamespaces(b, &v1.RedisClusterList{}).Complete(r)
+}
@@ -0,0 +1,103 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	redisv1 "redis.operator/api/v1"
+)
+
+var _ = Describe("RedisCluster Controller", func() {
+	Context("When reconciling a resource", func() {
+		const resourceName = "test-resource"
+
+		ctx := context.Background()
+
+		typeNamespacedName := types.NamespacedName{
+			Name:      resourceName,
+			Namespace: "default", // TODO(user):Modify as needed
+		}
+		rediscluster := &redisv1.RedisCluster{}
+
+		BeforeEach(func() {
+			By("creating the custom resource for the Kind RedisCluster")
+			err := k8sClient.Get(ctx, typeNamespacedName, rediscluster)
+			if err != nil && errors.IsNotFound(err) {
+				resource := &redisv1.RedisCluster{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      resourceName,
+						Namespace: "default",
+					},
+					Spec: redisv1.RedisClusterSpec{
+						Shards: 3,
+					},
+				}
+				Expect(k8sClient.Create(ctx, resource)).To(Succeed())
+			}
+		})
+
+		AfterEach(func() {
+			// TODO(user): Cleanup logic after each test, like removing the resource instance.
+			resource := &redisv1.RedisCluster{}
+			err := k8sClient.Get(ctx, typeNamespacedName, resource)
+			Expect(err).NotTo(HaveOccurred())
+
+			By("Cleanup the specific resource instance RedisCluster")
+			Expect(k8sClient.Delete(ctx, resource)).To(Succeed())
+		})
+		It("should successfully reconcile the resource", func() {
+			By("Reconciling the created resource")
+			controllerReconciler := &RedisClusterReconciler{
+				Client:    k8sClient,
+				K8Client:  k8sClientset,
+				Dk8Client: dynamicClient,
+				Scheme:    k8sClient.Scheme(),
+				Recorder:  record.NewFakeRecorder(100),
+			}
+
+			_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{
+				NamespacedName: typeNamespacedName,
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			By("creating the services, the configmap and a statefulset per shard")
+			instance := &redisv1.RedisCluster{}
+			Expect(k8sClient.Get(ctx, typeNamespacedName, instance)).To(Succeed())
+			for _, name := range []string{instance.GetHeadlessServiceName(), instance.GetServiceName()} {
+				Expect(k8sClient.Get(ctx, types.NamespacedName{Name: name, Namespace: "default"}, &corev1.Service{})).To(Succeed())
+			}
+			Expect(k8sClient.Get(ctx, types.NamespacedName{Name: instance.GetConfigName(), Namespace: "default"}, &corev1.ConfigMap{})).To(Succeed())
+			for shard := 0; shard < 3; shard++ {
+				statefulSet := &appsv1.StatefulSet{}
+				Expect(k8sClient.Get(ctx, types.NamespacedName{Name: instance.GetShardName(shard), Namespace: "default"}, statefulSet)).To(Succeed())
+				Expect(*statefulSet.Spec.Replicas).To(Equal(int32(instance.GetShardSize())))
+			}
+		})
+	})
+})
@@ -19,27 +19,41 @@ package controller
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"reflect"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/go-logr/logr"
 	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/record"
 	v1 "redis.operator/api/v1"
+	"redis.operator/pkg/config"
 	"redis.operator/pkg/kube/configmap"
+	"redis.operator/pkg/kube/custom"
+	"redis.operator/pkg/kube/dns"
+	"redis.operator/pkg/metrics"
 	k8sredis "redis.operator/pkg/redis"
 	"redis.operator/pkg/redisreplication"
 	"redis.operator/pkg/util/result"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 )
 
 // RedisReplicationReconciler reconciles a RedisReplication object
@@ -47,8 +61,15 @@ type RedisReplicationReconciler struct {
 	client.Client
 	K8Client  kubernetes.Interface
 	Dk8Client dynamic.Interface
+	Redis     k8sredis.RedisTopologyClient
 	Scheme    *runtime.Scheme
 	Log       logr.Logger
+	Recorder  record.EventRecorder
+
+	Namespaces *NamespaceSelector // reconciles every watched namespace when nil
+
+	failoverStart sync.Map // time a missing or duplicate master was first observed, keyed by instance
+	unreachable   unreachablePods
 }
 
 func (r *RedisReplicationReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
@@ -67,13 +88,27 @@ func (r *RedisReplicationReconciler) Reconcile(ctx context.Context, req ctrl.Req
 		if err = r.HandleReplicationFinalizer(ctx, instance, v1.RedisReplicationFinalizer); err != nil {
 			return result.RetryWithError(err, reqLogger, "Failed to handle finalizer")
 		}
+		r.failoverStart.Delete(req.NamespacedName)
+		r.unreachable.forget(req.NamespacedName)
+		metrics.DeleteInstanceMetrics(metrics.KindReplication, instance.Namespace, instance.Name)
+		k8sredis.InvalidateClients(instance.GetHeadlessServiceName(), instance.Namespace)
 		return result.Ok()
 	}
 
+	// resources of namespaces no longer selected still release their finalizer above
+	selected, err := r.Namespaces.Matches(ctx, instance.Namespace)
+	if err != nil {
+		return result.RetryWithError(err, reqLogger, "Failed to get namespace")
+	}
+	if !selected {
+		return result.ReconciledWithMessage(reqLogger, "Namespace not selected by the operator. Skipping")
+	}
+
 	if err = r.CreateReplicationFinalizer(ctx, instance, r.Client, v1.RedisReplicationFinalizer); err != nil {
 		return result.RetryWithError(err, reqLogger, "Failed to create finalizer")
 	}
 
+	start := time.Now()
 	if err = r.CreateOrUpdateHeadlessService(ctx, instance, reqLogger); err != nil {
 		return result.RetryWithError(err, reqLogger, "Failed to create service for redis instance")
 	}
@@ -81,20 +116,53 @@ func (r *RedisReplicationReconciler) Reconcile(ctx context.Context, req ctrl.Req
 	if err = r.CreateOrUpdateService(ctx, instance, reqLogger); err != nil {
 		return result.RetryWithError(err, reqLogger, "Failed to create service for redis instance")
 	}
+	metrics.ObserveStep(metrics.KindReplication, instance.Namespace, instance.Name, metrics.StepService, start)
 
+	start = time.Now()
 	if err = r.CreateOrUpdateConfigMap(ctx, instance, reqLogger); err != nil {
 		return result.RetryWithError(err, reqLogger, "Failed to create configmap for redis instance")
 	}
+	metrics.ObserveStep(metrics.KindReplication, instance.Namespace, instance.Name, metrics.StepConfigMap, start)
+
+	start = time.Now()
+	if err = r.CreateOrUpdateCertificate(ctx, instance, reqLogger); err != nil {
+		return result.RetryWithError(err, reqLogger, "Failed to create certificate for redis instance")
+	}
+
+	if err = r.CreateOrUpdateAuthSecret(ctx, instance, reqLogger); err != nil {
+		return result.RetryWithError(err, reqLogger, "Failed to create auth secret for redis instance")
+	}
 
 	if err = r.CreateOrUpdateStateful(ctx, instance, reqLogger); err != nil {
 		return result.RetryWithError(err, reqLogger, "Failed to create statefulset for redis instance")
 	}
+	metrics.ObserveStep(metrics.KindReplication, instance.Namespace, instance.Name, metrics.StepStatefulSet, start)
+
+	start = time.Now()
+	if err = r.CreateOrUpdateMonitoring(ctx, instance, reqLogger); err != nil {
+		return result.RetryWithError(err, reqLogger, "Failed to create monitoring resources for redis instance")
+	}
+	metrics.ObserveStep(metrics.KindReplication, instance.Namespace, instance.Name, metrics.StepMonitoring, start)
+
+	if err = r.ReloadTLSCertificates(ctx, instance, reqLogger); err != nil {
+		return result.RetryWithError(err, reqLogger, "Failed to reload tls certificates")
+	}
+
+	if instance.IsStandalone() {
+		return result.RequeueAfter(config.Get().RequeueInterval.Duration)
+	}
+
+	start = time.Now()
+	if err = r.UpdateReplicaPriority(ctx, instance, reqLogger); err != nil {
+		return result.RetryWithError(err, reqLogger, "Failed to update replica priority")
+	}
 
 	if err = r.UpdateRedisMaster(ctx, instance, reqLogger); err != nil {
 		return result.RetryWithError(err, reqLogger, "Failed to update redis master")
 	}
+	metrics.ObserveStep(metrics.KindReplication, instance.Namespace, instance.Name, metrics.StepMaster, start)
 
-	return result.RequeueAfter(1 * time.Second)
+	return result.RequeueAfter(config.Get().RequeueInterval.Duration)
 }
 
 func (r *RedisReplicationReconciler) CreateReplicationFinalizer(ctx context.Context, instance *v1.RedisReplication, client client.Client, finalizer string) error {
@@ -113,20 +181,103 @@ func (r *RedisReplicationReconciler) HandleReplicationFinalizer(ctx context.Cont
 	return nil
 }
 
-func (r *RedisReplicationReconciler) UpdateReplicationLabels(ctx context.Context, instance *v1.RedisReplication, reqLogger logr.Logger) error {
-
+func (r *RedisReplicationReconciler) ListReplicationPods(ctx context.Context, instance *v1.RedisReplication) (*corev1.PodList, error) {
 	labels := redisreplication.GetReplicationServiceLabels(instance)
 	selector := make([]string, 0, len(labels))
 	for key, value := range labels {
 		selector = append(selector, fmt.Sprintf("%s=%s", key, value))
 	}
 
-	podList, err := r.K8Client.CoreV1().Pods(instance.Namespace).List(ctx, metav1.ListOptions{LabelSelector: strings.Join(selector, ",")})
+	return r.K8Client.CoreV1().Pods(instance.Namespace).List(ctx, metav1.ListOptions{LabelSelector: strings.Join(selector, ",")})
+}
+
+// returns the zone of every scheduled pod keyed by the pod index. The zone is read from the topology label of the pod's node
+func (r *RedisReplicationReconciler) GetReplicaZones(ctx context.Context, instance *v1.RedisReplication) (map[int]string, error) {
+
+	podList, err := r.ListReplicationPods(ctx, instance)
+	if err != nil {
+		return nil, err
+	}
+
+	nodeZones := map[string]string{}
+	zones := map[int]string{}
+	for _, pod := range podList.Items {
+		indexStr, ok := pod.Labels["apps.kubernetes.io/pod-index"]
+		if !ok || pod.Spec.NodeName == "" {
+			continue // not scheduled yet
+		}
+
+		index, err := strconv.Atoi(indexStr)
+		if err != nil {
+			return nil, err
+		}
+
+		zone, ok := nodeZones[pod.Spec.NodeName]
+		if !ok {
+			node, err := r.K8Client.CoreV1().Nodes().Get(ctx, pod.Spec.NodeName, metav1.GetOptions{})
+			if err != nil {
+				return nil, err
+			}
+			zone = node.Labels[instance.GetZoneTopologyKey()]
+			nodeZones[pod.Spec.NodeName] = zone
+		}
+		zones[index] = zone
+	}
+	return zones, nil
+}
+
+// returns the dns name of the pod announcing address. Sentinels report the ip of the master when the pods announce
+// their ip, the operator addresses pods by dns name
+func (r *RedisReplicationReconciler) ResolvePodDNS(ctx context.Context, instance *v1.RedisReplication, address string) (string, error) {
+	if address == "" || !dns.IsAnnouncingPodIP() {
+		return address, nil
+	}
+
+	podList, err := r.ListReplicationPods(ctx, instance)
+	if err != nil {
+		return "", err
+	}
+
+	for _, pod := range podList.Items {
+		if pod.Status.PodIP != address {
+			continue
+		}
+		index, err := strconv.Atoi(pod.Labels["apps.kubernetes.io/pod-index"])
+		if err != nil {
+			return "", err
+		}
+		return instance.GetPodDNS(index), nil
+	}
+	return "", fmt.Errorf("no pod of %s has the ip %s", instance.Name, address)
+}
+
+func (r *RedisReplicationReconciler) UpdateReplicaPriority(ctx context.Context, instance *v1.RedisReplication, reqLogger logr.Logger) error {
+
+	if instance.Spec.ZoneConfig == nil {
+		return nil
+	}
+
+	zones, err := r.GetReplicaZones(ctx, instance)
 	if err != nil {
 		return err
 	}
 
-	replicaInfo, err := k8sredis.GetReplicaInfo(ctx, r.K8Client, instance, reqLogger)
+	priorities := make(map[int]int, len(zones))
+	for index, zone := range zones {
+		priorities[index] = instance.GetReplicaPriority(zone)
+	}
+
+	return r.Redis.SetReplicaPriority(ctx, instance, priorities, reqLogger)
+}
+
+func (r *RedisReplicationReconciler) UpdateReplicationLabels(ctx context.Context, instance *v1.RedisReplication, reqLogger logr.Logger) error {
+
+	podList, err := r.ListReplicationPods(ctx, instance)
+	if err != nil {
+		return err
+	}
+
+	replicaInfo, err := r.Redis.GetReplicaInfo(ctx, instance, reqLogger)
 	if err != nil {
 		return err
 	}
@@ -144,7 +295,7 @@ func (r *RedisReplicationReconciler) UpdateReplicationLabels(ctx context.Context
 
 		for _, info := range replicaInfo {
 			if info.PodIndex == index {
-				if role, ok := info.Info["role"]; ok {
+				if role := info.Redis.Replication.Role; role != "" {
 					if currentLabel, ok := pod.Labels["redis.operator/redis-role"]; ok {
 						if currentLabel == role {
 							break
@@ -172,31 +323,54 @@ func (r *RedisReplicationReconciler) CreateOrUpdateConfigMap(ctx context.Context
 		SetData(instance.Spec.RedisConfig.Data). // key was: redis.conf
 		BuildWithOwner(instance.GetOwnerReference())
 
-	_, err := r.K8Client.CoreV1().ConfigMaps(instance.Namespace).Get(ctx, instance.GetConfigName(), metav1.GetOptions{})
+	if instance.Spec.TLSConfig != nil {
+		if err := k8sredis.UpdateConfigMapTLSSettings(configMap, "redis.conf", k8sredis.ServerTLSOptions{
+			Settings:        instance.Spec.TLSConfig,
+			Mode:            instance.Spec.TLSConfig.GetMode(),
+			ReplicationMode: instance.Spec.TLSConfig.GetMode(),
+			PlaintextPort:   instance.GetPlaintextPort(),
+			TLSPort:         instance.GetRedisPort(),
+		}); err != nil {
+			return err
+		}
+	}
+
+	currentConfigMap, err := r.K8Client.CoreV1().ConfigMaps(instance.Namespace).Get(ctx, instance.GetConfigName(), metav1.GetOptions{})
 	if err != nil {
 		if apierrors.IsNotFound(err) {
 			reqLogger.Info("Creating configmap")
-			_, err := r.K8Client.CoreV1().ConfigMaps(instance.Namespace).Create(ctx, configMap, metav1.CreateOptions{})
-			return err
+			if _, err := r.K8Client.CoreV1().ConfigMaps(instance.Namespace).Create(ctx, configMap, metav1.CreateOptions{}); err != nil {
+				return err
+			}
+			r.Recorder.Eventf(instance, corev1.EventTypeNormal, EventReasonCreated, "Created configmap %s", configMap.Name)
+			return nil
 		}
 		return err
 	}
 
-	_, err = r.K8Client.CoreV1().ConfigMaps(instance.Namespace).Update(ctx, configMap, metav1.UpdateOptions{})
-	return err
+	if _, err = r.K8Client.CoreV1().ConfigMaps(instance.Namespace).Update(ctx, configMap, metav1.UpdateOptions{}); err != nil {
+		return err
+	}
+	if !reflect.DeepEqual(currentConfigMap.Data, configMap.Data) {
+		r.Recorder.Eventf(instance, corev1.EventTypeNormal, EventReasonConfigUpdated, "Updated configmap %s", configMap.Name)
+	}
+	return nil
 }
 
+// GetRedisSentinelInstance returns the sentinel of instance. A sentinel of another namespace is only returned when
+// its allowedNamespaces contain the namespace of instance
 func (r *RedisReplicationReconciler) GetRedisSentinelInstance(ctx context.Context, instance *v1.RedisReplication) (*v1.RedisSentinel, error) {
 
 	if instance.Spec.RedisSentinelConfig == nil {
 		return nil, fmt.Errorf("redisSentinelName is not set")
 	}
 
+	reference := instance.GetSentinelReference()
 	customObject, err := r.Dk8Client.Resource(schema.GroupVersionResource{
 		Group:    "redis.redis.operator",
 		Version:  "v1",
 		Resource: "redissentinels",
-	}).Namespace(instance.Namespace).Get(ctx, instance.Spec.RedisSentinelConfig.RedisSentinelName, metav1.GetOptions{})
+	}).Namespace(reference.Namespace).Get(ctx, reference.Name, metav1.GetOptions{})
 	if err != nil {
 		return nil, err
 	}
@@ -211,12 +385,29 @@ func (r *RedisReplicationReconciler) GetRedisSentinelInstance(ctx context.Contex
 		return nil, err
 	}
 
+	if !redisSentinel.AllowsNamespace(instance.Namespace) {
+		return nil, fmt.Errorf("%w: sentinel %s does not allow replications of namespace %s", errReferenceDenied, reference, instance.Namespace)
+	}
 	return redisSentinel, nil
 }
 
+// GetSentinelMasters returns the master of instance seen by every sentinel. The sentinels use the tls settings of
+// the first replication they monitor
+func (r *RedisReplicationReconciler) GetSentinelMasters(ctx context.Context, sentinelInstance *v1.RedisSentinel, instance *v1.RedisReplication, monitor *v1.RedisSentinelMonitor) ([]k8sredis.SentinelMaster, error) {
+	tlsInstance := instance
+	if reference := sentinelInstance.GetPrimaryReplication(); reference != client.ObjectKeyFromObject(instance) {
+		primaryInstance, err := getRedisReplication(ctx, r.Dk8Client, reference.Namespace, reference.Name)
+		if err != nil {
+			return nil, err
+		}
+		tlsInstance = primaryInstance
+	}
+	return r.Redis.GetSentinelMasters(ctx, sentinelInstance, tlsInstance, monitor.MasterName)
+}
+
 // go through the sentinels masters and find the 'agreed master' using the supplied quorum. We don't use any
 // sentinels considered down
-func GetSentinelMasterCandidate(sentinelMasters []k8sredis.RedisCommandInfo, sentinelInstance *v1.RedisSentinel) (string, error) {
+func GetSentinelMasterCandidate(sentinelMasters []k8sredis.SentinelMaster, quorum int) (string, error) {
 
 	candidates := make([]struct {
 		DNS    string
@@ -226,11 +417,11 @@ func GetSentinelMasterCandidate(sentinelMasters []k8sredis.RedisCommandInfo, sen
 	//candidates := []RedisCandidates{}
 	for _, sentinelMaster := range sentinelMasters {
 
-		if _, ok := sentinelMaster.Info["s-down-time"]; ok { // don't use down sentinels
+		if sentinelMaster.IsDown() { // don't use down sentinels
 			continue
 		}
 
-		if ip, ok := sentinelMaster.Info["ip"]; ok {
+		if ip := sentinelMaster.IP; ip != "" {
 
 			found := false
 			for i, candidate := range candidates {
@@ -254,7 +445,7 @@ func GetSentinelMasterCandidate(sentinelMasters []k8sredis.RedisCommandInfo, sen
 	}
 
 	for _, candidate := range candidates {
-		if candidate.Agreed >= sentinelInstance.Spec.RedisSentinelQuorum {
+		if candidate.Agreed >= quorum {
 			return candidate.DNS, nil
 		}
 	}
@@ -262,95 +453,91 @@ func GetSentinelMasterCandidate(sentinelMasters []k8sredis.RedisCommandInfo, sen
 	return "", nil
 }
 
-// returns the master with the highest number of slaves.
-func GetHighestConnectedSlaves(replicationInfo []k8sredis.RedisCommandInfo) (string, error) {
-	realMaster := ""
-	slaveHigh := 0
-	for _, info := range replicationInfo {
-		if slaves, ok := info.Info["connected_slaves"]; ok {
-			slaveCount, err := strconv.Atoi(slaves)
-			if err != nil {
-				return "", err
-			}
-			if slaveHigh < slaveCount {
-				slaveHigh = slaveCount
-				realMaster = info.DNS
-			} else if slaveHigh == slaveCount {
-				realMaster = "" // don't demote unless certain.
-			}
-		}
+// sets the zone of every instance using the pod index
+func (r *RedisReplicationReconciler) SetReplicationZones(ctx context.Context, instance *v1.RedisReplication, replicationInfo []k8sredis.RedisCommandInfo) error {
+	if instance.Spec.ZoneConfig == nil {
+		return nil
 	}
-	return realMaster, nil
+
+	zones, err := r.GetReplicaZones(ctx, instance)
+	if err != nil {
+		return err
+	}
+
+	for i := range replicationInfo {
+		replicationInfo[i].Zone = zones[replicationInfo[i].PodIndex]
+	}
+	return nil
 }
 
 func (r *RedisReplicationReconciler) UpdateRedisMaster(ctx context.Context, instance *v1.RedisReplication, reqLogger logr.Logger) error {
 
-	replicationInfo, err := k8sredis.GetReplicaInfo(ctx, r.K8Client, instance, reqLogger)
+	replicationInfo, err := r.Redis.GetReplicaInfo(ctx, instance, reqLogger)
 	if err != nil {
 		return err
 	}
 
+	r.RecordUnreachablePods(instance, replicationInfo)
+
 	if len(replicationInfo) == 0 {
 		return nil
 	}
 
+	if err = r.SetReplicationZones(ctx, instance, replicationInfo); err != nil {
+		return err
+	}
+
 	masters := 0
-	slaves := 0
 	for _, info := range replicationInfo {
-		if role, ok := info.Info["role"]; ok {
-			if role == "master" {
-				masters++
-			} else if role == "slave" {
-				slaves++
-			}
+		if info.Redis.Replication.Role == "master" {
+			masters++
 		}
 	}
 
+	r.UpdateReplicaLag(instance, replicationInfo)
+
+	key := types.NamespacedName{Namespace: instance.Namespace, Name: instance.Name}
 	if masters == 1 {
-		return nil
+		if start, ok := r.failoverStart.LoadAndDelete(key); ok {
+			metrics.FailoverDuration.WithLabelValues(metrics.KindReplication, instance.Namespace, instance.Name).Observe(time.Since(start.(time.Time)).Seconds())
+		}
+		return r.UpdateMasterStatus(ctx, instance, replicationInfo)
 	}
+	r.failoverStart.LoadOrStore(key, time.Now())
 
-	if instance.Spec.RedisSentinelConfig == nil {
-		if slaves == 0 {
-			realMaster := replicationInfo[0].DNS
-			reqLogger.Info("running without a sentinel. promoting first instance", "master", realMaster)
-			return k8sredis.SetReplicationMaster(ctx, r.K8Client, instance, realMaster, reqLogger)
-		}
-		realMaster, err := GetHighestConnectedSlaves(replicationInfo)
-		if err != nil {
+	if masters > 1 && config.Get().IsEnabled(config.FeatureSplitBrainResolution) {
+		resolved, err := r.ResolveSplitBrain(ctx, instance, replicationInfo, reqLogger)
+		if err != nil || resolved {
 			return err
 		}
-		if realMaster == "" {
-			reqLogger.Info("uncertainity about current master. cannot promote an instance")
-			return nil // cannot promote unless certain
-		}
-		reqLogger.Info("updating redis master instances", "master", realMaster)
-		return k8sredis.SetReplicationMaster(ctx, r.K8Client, instance, realMaster, reqLogger)
+	}
+
+	if instance.Spec.RedisSentinelConfig == nil {
+		reqLogger.Info("running without a sentinel. electing a master")
+		return r.ElectRedisMaster(ctx, instance, replicationInfo, reqLogger)
 	}
 
 	sentinelInstance, err := r.GetRedisSentinelInstance(ctx, instance)
 	if err != nil {
-		if !apierrors.IsNotFound(err) {
-			return err
+		if errors.Is(err, errReferenceDenied) {
+			reqLogger.Info("sentinel reference denied. electing a master", "reason", err.Error())
+			r.Recorder.Event(instance, corev1.EventTypeWarning, EventReasonReferenceDenied, err.Error())
+			return r.ElectRedisMaster(ctx, instance, replicationInfo, reqLogger)
 		}
-		if slaves == 0 {
-			realMaster := replicationInfo[0].DNS
-			reqLogger.Info("no sentinel instance found. promoting first instance", "master", realMaster)
-			return k8sredis.SetReplicationMaster(ctx, r.K8Client, instance, realMaster, reqLogger)
-		}
-		realMaster, err := GetHighestConnectedSlaves(replicationInfo)
-		if err != nil {
+		if !apierrors.IsNotFound(err) {
 			return err
 		}
-		if realMaster == "" {
-			reqLogger.Info("uncertainity about current master", "master", realMaster)
-			return nil // wait for the sentinel. Do not promote unless certain
-		}
-		reqLogger.Info("updating redis master instances", "master", realMaster)
-		return k8sredis.SetReplicationMaster(ctx, r.K8Client, instance, realMaster, reqLogger)
+		reqLogger.Info("no sentinel instance found. electing a master")
+		return r.ElectRedisMaster(ctx, instance, replicationInfo, reqLogger)
+	}
+
+	monitor := sentinelInstance.GetMonitor(client.ObjectKeyFromObject(instance))
+	if monitor == nil {
+		reqLogger.Info("not monitored by the sentinels. electing a master", "sentinel", sentinelInstance.Name)
+		return r.ElectRedisMaster(ctx, instance, replicationInfo, reqLogger)
 	}
 
-	sentinelMasters, err := k8sredis.GetSentinelMasters(ctx, r.K8Client, sentinelInstance, instance)
+	sentinelMasters, err := r.GetSentinelMasters(ctx, sentinelInstance, instance, monitor)
 	if err != nil {
 		return err
 	}
@@ -359,20 +546,178 @@ func (r *RedisReplicationReconciler) UpdateRedisMaster(ctx context.Context, inst
 		return fmt.Errorf("failed to query sentinel masters")
 	}
 
-	candidate, err := GetSentinelMasterCandidate(sentinelMasters, sentinelInstance)
+	candidate, err := GetSentinelMasterCandidate(sentinelMasters, monitor.Quorum)
 	if err != nil {
 		return err
 	}
+	if candidate, err = r.ResolvePodDNS(ctx, instance, candidate); err != nil {
+		return err
+	}
 
 	if candidate != "" {
+		// sentinels only see the instances they can reach, they may agree on a replica missing writes
+		if err := k8sredis.CanPromote(replicationInfo, candidate); err != nil {
+			reqLogger.Info("refusing to promote the master agreed by the sentinels", "master", candidate, "reason", err.Error())
+			r.Recorder.Eventf(instance, corev1.EventTypeWarning, EventReasonElectionRefused, "Refused to promote %s: %v", candidate, err)
+			return nil
+		}
 		reqLogger.Info("sentinels agreed on a new master. updating instances...", "master", candidate)
-		return k8sredis.SetReplicationMaster(ctx, r.K8Client, instance, candidate, reqLogger)
+		return r.SetReplicationMaster(ctx, instance, replicationInfo, candidate, reqLogger)
 	}
 
 	reqLogger.Info("Sentinels have not agreed on a new master")
+	r.Recorder.Eventf(instance, corev1.EventTypeWarning, EventReasonQuorumLost, "Sentinels of %s have not agreed on a master", sentinelInstance.Name)
+	return nil
+}
+
+// records the master in the status once it is the only one
+func (r *RedisReplicationReconciler) UpdateMasterStatus(ctx context.Context, instance *v1.RedisReplication, replicationInfo []k8sredis.RedisCommandInfo) error {
+	for _, info := range replicationInfo {
+		if info.Redis.Replication.Role == "master" && info.DNS != instance.Status.MasterDns {
+			instance.Status.MasterDns = info.DNS
+			return r.Client.Status().Update(ctx, instance)
+		}
+	}
 	return nil
 }
 
+// fences and demotes every master but one when several of them accepted writes. Returns false when at most one
+// master holds writes, the remaining empty masters are handled by the regular election
+func (r *RedisReplicationReconciler) ResolveSplitBrain(ctx context.Context, instance *v1.RedisReplication, replicationInfo []k8sredis.RedisCommandInfo, reqLogger logr.Logger) (bool, error) {
+	preferredMaster := ""
+	if instance.Spec.RedisSentinelConfig != nil {
+		if sentinelInstance, err := r.GetRedisSentinelInstance(ctx, instance); err == nil {
+			if monitor := sentinelInstance.GetMonitor(client.ObjectKeyFromObject(instance)); monitor != nil {
+				if sentinelMasters, err := r.GetSentinelMasters(ctx, sentinelInstance, instance, monitor); err == nil {
+					if candidate, err := GetSentinelMasterCandidate(sentinelMasters, monitor.Quorum); err == nil {
+						preferredMaster, _ = r.ResolvePodDNS(ctx, instance, candidate)
+					}
+				}
+			}
+		}
+	}
+
+	splitBrain, err := k8sredis.DetectSplitBrain(replicationInfo, preferredMaster, instance.GetPreferredZone())
+	if err != nil || splitBrain == nil {
+		return false, err
+	}
+
+	reqLogger.Info("split-brain detected", "master", splitBrain.Master.Info.DNS, "stale", len(splitBrain.Stale))
+
+	// stale masters must not accept writes between the decision and their demotion
+	fences, err := r.Redis.FenceStaleMasters(ctx, instance, splitBrain, reqLogger)
+	if err != nil {
+		if unfenceErr := r.Redis.UnfenceMasters(ctx, instance, fences, reqLogger); unfenceErr != nil {
+			reqLogger.Error(unfenceErr, "failed to unfence masters")
+		}
+		return true, r.SetSplitBrainCondition(ctx, instance, metav1.ConditionTrue, "FencingFailed", err.Error())
+	}
+
+	if err := r.SetReplicationMaster(ctx, instance, replicationInfo, splitBrain.Master.Info.DNS, reqLogger); err != nil {
+		return true, err
+	}
+
+	if err := r.Redis.UnfenceMasters(ctx, instance, fences, reqLogger); err != nil {
+		return true, err
+	}
+
+	r.Recorder.Eventf(instance, corev1.EventTypeWarning, EventReasonSplitBrain, "Resolved split-brain: %s", splitBrain)
+	return true, r.SetSplitBrainCondition(ctx, instance, metav1.ConditionFalse, "StaleMastersDemoted", splitBrain.String())
+}
+
+func (r *RedisReplicationReconciler) SetSplitBrainCondition(ctx context.Context, instance *v1.RedisReplication, status metav1.ConditionStatus, reason string, message string) error {
+	if !meta.SetStatusCondition(&instance.Status.Conditions, metav1.Condition{
+		Type:               v1.ConditionSplitBrain,
+		Status:             status,
+		Reason:             reason,
+		Message:            message,
+		ObservedGeneration: instance.Generation,
+	}) {
+		return nil
+	}
+	return r.Client.Status().Update(ctx, instance)
+}
+
+// promotes the instance holding the most recent writes. Nothing is promoted when every choice would discard writes
+func (r *RedisReplicationReconciler) ElectRedisMaster(ctx context.Context, instance *v1.RedisReplication, replicationInfo []k8sredis.RedisCommandInfo, reqLogger logr.Logger) error {
+	election, err := k8sredis.ElectMaster(replicationInfo, instance.GetPreferredZone())
+	if err != nil {
+		return err
+	}
+
+	if election.Master == "" {
+		reqLogger.Info("cannot promote an instance", "reason", election.Reason)
+		r.Recorder.Eventf(instance, corev1.EventTypeWarning, EventReasonElectionRefused, "Cannot promote an instance: %s", election.Reason)
+		return nil // cannot promote unless certain
+	}
+
+	reqLogger.Info("updating redis master instances", "master", election.Master)
+	return r.SetReplicationMaster(ctx, instance, replicationInfo, election.Master, reqLogger)
+}
+
+// promotes masterDNS and records an event for every instance whose role changes
+func (r *RedisReplicationReconciler) SetReplicationMaster(ctx context.Context, instance *v1.RedisReplication, replicationInfo []k8sredis.RedisCommandInfo, masterDNS string, reqLogger logr.Logger) error {
+
+	if err := r.Redis.SetReplicationMaster(ctx, instance, masterDNS, reqLogger); err != nil {
+		return err
+	}
+
+	for _, info := range replicationInfo {
+		role := info.Redis.Replication.Role
+		if info.DNS == masterDNS && role != "master" {
+			metrics.MasterChanges.WithLabelValues(metrics.KindReplication, instance.Namespace, instance.Name).Inc()
+			r.Recorder.Eventf(instance, corev1.EventTypeNormal, EventReasonPromoted, "Promoted %s to master", info.DNS)
+		} else if info.DNS != masterDNS && role == "master" {
+			r.Recorder.Eventf(instance, corev1.EventTypeNormal, EventReasonDemoted, "Demoted %s to replica of %s", info.DNS, masterDNS)
+		}
+	}
+	return nil
+}
+
+// counts the pods that could not be reached and records a warning for the ones reachable at the previous reconcile
+func (r *RedisReplicationReconciler) RecordUnreachablePods(instance *v1.RedisReplication, replicationInfo []k8sredis.RedisCommandInfo) {
+	reachable := make(map[int]bool, len(replicationInfo))
+	for _, info := range replicationInfo {
+		reachable[info.PodIndex] = true
+	}
+
+	unreachable := []string{}
+	for i := 0; i < instance.GetReplicas(); i++ {
+		if !reachable[i] {
+			metrics.RedisConnectionErrors.WithLabelValues(metrics.KindReplication, instance.Namespace, instance.Name).Inc()
+			unreachable = append(unreachable, fmt.Sprintf("%s-%d", instance.Name, i))
+		}
+	}
+	for _, pod := range r.unreachable.update(client.ObjectKeyFromObject(instance), unreachable) {
+		r.Recorder.Eventf(instance, corev1.EventTypeWarning, EventReasonConnectionFailed, "Failed to connect to redis pod %s", pod)
+	}
+}
+
+// sets the replication lag of every replica using the offset of the master
+func (r *RedisReplicationReconciler) UpdateReplicaLag(instance *v1.RedisReplication, replicationInfo []k8sredis.RedisCommandInfo) {
+	masterOffset := int64(-1)
+	for _, info := range replicationInfo {
+		replication := info.Redis.Replication
+		if replication.Role == "master" && replication.MasterOffset > masterOffset {
+			masterOffset = replication.MasterOffset
+		}
+	}
+	if masterOffset < 0 {
+		return
+	}
+
+	for _, info := range replicationInfo {
+		podName := fmt.Sprintf("%s-%d", instance.Name, info.PodIndex)
+		if info.Redis.Replication.Role == "master" {
+			metrics.ReplicaLag.WithLabelValues(metrics.KindReplication, instance.Namespace, instance.Name, podName).Set(0)
+			continue
+		}
+		if offset := info.Redis.Replication.ReplicaOffset; offset >= 0 {
+			metrics.ReplicaLag.WithLabelValues(metrics.KindReplication, instance.Namespace, instance.Name, podName).Set(float64(masterOffset - offset))
+		}
+	}
+}
+
 func (r *RedisReplicationReconciler) CreateOrUpdateStateful(ctx context.Context, instance *v1.RedisReplication, reqLogger logr.Logger) error {
 
 	initContainer, err := redisreplication.CreateContainer(instance)
@@ -392,8 +737,11 @@ func (r *RedisReplicationReconciler) CreateOrUpdateStateful(ctx context.Context,
 	if err != nil {
 		if apierrors.IsNotFound(err) {
 			reqLogger.Info("Creating statefulset")
-			_, err = r.K8Client.AppsV1().StatefulSets(instance.GetNamespace()).Create(ctx, statefulSet, metav1.CreateOptions{})
-			return err
+			if _, err = r.K8Client.AppsV1().StatefulSets(instance.GetNamespace()).Create(ctx, statefulSet, metav1.CreateOptions{}); err != nil {
+				return err
+			}
+			r.Recorder.Eventf(instance, corev1.EventTypeNormal, EventReasonCreated, "Created statefulset %s", statefulSet.Name)
+			return nil
 		}
 	}
 
@@ -409,8 +757,11 @@ func (r *RedisReplicationReconciler) CreateOrUpdateHeadlessService(ctx context.C
 	if err != nil {
 		if apierrors.IsNotFound(err) {
 			reqLogger.Info("Creating headless service")
-			_, err := r.K8Client.CoreV1().Services(instance.GetNamespace()).Create(ctx, &newService, metav1.CreateOptions{})
-			return err
+			if _, err := r.K8Client.CoreV1().Services(instance.GetNamespace()).Create(ctx, &newService, metav1.CreateOptions{}); err != nil {
+				return err
+			}
+			r.Recorder.Eventf(instance, corev1.EventTypeNormal, EventReasonCreated, "Created headless service %s", newService.Name)
+			return nil
 		}
 		return err
 	}
@@ -427,8 +778,11 @@ func (r *RedisReplicationReconciler) CreateOrUpdateService(ctx context.Context,
 	if err != nil {
 		if apierrors.IsNotFound(err) {
 			reqLogger.Info("Creating service")
-			_, err := r.K8Client.CoreV1().Services(instance.GetNamespace()).Create(ctx, &newService, metav1.CreateOptions{})
-			return err
+			if _, err := r.K8Client.CoreV1().Services(instance.GetNamespace()).Create(ctx, &newService, metav1.CreateOptions{}); err != nil {
+				return err
+			}
+			r.Recorder.Eventf(instance, corev1.EventTypeNormal, EventReasonCreated, "Created service %s", newService.Name)
+			return nil
 		}
 		return err
 	}
@@ -436,10 +790,159 @@ func (r *RedisReplicationReconciler) CreateOrUpdateService(ctx context.Context,
 	return err
 }
 
+// CreateOrUpdateMonitoring manages the ServiceMonitor and PrometheusRule of an exporter enabled instance.
+// Nothing is done when the Prometheus Operator CRDs are not installed
+func (r *RedisReplicationReconciler) CreateOrUpdateMonitoring(ctx context.Context, instance *v1.RedisReplication, reqLogger logr.Logger) error {
+
+	resources := []struct {
+		gvr     schema.GroupVersionResource
+		name    string
+		enabled bool
+		build   func(*v1.RedisReplication) *unstructured.Unstructured
+	}{
+		{custom.ServiceMonitorResource, instance.GetServiceMonitorName(), instance.Spec.Monitoring.IsServiceMonitorEnabled(), redisreplication.CreateServiceMonitor},
+		{custom.PrometheusRuleResource, instance.GetPrometheusRuleName(), instance.Spec.Monitoring.IsPrometheusRuleEnabled(), redisreplication.CreatePrometheusRule},
+	}
+
+	for _, resource := range resources {
+		available, err := custom.HasResource(r.K8Client.Discovery(), resource.gvr)
+		if err != nil {
+			return err
+		}
+		if !available {
+			continue
+		}
+
+		if !instance.IsExporterEnabled() || !resource.enabled {
+			deleted, err := custom.Delete(ctx, r.Dk8Client, resource.gvr, instance.Namespace, resource.name)
+			if err != nil {
+				return err
+			}
+			if deleted {
+				reqLogger.Info("Deleted monitoring resource", "resource", resource.gvr.Resource, "name", resource.name)
+			}
+			continue
+		}
+
+		created, err := custom.CreateOrUpdate(ctx, r.Dk8Client, resource.gvr, resource.build(instance))
+		if err != nil {
+			return err
+		}
+		if created {
+			reqLogger.Info("Created monitoring resource", "resource", resource.gvr.Resource, "name", resource.name)
+			r.Recorder.Eventf(instance, corev1.EventTypeNormal, EventReasonCreated, "Created %s %s", resource.gvr.Resource, resource.name)
+		}
+	}
+	return nil
+}
+
+// ReloadTLSCertificates makes every pod load the certificate again after the tls secret changed. The
+// resourceVersion of the secret is only recorded once all reachable pods serve the new certificate
+func (r *RedisReplicationReconciler) ReloadTLSCertificates(ctx context.Context, instance *v1.RedisReplication, reqLogger logr.Logger) error {
+	if instance.Spec.TLSConfig == nil {
+		return nil
+	}
+
+	secret, err := r.K8Client.CoreV1().Secrets(instance.Namespace).Get(ctx, instance.GetTLSSecretName(), metav1.GetOptions{})
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil // not issued yet
+		}
+		return err
+	}
+	if secret.ResourceVersion == instance.Status.TLSSecretVersion {
+		return nil
+	}
+
+	// pods started after the secret was created have already loaded it
+	if instance.Status.TLSSecretVersion != "" {
+		reloaded, err := r.Redis.ReloadReplicationCertificates(ctx, instance, reqLogger)
+		if err != nil {
+			return err
+		}
+		if !reloaded {
+			return nil
+		}
+		reqLogger.Info("Reloaded tls certificates", "secret", secret.Name)
+		r.Recorder.Eventf(instance, corev1.EventTypeNormal, EventReasonCertReloaded, "Reloaded certificate from secret %s", secret.Name)
+	}
+
+	instance.Status.TLSSecretVersion = secret.ResourceVersion
+	return r.Client.Status().Update(ctx, instance)
+}
+
+// CreateOrUpdateCertificate manages the cert-manager Certificate of an instance with tls.issuerRef set
+func (r *RedisReplicationReconciler) CreateOrUpdateCertificate(ctx context.Context, instance *v1.RedisReplication, reqLogger logr.Logger) error {
+	var certificate *unstructured.Unstructured
+	if instance.Spec.TLSConfig != nil && instance.Spec.TLSConfig.IssuerRef != nil {
+		certificate = redisreplication.CreateCertificate(instance)
+	}
+
+	created, err := reconcileCertificate(ctx, r.K8Client, r.Dk8Client, instance.Namespace, instance.GetCertificateName(), certificate, reqLogger)
+	if err != nil {
+		return err
+	}
+	if created {
+		r.Recorder.Eventf(instance, corev1.EventTypeNormal, EventReasonCreated, "Created certificate %s", instance.GetCertificateName())
+	}
+	return nil
+}
+
+// CreateOrUpdateAuthSecret stores requirepass in the secret read by the exporter. Skipped when the exporter is
+// disabled or uses a secret of its own
+func (r *RedisReplicationReconciler) CreateOrUpdateAuthSecret(ctx context.Context, instance *v1.RedisReplication, reqLogger logr.Logger) error {
+	if !instance.IsExporterEnabled() {
+		return nil
+	}
+
+	passwordSecret, err := redisreplication.GetExporterPasswordSecret(instance)
+	if err != nil {
+		return err
+	}
+	if passwordSecret == nil || passwordSecret.Name != instance.GetAuthSecretName() {
+		return nil
+	}
+
+	secret, err := redisreplication.CreateAuthSecret(instance)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.K8Client.CoreV1().Secrets(instance.Namespace).Get(ctx, secret.Name, metav1.GetOptions{})
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			reqLogger.Info("Creating auth secret")
+			if _, err := r.K8Client.CoreV1().Secrets(instance.Namespace).Create(ctx, secret, metav1.CreateOptions{}); err != nil {
+				return err
+			}
+			r.Recorder.Eventf(instance, corev1.EventTypeNormal, EventReasonCreated, "Created secret %s", secret.Name)
+			return nil
+		}
+		return err
+	}
+	_, err = r.K8Client.CoreV1().Secrets(instance.Namespace).Update(ctx, secret, metav1.UpdateOptions{})
+	return err
+}
+
 // SetupWithManager sets up the controller with the Manager.
 func (r *RedisReplicationReconciler) SetupWithManager(mgr ctrl.Manager) error {
-	return ctrl.NewControllerManagedBy(mgr).
+	b := ctrl.NewControllerManagedBy(mgr).
 		For(&v1.RedisReplication{}).
 		Owns(&appsv1.StatefulSet{}).
-		Complete(r)
+		Watches(&v1.RedisSentinel{}, handler.EnqueueRequestsFromMapFunc(r.mapSentinelToReplications))
+	return r.Namespaces.watchNamespaces(b, &v1.RedisReplicationList{}).Complete(r)
+}
+
+// mapSentinelToReplications enqueues the replications monitored by a sentinel, whatever their namespace
+func (r *RedisReplicationReconciler) mapSentinelToReplications(ctx context.Context, obj client.Object) []reconcile.Request {
+	sentinel, ok := obj.(*v1.RedisSentinel)
+	if !ok {
+		return nil
+	}
+
+	requests := []reconcile.Request{}
+	for _, monitor := range sentinel.GetMonitors() {
+		requests = append(requests, reconcile.Request{NamespacedName: monitor.GetReplicationReference()})
+	}
+	return requests
 }
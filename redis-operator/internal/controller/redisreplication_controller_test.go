@@ -23,11 +23,13 @@ import (
 	. "github.com/onsi/gomega"
 	"k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	redisv1 "redis.operator/api/v1"
+	"redis.operator/pkg/redis/fake"
 )
 
 var _ = Describe("RedisReplication Controller", func() {
@@ -69,8 +71,10 @@ var _ = Describe("RedisReplication Controller", func() {
 		It("should successfully reconcile the resource", func() {
 			By("Reconciling the created resource")
 			controllerReconciler := &RedisReplicationReconciler{
-				Client: k8sClient,
-				Scheme: k8sClient.Scheme(),
+				Client:   k8sClient,
+				Scheme:   k8sClient.Scheme(),
+				Recorder: record.NewFakeRecorder(100),
+				Redis:    fake.NewTopology(),
 			}
 
 			_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{
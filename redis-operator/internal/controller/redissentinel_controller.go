@@ -19,26 +19,35 @@ package controller
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"strconv"
+	"reflect"
 	"strings"
 	"time"
 
 	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/record"
 	v1 "redis.operator/api/v1"
+	"redis.operator/pkg/config"
 	"redis.operator/pkg/kube/configmap"
+	"redis.operator/pkg/metrics"
 	k8sredis "redis.operator/pkg/redis"
 	"redis.operator/pkg/redissentinel"
 	"redis.operator/pkg/util/result"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 )
 
 // RedisSentinelReconciler reconciles a RedisSentinel object
@@ -46,8 +55,14 @@ type RedisSentinelReconciler struct {
 	client.Client
 	K8Client  kubernetes.Interface
 	Dk8Client dynamic.Interface
+	Redis     k8sredis.RedisTopologyClient
 	Log       logr.Logger
 	Scheme    *runtime.Scheme
+	Recorder  record.EventRecorder
+
+	Namespaces *NamespaceSelector // reconciles every watched namespace when nil
+
+	unreachable unreachablePods
 }
 
 func (r *RedisSentinelReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
@@ -66,21 +81,32 @@ func (r *RedisSentinelReconciler) Reconcile(ctx context.Context, req ctrl.Reques
 		if err = r.HandleReplicationFinalizer(ctx, instance, v1.RedisSentinelFinalizer); err != nil {
 			return result.RetryWithError(err, reqLogger, "Failed to handle finalizer")
 		}
+		r.unreachable.forget(req.NamespacedName)
+		metrics.DeleteInstanceMetrics(metrics.KindSentinel, instance.Namespace, instance.Name)
+		k8sredis.InvalidateClients(instance.GetHeadlessServiceName(), instance.Namespace)
 		return result.Ok()
 	}
 
+	// resources of namespaces no longer selected still release their finalizer above
+	selected, err := r.Namespaces.Matches(ctx, instance.Namespace)
+	if err != nil {
+		return result.RetryWithError(err, reqLogger, "Failed to get namespace")
+	}
+	if !selected {
+		return result.ReconciledWithMessage(reqLogger, "Namespace not selected by the operator. Skipping")
+	}
+
 	if err = r.CreateReplicationFinalizer(ctx, instance, v1.RedisSentinelFinalizer); err != nil {
 		return result.RetryWithError(err, reqLogger, "Failed to create finalizer")
 	}
 
-	if err, critical := r.CreateOrUpdateConfigMap(ctx, instance, reqLogger); err != nil {
-		if critical {
-			return result.RetryWithError(err, reqLogger, "Failed to create or update configmap")
-		} else {
-			return result.RequeueAfterWithMessage(1*time.Second, reqLogger, "non-critical error occurred", "error", err)
-		}
+	start := time.Now()
+	if err := r.CreateOrUpdateConfigMap(ctx, instance, reqLogger); err != nil {
+		return result.RetryWithError(err, reqLogger, "Failed to create or update configmap")
 	}
+	metrics.ObserveStep(metrics.KindSentinel, instance.Namespace, instance.Name, metrics.StepConfigMap, start)
 
+	start = time.Now()
 	if err := r.CreateOrUpdateHeadlessService(ctx, instance, reqLogger); err != nil {
 		return result.RetryWithError(err, reqLogger, "Failed creating or updating headless service")
 	}
@@ -88,20 +114,42 @@ func (r *RedisSentinelReconciler) Reconcile(ctx context.Context, req ctrl.Reques
 	if err := r.CreateOrUpdateService(ctx, instance, reqLogger); err != nil {
 		return result.RetryWithError(err, reqLogger, "Failed creating or updating service")
 	}
+	metrics.ObserveStep(metrics.KindSentinel, instance.Namespace, instance.Name, metrics.StepService, start)
+
+	start = time.Now()
+	if err := r.CreateOrUpdateCertificate(ctx, instance, reqLogger); err != nil {
+		return result.RetryWithError(err, reqLogger, "Failed creating or updating certificate")
+	}
+
+	if err := r.CreateOrUpdateAuthSecret(ctx, instance, reqLogger); err != nil {
+		return result.RetryWithError(err, reqLogger, "Failed creating or updating auth secret")
+	}
 
 	if err := r.CreateOrUpdateSentinel(ctx, instance, reqLogger); err != nil {
 		return result.RetryWithError(err, reqLogger, "Failed creating or updating sentinel")
 	}
+	metrics.ObserveStep(metrics.KindSentinel, instance.Namespace, instance.Name, metrics.StepStatefulSet, start)
+
+	if err := r.ReloadTLSCertificates(ctx, instance, reqLogger); err != nil {
+		return result.RetryWithError(err, reqLogger, "Failed to reload tls certificates")
+	}
 
-	if err := r.CheckSentinelStatus(ctx, instance, reqLogger); err != nil {
+	start = time.Now()
+	masters, err := r.UpdateSentinelMonitors(ctx, instance, reqLogger)
+	if err != nil {
+		return result.RetryWithError(err, reqLogger, "Failed to update monitored masters")
+	}
+
+	if err := r.CheckSentinelStatus(ctx, instance, masters, reqLogger); err != nil {
 		return result.RetryWithError(err, reqLogger, "Failed to check sentinel status")
 	}
+	metrics.ObserveStep(metrics.KindSentinel, instance.Namespace, instance.Name, metrics.StepSentinel, start)
 
 	if err := r.UpdateSentinelLabels(ctx, instance, reqLogger); err != nil {
 		return result.RetryWithError(err, reqLogger, "Failed to update sentinel labels")
 	}
 
-	return result.RequeueAfter(1 * time.Second)
+	return result.RequeueAfter(config.Get().RequeueInterval.Duration)
 }
 
 func (r *RedisSentinelReconciler) CreateReplicationFinalizer(ctx context.Context, instance *v1.RedisSentinel, finalizer string) error {
@@ -137,18 +185,16 @@ func (r *RedisSentinelReconciler) UpdateSentinelLabels(ctx context.Context, inst
 		return err
 	}
 
-	replicaInfo, err := k8sredis.GetReplicaInfo(ctx, r.K8Client, replicaInstance, reqLogger)
+	replicaInfo, err := r.Redis.GetReplicaInfo(ctx, replicaInstance, reqLogger)
 	if err != nil {
 		return err
 	}
 
 	masterDNS := "none"
 	for _, info := range replicaInfo {
-		if role, ok := info.Info["role"]; ok {
-			if role == "master" {
-				masterDNS = info.DNS
-				break
-			}
+		if info.Redis.Replication.Role == "master" {
+			masterDNS = info.DNS
+			break
 		}
 	}
 
@@ -173,49 +219,278 @@ func (r *RedisSentinelReconciler) UpdateSentinelLabels(ctx context.Context, inst
 	return nil
 }
 
-func (r *RedisSentinelReconciler) CheckSentinelStatus(ctx context.Context, instance *v1.RedisSentinel, logger logr.Logger) error {
+// CheckSentinelStatus records the agreement of the sentinels on every monitored master and repairs the sentinels
+// reporting one of masters, the masters reached by the operator, down
+func (r *RedisSentinelReconciler) CheckSentinelStatus(ctx context.Context, instance *v1.RedisSentinel, masters map[string]string, logger logr.Logger) error {
 
 	replicaInstance, err := r.GetRedisReplicationInstance(ctx, instance)
 	if err != nil {
 		return err
 	}
 
-	redisInfo, err := k8sredis.GetSentinelMasters(ctx, r.K8Client, instance, replicaInstance)
-	if err != nil {
-		return err
+	reachable := map[int]bool{}
+	sick := []k8sredis.SickSentinel{}
+	for _, monitor := range instance.GetMonitors() {
+		sentinelMasters, err := r.Redis.GetSentinelMasters(ctx, instance, replicaInstance, monitor.MasterName)
+		if err != nil {
+			return err
+		}
+
+		for _, master := range sentinelMasters {
+			reachable[master.SentinelPodIndex] = true
+		}
+		metrics.SentinelAgreement.WithLabelValues(metrics.KindSentinel, instance.Namespace, instance.Name, monitor.MasterName).Set(float64(GetSentinelAgreement(sentinelMasters)))
+		if len(sentinelMasters) < monitor.Quorum {
+			r.Recorder.Eventf(instance, corev1.EventTypeWarning, EventReasonQuorumLost, "Only %d sentinels monitor %s. quorum is %d", len(sentinelMasters), monitor.MasterName, monitor.Quorum)
+		}
+
+		_, masterReachable := masters[monitor.MasterName]
+		sick = append(sick, k8sredis.FindSickSentinels(sentinelMasters, monitor.MasterName, masterReachable, monitor.Quorum, instance.Spec.Repair.GetDownTimeThreshold())...)
 	}
 
-	for _, info := range redisInfo {
+	unreachable := []string{}
+	for i := 0; i < instance.Spec.StatefulsetConfig.GetReplicas(); i++ {
+		if !reachable[i] {
+			metrics.RedisConnectionErrors.WithLabelValues(metrics.KindSentinel, instance.Namespace, instance.Name).Inc()
+			unreachable = append(unreachable, fmt.Sprintf("%s-%d", instance.Name, i))
+		}
+	}
+	for _, pod := range r.unreachable.update(client.ObjectKeyFromObject(instance), unreachable) {
+		r.Recorder.Eventf(instance, corev1.EventTypeWarning, EventReasonConnectionFailed, "Failed to connect to sentinel pod %s", pod)
+	}
 
-		if foundDownTime, ok := info.Info["s-down-time"]; ok {
-			downTime, err := strconv.Atoi(foundDownTime)
+	return r.RepairSentinels(ctx, instance, replicaInstance, sick, len(reachable), logger)
+}
+
+// RepairSentinels takes the next repair step of every sick sentinel once the previous one had the down time
+// threshold to take effect. Sentinels are reset first, made to monitor the master again next, and restarted last.
+// Restarts are rate limited and skipped when the remaining sentinels would lose the quorum of a master
+func (r *RedisSentinelReconciler) RepairSentinels(ctx context.Context, instance *v1.RedisSentinel, replicaInstance *v1.RedisReplication, sick []k8sredis.SickSentinel, reachable int, logger logr.Logger) error {
+	threshold := instance.Spec.Repair.GetDownTimeThreshold()
+	previous := map[string]v1.RedisSentinelRepairStatus{}
+	for _, repair := range instance.Status.Repairs {
+		previous[fmt.Sprintf("%d/%s", repair.PodIndex, repair.MasterName)] = repair
+	}
+
+	repairs := []v1.RedisSentinelRepairStatus{}
+	restarted := map[int]bool{}
+	for _, sentinel := range sick {
+		repair, ok := previous[fmt.Sprintf("%d/%s", sentinel.PodIndex, sentinel.MasterName)]
+		if !ok {
+			repair = v1.RedisSentinelRepairStatus{PodIndex: sentinel.PodIndex, MasterName: sentinel.MasterName}
+		}
+		if (ok && time.Since(repair.LastAttempt.Time) < threshold) || restarted[sentinel.PodIndex] {
+			repairs = append(repairs, repair)
+			continue
+		}
+
+		podName := fmt.Sprintf("%s-%d", instance.Name, sentinel.PodIndex)
+		switch repair.Attempts {
+		case 0:
+			logger.Info("sentinel reports a reachable master down. resetting", "pod", sentinel.DNS, "master", sentinel.MasterName, "downTime", sentinel.DownTime)
+			if err := r.Redis.ResetSentinelMaster(ctx, instance, replicaInstance, sentinel.PodIndex, sentinel.MasterName); err != nil {
+				return err
+			}
+			r.Recorder.Eventf(instance, corev1.EventTypeWarning, EventReasonSentinelReset, "Reset master %s on sentinel pod %s after it reported it down for %s", sentinel.MasterName, podName, sentinel.DownTime)
+		case 1:
+			// added again by the next reconcile
+			logger.Info("sentinel still reports a reachable master down. monitoring it again", "pod", sentinel.DNS, "master", sentinel.MasterName)
+			if err := r.Redis.RemoveSentinelMaster(ctx, instance, replicaInstance, sentinel.PodIndex, sentinel.MasterName); err != nil {
+				return err
+			}
+			r.Recorder.Eventf(instance, corev1.EventTypeWarning, EventReasonSentinelRemonitored, "Monitoring master %s again on sentinel pod %s", sentinel.MasterName, podName)
+		default:
+			logger.Info("sentinel could not be repaired. restarting", "pod", sentinel.DNS, "master", sentinel.MasterName)
+			ok, err := r.RestartSentinel(ctx, instance, sentinel.PodIndex, reachable, logger)
 			if err != nil {
 				return err
 			}
-			if downTime > 20000 { // 20s
-				podName := fmt.Sprintf("%s-%d", instance.Name, info.PodIndex)
-				logger.Info("Detected a sentinel down longer than 20s. Restarting", "PodIP", info.DNS)
-				err = r.K8Client.CoreV1().Pods(instance.Namespace).Delete(ctx, podName, metav1.DeleteOptions{}) // delete the pod to force it to restart with the updated configmap.
-				if err != nil {
-					return err
-				}
+			if !ok {
+				repairs = append(repairs, repair)
+				continue
 			}
+			restarted[sentinel.PodIndex] = true
+			reachable--
+			r.Recorder.Eventf(instance, corev1.EventTypeWarning, EventReasonSentinelRestart, "Restarted sentinel pod %s after it reported master %s down for %s", podName, sentinel.MasterName, sentinel.DownTime)
 		}
+
+		repair.Attempts++
+		repair.LastAttempt = metav1.Now()
+		repairs = append(repairs, repair)
 	}
 
-	return nil
+	if (len(repairs) == 0 && len(instance.Status.Repairs) == 0) || reflect.DeepEqual(repairs, instance.Status.Repairs) {
+		return nil
+	}
+	instance.Status.Repairs = repairs
+	return r.Client.Status().Update(ctx, instance)
+}
+
+// RestartSentinel deletes the pod of a sentinel unless a sentinel was restarted less than the restart interval ago or
+// the remaining reachable sentinels would lose the quorum of a master. Returns false when the restart is skipped.
+// The time of the restart is set in the status, which is left to the caller to update
+func (r *RedisSentinelReconciler) RestartSentinel(ctx context.Context, instance *v1.RedisSentinel, podIndex int, reachable int, logger logr.Logger) (bool, error) {
+	podName := fmt.Sprintf("%s-%d", instance.Name, podIndex)
+	if instance.Status.LastRestart != nil && time.Since(instance.Status.LastRestart.Time) < instance.Spec.Repair.GetRestartInterval() {
+		logger.Info("sentinel restart is rate limited", "pod", podName, "lastRestart", instance.Status.LastRestart.Time)
+		return false, nil
+	}
+	if !k8sredis.CanRestartSentinel(instance, reachable) {
+		logger.Info("restarting the sentinel would lose the quorum", "pod", podName, "reachable", reachable)
+		return false, nil
+	}
+
+	if err := r.K8Client.CoreV1().Pods(instance.Namespace).Delete(ctx, podName, metav1.DeleteOptions{}); err != nil {
+		return false, err
+	}
+	instance.Status.LastRestart = &metav1.Time{Time: time.Now()}
+	return true, nil
+}
+
+// UpdateSentinelMonitors makes every sentinel monitor the master of each replication and forget the masters of the
+// replications no longer monitored. Returns the address of every master the operator reaches by master name
+func (r *RedisSentinelReconciler) UpdateSentinelMonitors(ctx context.Context, instance *v1.RedisSentinel, logger logr.Logger) (map[string]string, error) {
+	primaryInstance, err := r.GetRedisReplicationInstance(ctx, instance)
+	if err != nil {
+		return nil, err
+	}
+
+	monitors := []k8sredis.SentinelMonitor{}
+	monitored := []string{}
+	masters := map[string]string{}
+	replicationNames := map[string]string{}
+	for _, monitor := range instance.GetMonitors() {
+		if monitor.Quorum < 1 {
+			return nil, fmt.Errorf("quorum of master %s is not set", monitor.MasterName)
+		}
+		monitored = append(monitored, monitor.MasterName)
+		replicationNames[monitor.MasterName] = monitor.GetReplicationReference().String()
+
+		replicaInstance, err := getMonitoredReplication(ctx, r.Dk8Client, instance, monitor.GetReplicationReference())
+		if err != nil {
+			if apierrors.IsNotFound(err) {
+				logger.Info("monitored replication not found", "replication", monitor.RedisReplicationName, "namespace", monitor.RedisReplicationNamespace)
+				continue
+			}
+			if errors.Is(err, errReferenceDenied) {
+				logger.Info("monitored replication denied the reference", "replication", monitor.RedisReplicationName, "namespace", monitor.RedisReplicationNamespace)
+				r.Recorder.Event(instance, corev1.EventTypeWarning, EventReasonReferenceDenied, err.Error())
+				continue
+			}
+			return nil, err
+		}
+
+		masterDNS, err := GetReplicationMasterDNS(ctx, r.Redis, replicaInstance, logger)
+		if err != nil {
+			return nil, err
+		}
+		if masterDNS == "" {
+			logger.Info("uncertain master. not monitoring the replication yet", "replication", monitor.RedisReplicationName, "namespace", monitor.RedisReplicationNamespace)
+			continue
+		}
+
+		settings := redissentinel.GetMonitorSettings(instance, monitor, replicaInstance)
+		if _, ok := settings["auth-pass"]; !ok {
+			password, err := replicaInstance.Spec.RedisConfig.GetValue("redis.conf", "requirepass")
+			if err != nil {
+				return nil, err
+			}
+			if password != "" {
+				settings["auth-pass"] = password
+			}
+		}
+
+		masters[monitor.MasterName] = masterDNS
+		monitors = append(monitors, k8sredis.SentinelMonitor{
+			Name:     monitor.MasterName,
+			DNS:      masterDNS,
+			Port:     replicaInstance.GetReplicationPort(), // sentinels are switched to the tls-replication of the mode before monitoring it
+			Quorum:   monitor.Quorum,
+			Settings: settings,
+		})
+	}
+
+	removed := []string{}
+	for _, name := range instance.Status.MonitoredMasters {
+		if _, ok := replicationNames[name]; !ok {
+			removed = append(removed, name)
+		}
+	}
+
+	changes, err := r.Redis.UpdateSentinelMonitors(ctx, instance, primaryInstance, monitors, removed, logger)
+	if err != nil {
+		return nil, err
+	}
+	for _, name := range changes.Added {
+		r.Recorder.Eventf(instance, corev1.EventTypeNormal, EventReasonMonitorAdded, "Sentinels monitor master %s of replication %s", name, replicationNames[name])
+	}
+	for _, name := range changes.Updated {
+		r.Recorder.Eventf(instance, corev1.EventTypeNormal, EventReasonConfigUpdated, "Updated the settings of master %s", name)
+	}
+	for _, name := range changes.Removed {
+		metrics.SentinelAgreement.DeleteLabelValues(metrics.KindSentinel, instance.Namespace, instance.Name, name)
+		r.Recorder.Eventf(instance, corev1.EventTypeNormal, EventReasonMonitorRemoved, "Sentinels no longer monitor master %s", name)
+	}
+
+	if reflect.DeepEqual(instance.Status.MonitoredMasters, monitored) {
+		return masters, nil
+	}
+	instance.Status.MonitoredMasters = monitored
+	return masters, r.Client.Status().Update(ctx, instance)
+}
+
+// GetReplicationMasterDNS returns the master of a replication, empty when there is not exactly one
+func GetReplicationMasterDNS(ctx context.Context, redisClient k8sredis.RedisTopologyClient, replicaInstance *v1.RedisReplication, logger logr.Logger) (string, error) {
+	redisInfo, err := redisClient.GetReplicaInfo(ctx, replicaInstance, logger)
+	if err != nil {
+		return "", err
+	}
+
+	masterDNS := []string{}
+	for _, info := range redisInfo {
+		if info.Redis.Replication.Role == "master" {
+			masterDNS = append(masterDNS, info.DNS)
+		}
+	}
+	if len(masterDNS) != 1 {
+		return "", nil
+	}
+	return masterDNS[0], nil
+}
+
+// returns the number of sentinels agreeing on the most common master
+func GetSentinelAgreement(sentinelMasters []k8sredis.SentinelMaster) int {
+	agreed := map[string]int{}
+	highest := 0
+	for _, sentinelMaster := range sentinelMasters {
+		if ip := sentinelMaster.IP; ip != "" {
+			agreed[ip]++
+			if agreed[ip] > highest {
+				highest = agreed[ip]
+			}
+		}
+	}
+	return highest
 }
 
 func (r *RedisSentinelReconciler) CreateOrUpdateService(ctx context.Context, instance *v1.RedisSentinel, logger logr.Logger) error {
 
-	newService := redissentinel.CreateSentinelService(instance)
+	replicationInstance, err := r.GetRedisReplicationInstance(ctx, instance)
+	if err != nil {
+		return err
+	}
 
-	_, err := r.K8Client.CoreV1().Services(instance.Namespace).Get(ctx, instance.GetServiceName(), metav1.GetOptions{})
+	newService := redissentinel.CreateSentinelService(instance, replicationInstance)
+
+	_, err = r.K8Client.CoreV1().Services(instance.Namespace).Get(ctx, instance.GetServiceName(), metav1.GetOptions{})
 	if err != nil {
 		if apierrors.IsNotFound(err) {
 			logger.Info("Creating service")
-			_, err := r.K8Client.CoreV1().Services(instance.Namespace).Create(ctx, &newService, metav1.CreateOptions{})
-			return err
+			if _, err := r.K8Client.CoreV1().Services(instance.Namespace).Create(ctx, &newService, metav1.CreateOptions{}); err != nil {
+				return err
+			}
+			r.Recorder.Eventf(instance, corev1.EventTypeNormal, EventReasonCreated, "Created service %s", newService.Name)
+			return nil
 		}
 		return err
 	}
@@ -225,14 +500,22 @@ func (r *RedisSentinelReconciler) CreateOrUpdateService(ctx context.Context, ins
 
 func (r *RedisSentinelReconciler) CreateOrUpdateHeadlessService(ctx context.Context, instance *v1.RedisSentinel, logger logr.Logger) error {
 
-	newService := redissentinel.CreateSentinelHeadlessService(instance)
+	replicationInstance, err := r.GetRedisReplicationInstance(ctx, instance)
+	if err != nil {
+		return err
+	}
+
+	newService := redissentinel.CreateSentinelHeadlessService(instance, replicationInstance)
 
-	_, err := r.K8Client.CoreV1().Services(instance.Namespace).Get(ctx, instance.GetHeadlessServiceName(), metav1.GetOptions{})
+	_, err = r.K8Client.CoreV1().Services(instance.Namespace).Get(ctx, instance.GetHeadlessServiceName(), metav1.GetOptions{})
 	if err != nil {
 		if apierrors.IsNotFound(err) {
 			logger.Info("Creating headless service")
-			_, err := r.K8Client.CoreV1().Services(instance.Namespace).Create(ctx, &newService, metav1.CreateOptions{})
-			return err
+			if _, err := r.K8Client.CoreV1().Services(instance.Namespace).Create(ctx, &newService, metav1.CreateOptions{}); err != nil {
+				return err
+			}
+			r.Recorder.Eventf(instance, corev1.EventTypeNormal, EventReasonCreated, "Created headless service %s", newService.Name)
+			return nil
 		}
 		return err
 	}
@@ -261,8 +544,11 @@ func (r *RedisSentinelReconciler) CreateOrUpdateSentinel(ctx context.Context, in
 	if err != nil {
 		if apierrors.IsNotFound(err) {
 			logger.Info("Creating statefulset")
-			_, err = r.K8Client.AppsV1().StatefulSets(instance.GetNamespace()).Create(ctx, statefulSet, metav1.CreateOptions{})
-			return err
+			if _, err = r.K8Client.AppsV1().StatefulSets(instance.GetNamespace()).Create(ctx, statefulSet, metav1.CreateOptions{}); err != nil {
+				return err
+			}
+			r.Recorder.Eventf(instance, corev1.EventTypeNormal, EventReasonCreated, "Created statefulset %s", statefulSet.Name)
+			return nil
 		}
 		return err
 	}
@@ -270,12 +556,43 @@ func (r *RedisSentinelReconciler) CreateOrUpdateSentinel(ctx context.Context, in
 	return err
 }
 
+// GetRedisReplicationInstance returns the first monitored replication, providing the default tls settings of the
+// sentinels. Secrets aren't shared across namespaces, so sentinels whose first replication has tls in another
+// namespace need their own tls settings
 func (r *RedisSentinelReconciler) GetRedisReplicationInstance(ctx context.Context, instance *v1.RedisSentinel) (*v1.RedisReplication, error) {
-	customObject, err := r.Dk8Client.Resource(schema.GroupVersionResource{
+	reference := instance.GetPrimaryReplication()
+	if reference.Name == "" {
+		return nil, fmt.Errorf("no redis replication is monitored")
+	}
+	replicaInstance, err := getMonitoredReplication(ctx, r.Dk8Client, instance, reference)
+	if err != nil {
+		return nil, err
+	}
+	if reference.Namespace != instance.Namespace && replicaInstance.Spec.TLSConfig != nil && instance.Spec.TLSConfig == nil {
+		return nil, fmt.Errorf("replication %s has tls in another namespace, tlsConfig of the sentinel must be set", reference)
+	}
+	return replicaInstance, nil
+}
+
+// getMonitoredReplication returns the replication of reference monitored by instance. A replication of another
+// namespace is only returned when its allowedNamespaces contain the namespace of instance
+func getMonitoredReplication(ctx context.Context, dk8Client dynamic.Interface, instance *v1.RedisSentinel, reference types.NamespacedName) (*v1.RedisReplication, error) {
+	replicaInstance, err := getRedisReplication(ctx, dk8Client, reference.Namespace, reference.Name)
+	if err != nil {
+		return nil, err
+	}
+	if !replicaInstance.AllowsNamespace(instance.Namespace) {
+		return nil, fmt.Errorf("%w: replication %s does not allow sentinels of namespace %s", errReferenceDenied, reference, instance.Namespace)
+	}
+	return replicaInstance, nil
+}
+
+func getRedisReplication(ctx context.Context, dk8Client dynamic.Interface, namespace string, name string) (*v1.RedisReplication, error) {
+	customObject, err := dk8Client.Resource(schema.GroupVersionResource{
 		Group:    "redis.redis.operator",
 		Version:  "v1",
 		Resource: "redisreplications",
-	}).Namespace(instance.Namespace).Get(ctx, instance.Spec.RedisReplicationName, metav1.GetOptions{})
+	}).Namespace(namespace).Get(ctx, name, metav1.GetOptions{})
 	if err != nil {
 		return nil, err
 	}
@@ -293,15 +610,15 @@ func (r *RedisSentinelReconciler) GetRedisReplicationInstance(ctx context.Contex
 	return redisReplication, nil
 }
 
-func (r *RedisSentinelReconciler) CreateOrUpdateConfigMap(ctx context.Context, instance *v1.RedisSentinel, logger logr.Logger) (error, bool) {
+func (r *RedisSentinelReconciler) CreateOrUpdateConfigMap(ctx context.Context, instance *v1.RedisSentinel, logger logr.Logger) error {
 
 	if _, ok := instance.Spec.RedisConfig.Data["sentinel.conf"]; !ok {
-		return fmt.Errorf("sentinel.conf not found in redisConfig"), true
+		return fmt.Errorf("sentinel.conf not found in redisConfig")
 	}
 
 	replicaInstance, err := r.GetRedisReplicationInstance(ctx, instance)
 	if err != nil {
-		return err, true
+		return err
 	}
 
 	newConfigMap := configmap.NewBuilder().
@@ -310,27 +627,171 @@ func (r *RedisSentinelReconciler) CreateOrUpdateConfigMap(ctx context.Context, i
 		SetData(instance.Spec.RedisConfig.Data).
 		BuildWithOwner(instance.GetOwnerReference())
 
-	if err, critical := redissentinel.UpdateConfigMap(ctx, instance, replicaInstance, r.K8Client, newConfigMap, logger); err != nil {
-		return err, critical
+	if err := redissentinel.UpdateConfigMap(instance, replicaInstance, newConfigMap); err != nil {
+		return err
 	}
 
-	_, err = r.K8Client.CoreV1().ConfigMaps(instance.Namespace).Get(ctx, instance.GetConfigName(), metav1.GetOptions{})
+	currentConfigMap, err := r.K8Client.CoreV1().ConfigMaps(instance.Namespace).Get(ctx, instance.GetConfigName(), metav1.GetOptions{})
 	if err != nil {
 		if apierrors.IsNotFound(err) {
 			logger.Info("Creating configmap")
-			_, err = r.K8Client.CoreV1().ConfigMaps(instance.Namespace).Create(ctx, newConfigMap, metav1.CreateOptions{})
-			return err, true
+			if _, err = r.K8Client.CoreV1().ConfigMaps(instance.Namespace).Create(ctx, newConfigMap, metav1.CreateOptions{}); err != nil {
+				return err
+			}
+			r.Recorder.Eventf(instance, corev1.EventTypeNormal, EventReasonCreated, "Created configmap %s", newConfigMap.Name)
+			return nil
 		}
-		return err, true
+		return err
+	}
+
+	if _, err = r.K8Client.CoreV1().ConfigMaps(instance.Namespace).Update(ctx, newConfigMap, metav1.UpdateOptions{}); err != nil {
+		return err
+	}
+	if !reflect.DeepEqual(currentConfigMap.Data, newConfigMap.Data) {
+		r.Recorder.Eventf(instance, corev1.EventTypeNormal, EventReasonConfigUpdated, "Updated configmap %s", newConfigMap.Name)
+	}
+	return nil
+}
+
+// RestartRefusingSentinel deletes the pod of the first sentinel refusing to reload its certificate. Nothing is
+// restarted while a sentinel is unreachable, usually the one restarted by the previous reconcile
+func (r *RedisSentinelReconciler) RestartRefusingSentinel(ctx context.Context, instance *v1.RedisSentinel, reload *k8sredis.SentinelCertificateReload, logger logr.Logger) error {
+	podName := fmt.Sprintf("%s-%d", instance.Name, reload.Refused[0])
+	if reload.Reachable < instance.Spec.StatefulsetConfig.GetReplicas() {
+		logger.Info("waiting for every sentinel to be reachable before restarting the next one", "pod", podName, "reachable", reload.Reachable)
+		return nil
+	}
+	if !k8sredis.CanRestartSentinel(instance, reload.Reachable) {
+		logger.Info("restarting the sentinel would lose the quorum", "pod", podName, "reachable", reload.Reachable)
+		return nil
 	}
 
-	_, err = r.K8Client.CoreV1().ConfigMaps(instance.Namespace).Update(ctx, newConfigMap, metav1.UpdateOptions{})
-	return err, true
+	if err := r.K8Client.CoreV1().Pods(instance.Namespace).Delete(ctx, podName, metav1.DeleteOptions{}); err != nil {
+		return err
+	}
+	logger.Info("restarted sentinel refusing to reload its certificate", "pod", podName, "refused", len(reload.Refused))
+	r.Recorder.Eventf(instance, corev1.EventTypeNormal, EventReasonSentinelRestart, "Restarted sentinel pod %s to load the renewed certificate", podName)
+	return nil
+}
+
+// ReloadTLSCertificates makes every sentinel load the certificate again after the tls secret changed. Sentinels
+// refusing the reload are restarted one per reconcile, once every sentinel is reachable and only when the others keep
+// the quorum. The secret counts as loaded once every sentinel serves its certificate
+func (r *RedisSentinelReconciler) ReloadTLSCertificates(ctx context.Context, instance *v1.RedisSentinel, logger logr.Logger) error {
+	replicationInstance, err := r.GetRedisReplicationInstance(ctx, instance)
+	if err != nil {
+		return fmt.Errorf("failed to get replication instance: %v", err)
+	}
+	if instance.GetTLSConfig(replicationInstance) == nil {
+		return nil
+	}
+
+	secret, err := r.K8Client.CoreV1().Secrets(instance.Namespace).Get(ctx, instance.GetTLSSecretName(replicationInstance), metav1.GetOptions{})
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil // not issued yet
+		}
+		return err
+	}
+	if secret.ResourceVersion == instance.Status.TLSSecretVersion {
+		return nil
+	}
+
+	// sentinels started after the secret was created have already loaded it
+	if instance.Status.TLSSecretVersion != "" {
+		reload, err := r.Redis.ReloadSentinelCertificates(ctx, instance, replicationInstance, logger)
+		if err != nil {
+			return err
+		}
+		if len(reload.Refused) > 0 {
+			return r.RestartRefusingSentinel(ctx, instance, reload, logger)
+		}
+		if !reload.Reloaded {
+			return nil
+		}
+		logger.Info("Reloaded tls certificates", "secret", secret.Name)
+		r.Recorder.Eventf(instance, corev1.EventTypeNormal, EventReasonCertReloaded, "Reloaded certificate from secret %s", secret.Name)
+	}
+
+	instance.Status.TLSSecretVersion = secret.ResourceVersion
+	return r.Client.Status().Update(ctx, instance)
+}
+
+// CreateOrUpdateCertificate manages the cert-manager Certificate of a sentinel with tls.issuerRef set
+func (r *RedisSentinelReconciler) CreateOrUpdateCertificate(ctx context.Context, instance *v1.RedisSentinel, logger logr.Logger) error {
+	var certificate *unstructured.Unstructured
+	if instance.Spec.TLSConfig != nil && instance.Spec.TLSConfig.IssuerRef != nil {
+		certificate = redissentinel.CreateCertificate(instance)
+	}
+
+	created, err := reconcileCertificate(ctx, r.K8Client, r.Dk8Client, instance.Namespace, instance.GetCertificateName(), certificate, logger)
+	if err != nil {
+		return err
+	}
+	if created {
+		r.Recorder.Eventf(instance, corev1.EventTypeNormal, EventReasonCreated, "Created certificate %s", instance.GetCertificateName())
+	}
+	return nil
+}
+
+// CreateOrUpdateAuthSecret stores requirepass in the secret read by the exporter. Skipped when the exporter is
+// disabled or uses a secret of its own
+func (r *RedisSentinelReconciler) CreateOrUpdateAuthSecret(ctx context.Context, instance *v1.RedisSentinel, logger logr.Logger) error {
+	if !instance.IsExporterEnabled() {
+		return nil
+	}
+
+	passwordSecret, err := redissentinel.GetExporterPasswordSecret(instance)
+	if err != nil {
+		return err
+	}
+	if passwordSecret == nil || passwordSecret.Name != instance.GetAuthSecretName() {
+		return nil
+	}
+
+	secret, err := redissentinel.CreateAuthSecret(instance)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.K8Client.CoreV1().Secrets(instance.Namespace).Get(ctx, secret.Name, metav1.GetOptions{})
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			logger.Info("Creating auth secret")
+			if _, err := r.K8Client.CoreV1().Secrets(instance.Namespace).Create(ctx, secret, metav1.CreateOptions{}); err != nil {
+				return err
+			}
+			r.Recorder.Eventf(instance, corev1.EventTypeNormal, EventReasonCreated, "Created secret %s", secret.Name)
+			return nil
+		}
+		return err
+	}
+	_, err = r.K8Client.CoreV1().Secrets(instance.Namespace).Update(ctx, secret, metav1.UpdateOptions{})
+	return err
 }
 
 // SetupWithManager sets up the controller with the Manager.
 func (r *RedisSentinelReconciler) SetupWithManager(mgr ctrl.Manager) error {
-	return ctrl.NewControllerManagedBy(mgr).
+	b := ctrl.NewControllerManagedBy(mgr).
 		For(&v1.RedisSentinel{}).
-		Complete(r)
+		Watches(&v1.RedisReplication{}, handler.EnqueueRequestsFromMapFunc(r.mapReplicationToSentinels))
+	return r.Namespaces.watchNamespaces(b, &v1.RedisSentinelList{}).Complete(r)
+}
+
+// mapReplicationToSentinels enqueues the sentinels of every watched namespace monitoring a replication, so they
+// pick up changes of its allowedNamespaces
+func (r *RedisSentinelReconciler) mapReplicationToSentinels(ctx context.Context, obj client.Object) []reconcile.Request {
+	sentinels := &v1.RedisSentinelList{}
+	if err := r.List(ctx, sentinels); err != nil {
+		ctrl.LoggerFrom(ctx).Error(err, "failed to list the sentinels monitoring a replication", "replication", obj.GetName(), "namespace", obj.GetNamespace())
+		return nil
+	}
+
+	requests := []reconcile.Request{}
+	for i := range sentinels.Items {
+		if sentinels.Items[i].GetMonitor(client.ObjectKeyFromObject(obj)) != nil {
+			requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&sentinels.Items[i])})
+		}
+	}
+	return requests
 }
@@ -0,0 +1,76 @@
+package controller
+
+import (
+	"context"
+	"errors"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	redisv1 "redis.operator/api/v1"
+)
+
+var _ = Describe("Cross-namespace references", func() {
+	ctx := context.Background()
+
+	It("only follows references allowed by the referenced resource", func() {
+		for _, name := range []string{"sentinels", "replications"} {
+			Expect(k8sClient.Create(ctx, &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: name}})).To(Succeed())
+		}
+
+		replication := &redisv1.RedisReplication{
+			ObjectMeta: metav1.ObjectMeta{Name: "shared", Namespace: "replications"},
+			Spec: redisv1.RedisReplicationSpec{
+				RedisSentinelConfig: &redisv1.RedisReplicationSentinelConfig{
+					RedisSentinelName:      "shared",
+					RedisSentinelNamespace: "sentinels",
+				},
+			},
+		}
+		Expect(k8sClient.Create(ctx, replication)).To(Succeed())
+		sentinel := &redisv1.RedisSentinel{
+			ObjectMeta: metav1.ObjectMeta{Name: "shared", Namespace: "sentinels"},
+			Spec: redisv1.RedisSentinelSpec{
+				Monitors: []redisv1.RedisSentinelMonitor{{
+					MasterName:                "shared",
+					RedisReplicationName:      "shared",
+					RedisReplicationNamespace: "replications",
+				}},
+			},
+		}
+		Expect(k8sClient.Create(ctx, sentinel)).To(Succeed())
+
+		sentinelReconciler := &RedisSentinelReconciler{Client: k8sClient, Dk8Client: dynamicClient}
+		replicationReconciler := &RedisReplicationReconciler{Client: k8sClient, Dk8Client: dynamicClient}
+
+		By("denying the references while the allow-lists are empty")
+		_, err := sentinelReconciler.GetRedisReplicationInstance(ctx, sentinel)
+		Expect(errors.Is(err, errReferenceDenied)).To(BeTrue())
+		_, err = replicationReconciler.GetRedisSentinelInstance(ctx, replication)
+		Expect(errors.Is(err, errReferenceDenied)).To(BeTrue())
+
+		By("following the references once the namespaces are allowed")
+		replication.Spec.AllowedNamespaces = []string{"sentinels"}
+		Expect(k8sClient.Update(ctx, replication)).To(Succeed())
+		sentinel.Spec.AllowedNamespaces = []string{"*"}
+		Expect(k8sClient.Update(ctx, sentinel)).To(Succeed())
+		_, err = sentinelReconciler.GetRedisReplicationInstance(ctx, sentinel)
+		Expect(err).NotTo(HaveOccurred())
+		_, err = replicationReconciler.GetRedisSentinelInstance(ctx, replication)
+		Expect(err).NotTo(HaveOccurred())
+
+		By("enqueueing the peers of the other namespace")
+		Eventually(func() []reconcile.Request {
+			return sentinelReconciler.mapReplicationToSentinels(ctx, replication)
+		}).Should(ConsistOf(reconcile.Request{NamespacedName: types.NamespacedName{Name: "shared", Namespace: "sentinels"}}))
+		Expect(replicationReconciler.mapSentinelToReplications(ctx, sentinel)).To(ConsistOf(
+			reconcile.Request{NamespacedName: types.NamespacedName{Name: "shared", Namespace: "replications"}}))
+
+		Expect(k8sClient.Delete(ctx, sentinel)).To(Succeed())
+		Expect(k8sClient.Delete(ctx, replication)).To(Succeed())
+	})
+})
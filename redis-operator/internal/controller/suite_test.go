@@ -19,6 +19,7 @@ package controller
 import (
 	"context"
 	"fmt"
+	"os"
 	"path/filepath"
 	"runtime"
 	"testing"
@@ -26,6 +27,8 @@ import (
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/kubernetes/scheme"
 	"k8s.io/client-go/rest"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -42,6 +45,8 @@ import (
 
 var cfg *rest.Config
 var k8sClient client.Client
+var k8sClientset kubernetes.Interface
+var dynamicClient dynamic.Interface
 var testEnv *envtest.Environment
 var ctx context.Context
 var cancel context.CancelFunc
@@ -57,6 +62,17 @@ var _ = BeforeSuite(func() {
 
 	ctx, cancel = context.WithCancel(context.TODO())
 
+	// the builders read the images from the environment of the manager, see config/manager/manager.yaml
+	for env, image := range map[string]string{
+		"REPLICA_IMAGE":  "redis:latest",
+		"SENTINEL_IMAGE": "redis:7.4.0",
+		"EXPORTER_IMAGE": "oliver006/redis_exporter:v1.63.0",
+	} {
+		if _, found := os.LookupEnv(env); !found {
+			Expect(os.Setenv(env, image)).To(Succeed())
+		}
+	}
+
 	By("bootstrapping test environment")
 	testEnv = &envtest.Environment{
 		CRDDirectoryPaths:     []string{filepath.Join("..", "..", "config", "crd", "bases")},
@@ -86,6 +102,12 @@ var _ = BeforeSuite(func() {
 	Expect(err).NotTo(HaveOccurred())
 	Expect(k8sClient).NotTo(BeNil())
 
+	k8sClientset, err = kubernetes.NewForConfig(cfg)
+	Expect(err).NotTo(HaveOccurred())
+
+	dynamicClient, err = dynamic.NewForConfig(cfg)
+	Expect(err).NotTo(HaveOccurred())
+
 })
 
 var _ = AfterSuite(func() {
@@ -0,0 +1,306 @@
+// Package config loads the configuration file of the operator and holds the configuration in use
+package config
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"redis.operator/pkg/kube/dns"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	APIVersion = "config.redis.operator/v1alpha1"
+	Kind       = "OperatorConfig"
+)
+
+// Feature gates, every gate has a default in defaultFeatureGates
+const (
+	// FeatureWebhooks serves the validating and defaulting webhooks. Changes need a restart
+	FeatureWebhooks = "Webhooks"
+	// FeatureSplitBrainResolution fences and demotes stale masters when several masters of a replication accepted writes
+	FeatureSplitBrainResolution = "SplitBrainResolution"
+)
+
+var defaultFeatureGates = map[string]bool{
+	FeatureWebhooks:             true,
+	FeatureSplitBrainResolution: true,
+}
+
+// OperatorConfig is the content of the configuration file
+type OperatorConfig struct {
+	metav1.TypeMeta `json:",inline"`
+
+	// Namespaces watched besides the namespace of the operator. Changes need a restart
+	Namespaces []string `json:"namespaces,omitempty"`
+
+	// WatchAllNamespaces watches every namespace of the cluster instead of Namespaces. Changes need a restart
+	WatchAllNamespaces bool `json:"watchAllNamespaces,omitempty"`
+
+	// NamespaceSelector watches the namespaces whose labels match instead of Namespaces. Namespaces are picked up
+	// when they are labelled, changes of the selector need a restart
+	NamespaceSelector *metav1.LabelSelector `json:"namespaceSelector,omitempty"`
+
+	// Images of the containers created for the custom resources
+	Images Images `json:"images,omitempty"`
+
+	// DNS sets how pods are addressed
+	DNS DNS `json:"dns,omitempty"`
+
+	// Probes overrides the timings of the liveness and readiness probes of the pods
+	Probes Probes `json:"probes,omitempty"`
+
+	// Redis sets how the operator talks to the redis pods
+	Redis Redis `json:"redis,omitempty"`
+
+	// RequeueInterval is the delay between two reconciles of a healthy resource
+	RequeueInterval metav1.Duration `json:"requeueInterval,omitempty"`
+
+	// FeatureGates enables or disables optional features, keyed by the name of the feature
+	FeatureGates map[string]bool `json:"featureGates,omitempty"`
+}
+
+type Images struct {
+	Replication string `json:"replication,omitempty"`
+	Sentinel    string `json:"sentinel,omitempty"`
+	Exporter    string `json:"exporter,omitempty"`
+}
+
+type DNS struct {
+	// ClusterDomain of the pod and service addresses. Detected from /etc/resolv.conf when empty
+	ClusterDomain string `json:"clusterDomain,omitempty"`
+
+	// AnnouncePodIP makes replication and sentinel pods announce their ip instead of their hostname
+	AnnouncePodIP bool `json:"announcePodIP,omitempty"`
+}
+
+type Probes struct {
+	Liveness  ProbeTiming `json:"liveness,omitempty"`
+	Readiness ProbeTiming `json:"readiness,omitempty"`
+}
+
+// ProbeTiming overrides the timings of a probe, unset fields keep the defaults of the resource kind
+type ProbeTiming struct {
+	InitialDelaySeconds *int32 `json:"initialDelaySeconds,omitempty"`
+	TimeoutSeconds      *int32 `json:"timeoutSeconds,omitempty"`
+	PeriodSeconds       *int32 `json:"periodSeconds,omitempty"`
+	FailureThreshold    *int32 `json:"failureThreshold,omitempty"`
+}
+
+type Redis struct {
+	// ProbeTimeout bounds the queries sent to every pod of a resource on each reconcile
+	ProbeTimeout metav1.Duration `json:"probeTimeout,omitempty"`
+}
+
+// DNSOverrides replaces the dns settings of every configuration passed to Set, usually from command line flags.
+// Nil fields keep the configured value
+type DNSOverrides struct {
+	ClusterDomain *string
+	AnnouncePodIP *bool
+}
+
+var (
+	mu           sync.RWMutex
+	current      *OperatorConfig
+	dnsOverrides DNSOverrides
+)
+
+// NewDefault returns the configuration used without a configuration file. The environment variables read by
+// earlier versions of the operator take precedence over the built-in defaults
+func NewDefault() *OperatorConfig {
+	config := &OperatorConfig{
+		TypeMeta: metav1.TypeMeta{APIVersion: APIVersion, Kind: Kind},
+		Images: Images{
+			Replication: getEnv("REPLICA_IMAGE", "redis:7.4.0"),
+			Sentinel:    getEnv("SENTINEL_IMAGE", "redis:7.4.0"),
+			Exporter:    getEnv("EXPORTER_IMAGE", "oliver006/redis_exporter:v1.63.0"),
+		},
+		DNS: DNS{
+			ClusterDomain: os.Getenv("CLUSTER_DOMAIN"),
+			AnnouncePodIP: os.Getenv("ANNOUNCE_POD_IP") == "true",
+		},
+		Redis: Redis{
+			ProbeTimeout: metav1.Duration{Duration: time.Second},
+		},
+		RequeueInterval: metav1.Duration{Duration: time.Second},
+		FeatureGates:    map[string]bool{},
+	}
+
+	if namespaces := os.Getenv("NAMESPACES"); namespaces != "" {
+		for _, nsSplit := range strings.Split(namespaces, ",") {
+			if namespace := strings.ReplaceAll(nsSplit, " ", ""); namespace != "" {
+				config.Namespaces = append(config.Namespaces, namespace)
+			}
+		}
+	}
+
+	for feature, enabled := range defaultFeatureGates {
+		config.FeatureGates[feature] = enabled
+	}
+	if os.Getenv("ENABLE_WEBHOOKS") == "false" {
+		config.FeatureGates[FeatureWebhooks] = false
+	}
+	return config
+}
+
+func getEnv(name string, defaultValue string) string {
+	if value, found := os.LookupEnv(name); found && value != "" {
+		return value
+	}
+	return defaultValue
+}
+
+// Parse reads a configuration file over the defaults, the settings missing from the file keep their default
+func Parse(data []byte) (*OperatorConfig, error) {
+	config := NewDefault()
+	config.TypeMeta = metav1.TypeMeta{}
+	if err := yaml.UnmarshalStrict(data, config); err != nil {
+		return nil, err
+	}
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+	return config, nil
+}
+
+// Load parses the configuration file at path
+func Load(path string) (*OperatorConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	config, err := Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("invalid configuration file %s: %v", path, err)
+	}
+	return config, nil
+}
+
+func (c *OperatorConfig) Validate() error {
+	if c.APIVersion != APIVersion || c.Kind != Kind {
+		return fmt.Errorf("expected apiVersion %s and kind %s, got %s and %s", APIVersion, Kind, c.APIVersion, c.Kind)
+	}
+
+	for _, namespace := range c.Namespaces {
+		if namespace == "" || strings.ContainsAny(namespace, " ,") {
+			return fmt.Errorf("invalid namespace %q", namespace)
+		}
+	}
+
+	if c.WatchAllNamespaces && c.NamespaceSelector != nil {
+		return fmt.Errorf("watchAllNamespaces and namespaceSelector are mutually exclusive")
+	}
+	if c.NamespaceSelector != nil {
+		if _, err := metav1.LabelSelectorAsSelector(c.NamespaceSelector); err != nil {
+			return fmt.Errorf("invalid namespaceSelector: %v", err)
+		}
+	}
+
+	if c.Images.Replication == "" || c.Images.Sentinel == "" || c.Images.Exporter == "" {
+		return fmt.Errorf("images.replication, images.sentinel and images.exporter must be set")
+	}
+
+	if strings.ContainsAny(c.DNS.ClusterDomain, " /:") {
+		return fmt.Errorf("invalid cluster domain %q", c.DNS.ClusterDomain)
+	}
+
+	if err := c.Probes.Liveness.validate(); err != nil {
+		return fmt.Errorf("probes.liveness: %v", err)
+	}
+	if err := c.Probes.Readiness.validate(); err != nil {
+		return fmt.Errorf("probes.readiness: %v", err)
+	}
+
+	if c.Redis.ProbeTimeout.Duration <= 0 {
+		return fmt.Errorf("redis.probeTimeout must be positive")
+	}
+	if c.RequeueInterval.Duration <= 0 {
+		return fmt.Errorf("requeueInterval must be positive")
+	}
+
+	for feature := range c.FeatureGates {
+		if _, ok := defaultFeatureGates[feature]; !ok {
+			return fmt.Errorf("unknown feature gate %s", feature)
+		}
+	}
+	return nil
+}
+
+func (p ProbeTiming) validate() error {
+	if p.InitialDelaySeconds != nil && *p.InitialDelaySeconds < 0 {
+		return fmt.Errorf("initialDelaySeconds must not be negative")
+	}
+	for name, value := range map[string]*int32{
+		"timeoutSeconds":   p.TimeoutSeconds,
+		"periodSeconds":    p.PeriodSeconds,
+		"failureThreshold": p.FailureThreshold,
+	} {
+		if value != nil && *value < 1 {
+			return fmt.Errorf("%s must be at least 1", name)
+		}
+	}
+	return nil
+}
+
+// IsEnabled returns whether feature is enabled, features missing from the configuration are disabled
+func (c *OperatorConfig) IsEnabled(feature string) bool {
+	return c.FeatureGates[feature]
+}
+
+// IsClusterScoped returns whether the operator watches namespaces beyond a fixed list, its cache and permissions
+// then span the cluster
+func (c *OperatorConfig) IsClusterScoped() bool {
+	return c.WatchAllNamespaces || c.NamespaceSelector != nil
+}
+
+// Get returns the configuration in use, the default configuration until Set is called. The result must not be modified
+func Get() *OperatorConfig {
+	mu.RLock()
+	defer mu.RUnlock()
+
+	if current == nil {
+		return NewDefault()
+	}
+	return current
+}
+
+// SetDNSOverrides sets the dns settings taking precedence over the configuration, they apply from the next Set
+func SetDNSOverrides(overrides DNSOverrides) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	dnsOverrides = overrides
+}
+
+// Set replaces the configuration in use and applies its dns settings, after the overrides. The cluster domain is
+// detected from /etc/resolv.conf when the configuration doesn't set one. A failed detection falls back to the default
+// domain and is returned as an error, the configuration is set anyway
+func Set(config *OperatorConfig) error {
+	mu.Lock()
+	if dnsOverrides.ClusterDomain != nil {
+		config.DNS.ClusterDomain = *dnsOverrides.ClusterDomain
+	}
+	if dnsOverrides.AnnouncePodIP != nil {
+		config.DNS.AnnouncePodIP = *dnsOverrides.AnnouncePodIP
+	}
+	current = config
+	mu.Unlock()
+
+	dns.SetAnnouncePodIP(config.DNS.AnnouncePodIP)
+
+	clusterDomain := config.DNS.ClusterDomain
+	if clusterDomain == "" {
+		detected, err := dns.DetectClusterDomain("/etc/resolv.conf")
+		if err != nil {
+			dns.SetClusterDomain("")
+			return fmt.Errorf("unable to detect the cluster domain, using %s: %v", dns.DefaultClusterDomain, err)
+		}
+		clusterDomain = detected
+	}
+	dns.SetClusterDomain(clusterDomain)
+	return nil
+}
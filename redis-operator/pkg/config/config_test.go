@@ -0,0 +1,120 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+)
+
+func TestParse(t *testing.T) {
+	config, err := Parse([]byte(`
+apiVersion: config.redis.operator/v1alpha1
+kind: OperatorConfig
+images:
+  sentinel: redis:7.2
+probes:
+  readiness:
+    periodSeconds: 20
+requeueInterval: 30s
+featureGates:
+  SplitBrainResolution: false
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if config.Images.Sentinel != "redis:7.2" || config.Images.Exporter == "" {
+		t.Errorf("expected the sentinel image to be overridden and the exporter image defaulted, got %+v", config.Images)
+	}
+	if config.Probes.Readiness.PeriodSeconds == nil || *config.Probes.Readiness.PeriodSeconds != 20 || config.Probes.Readiness.TimeoutSeconds != nil {
+		t.Errorf("expected only the readiness period to be overridden, got %+v", config.Probes.Readiness)
+	}
+	if config.RequeueInterval.Duration != 30*time.Second || config.Redis.ProbeTimeout.Duration != time.Second {
+		t.Errorf("unexpected durations %v and %v", config.RequeueInterval.Duration, config.Redis.ProbeTimeout.Duration)
+	}
+	if config.IsEnabled(FeatureSplitBrainResolution) || !config.IsEnabled(FeatureWebhooks) {
+		t.Errorf("unexpected feature gates %v", config.FeatureGates)
+	}
+
+	for name, data := range map[string]string{
+		"missing kind":       "apiVersion: config.redis.operator/v1alpha1\n",
+		"unknown field":      "apiVersion: config.redis.operator/v1alpha1\nkind: OperatorConfig\nimage: redis\n",
+		"unknown gate":       "apiVersion: config.redis.operator/v1alpha1\nkind: OperatorConfig\nfeatureGates:\n  Unknown: true\n",
+		"negative timeout":   "apiVersion: config.redis.operator/v1alpha1\nkind: OperatorConfig\nprobes:\n  liveness:\n    timeoutSeconds: 0\n",
+		"zero requeue":       "apiVersion: config.redis.operator/v1alpha1\nkind: OperatorConfig\nrequeueInterval: 0s\n",
+		"invalid domain":     "apiVersion: config.redis.operator/v1alpha1\nkind: OperatorConfig\ndns:\n  clusterDomain: a b\n",
+		"empty image":        "apiVersion: config.redis.operator/v1alpha1\nkind: OperatorConfig\nimages:\n  replication: \"\"\n",
+		"both scopes":        "apiVersion: config.redis.operator/v1alpha1\nkind: OperatorConfig\nwatchAllNamespaces: true\nnamespaceSelector:\n  matchLabels:\n    a: b\n",
+		"invalid selector":   "apiVersion: config.redis.operator/v1alpha1\nkind: OperatorConfig\nnamespaceSelector:\n  matchExpressions:\n  - key: a\n    operator: Bad\n",
+		"namespace with a ,": "apiVersion: config.redis.operator/v1alpha1\nkind: OperatorConfig\nnamespaces: [\"a,b\"]\n",
+	} {
+		if _, err := Parse([]byte(data)); err == nil {
+			t.Errorf("expected %s to be rejected", name)
+		}
+	}
+}
+
+func TestLoadSample(t *testing.T) {
+	if _, err := Load("../../config/manager/operator_config.yaml"); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestWatcherReload(t *testing.T) {
+	defer func() {
+		mu.Lock()
+		current = nil
+		mu.Unlock()
+	}()
+
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	write := func(data string) {
+		if err := os.WriteFile(path, []byte("apiVersion: config.redis.operator/v1alpha1\nkind: OperatorConfig\n"+data), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	write("requeueInterval: 5s\n")
+	watcher, err := NewWatcher(path, time.Second, logr.Discard())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	write("requeueInterval: 10s\n")
+	watcher.reload()
+	if interval := Get().RequeueInterval.Duration; interval != 10*time.Second {
+		t.Fatalf("expected the configuration to be reloaded, got a requeue interval of %v", interval)
+	}
+
+	write("requeueInterval: -1s\n")
+	watcher.reload()
+	if interval := Get().RequeueInterval.Duration; interval != 10*time.Second {
+		t.Errorf("expected the invalid configuration to be ignored, got a requeue interval of %v", interval)
+	}
+}
+
+func TestSetDNSOverrides(t *testing.T) {
+	defer func() {
+		mu.Lock()
+		current = nil
+		dnsOverrides = DNSOverrides{}
+		mu.Unlock()
+	}()
+
+	domain := "example.local"
+	announce := true
+	SetDNSOverrides(DNSOverrides{ClusterDomain: &domain, AnnouncePodIP: &announce})
+
+	for _, configured := range []string{"cluster.local", "other.local"} {
+		config := NewDefault()
+		config.DNS.ClusterDomain = configured
+		if err := Set(config); err != nil {
+			t.Fatal(err)
+		}
+		if dns := Get().DNS; dns.ClusterDomain != domain || !dns.AnnouncePodIP {
+			t.Errorf("expected the overrides to replace the configured dns settings, got %+v", dns)
+		}
+	}
+}
@@ -0,0 +1,82 @@
+package config
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"reflect"
+	"time"
+
+	"github.com/go-logr/logr"
+)
+
+// Watcher reloads the configuration file when its content changes. Invalid files are reported and the previous
+// configuration stays in use. Files mounted from a configmap are replaced through a symlink, so the content is
+// polled rather than watched
+type Watcher struct {
+	Path     string
+	Interval time.Duration
+	Log      logr.Logger
+
+	data []byte
+}
+
+func NewWatcher(path string, interval time.Duration, logger logr.Logger) (*Watcher, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return &Watcher{Path: path, Interval: interval, Log: logger, data: data}, nil
+}
+
+// Start polls the file until ctx is done, it implements manager.Runnable
+func (w *Watcher) Start(ctx context.Context) error {
+	ticker := time.NewTicker(w.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			w.reload()
+		}
+	}
+}
+
+// NeedLeaderElection returns false, every replica of the operator keeps its configuration up to date
+func (w *Watcher) NeedLeaderElection() bool {
+	return false
+}
+
+func (w *Watcher) reload() {
+	data, err := os.ReadFile(w.Path)
+	if err != nil {
+		w.Log.Error(err, "failed to read the configuration file", "path", w.Path)
+		return
+	}
+	if bytes.Equal(data, w.data) {
+		return
+	}
+	w.data = data
+
+	config, err := Parse(data)
+	if err != nil {
+		w.Log.Error(err, "ignoring invalid configuration file", "path", w.Path)
+		return
+	}
+
+	previous := Get()
+	if !reflect.DeepEqual(previous.Namespaces, config.Namespaces) || previous.WatchAllNamespaces != config.WatchAllNamespaces ||
+		!reflect.DeepEqual(previous.NamespaceSelector, config.NamespaceSelector) {
+		w.Log.Info("the watched namespaces changed, restart the operator to apply them", "namespaces", config.Namespaces)
+	}
+	if previous.IsEnabled(FeatureWebhooks) != config.IsEnabled(FeatureWebhooks) {
+		w.Log.Info("the webhooks feature gate changed, restart the operator to apply it")
+	}
+
+	if err := Set(config); err != nil {
+		w.Log.Error(err, "failed to apply the dns settings")
+	}
+	w.Log.Info("reloaded the configuration file", "path", w.Path)
+}
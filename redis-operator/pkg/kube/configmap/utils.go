@@ -29,6 +29,49 @@ func UpdateConfigMapKey(configMap *v1.ConfigMap, key string, substr string, newS
 	return false
 }
 
+// SetConfigMapDirective replaces the line whose first field is name, or appends it when missing.
+// Unlike UpdateConfigMapKey, "port" does not match "tls-port"
+func SetConfigMapDirective(configMap *v1.ConfigMap, key string, name string, value string) bool {
+	foundValue, ok := configMap.Data[key]
+	if !ok {
+		return false
+	}
+
+	lines := strings.Split(foundValue, "\n")
+	found := false
+	for i, line := range lines {
+		fields := strings.Fields(line)
+		if len(fields) > 0 && fields[0] == name {
+			lines[i] = name + " " + value
+			found = true
+		}
+	}
+	if !found {
+		lines = append(lines, name+" "+value)
+	}
+
+	configMap.Data[key] = strings.Join(lines, "\n")
+	return true
+}
+
+// RemoveConfigMapLines drops the lines whose fields match
+func RemoveConfigMapLines(configMap *v1.ConfigMap, key string, match func(fields []string) bool) bool {
+	foundValue, ok := configMap.Data[key]
+	if !ok {
+		return false
+	}
+
+	lines := []string{}
+	for _, line := range strings.Split(foundValue, "\n") {
+		if !match(strings.Fields(line)) {
+			lines = append(lines, line)
+		}
+	}
+
+	configMap.Data[key] = strings.Join(lines, "\n")
+	return true
+}
+
 func AddConfigMapKey(configMap *v1.ConfigMap, key string, newSubStr string) bool {
 	foundValue, ok := configMap.Data[key]
 	if !ok {
@@ -1,36 +1,21 @@
 package container
 
 import (
-	"os"
-
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
+	"redis.operator/pkg/config"
 )
 
 func GetRedisReplicationImage() string {
-
-	var envVariable = "REPLICA_IMAGE"
-	if image, found := os.LookupEnv(envVariable); found {
-		return image
-	}
-	panic("didn't find replication image")
+	return config.Get().Images.Replication
 }
 
 func GetRedisExporterImage() string {
-	var envVariable = "EXPORTER_IMAGE"
-	if image, found := os.LookupEnv(envVariable); found {
-		return image
-	}
-	panic("didn't find exporter image")
+	return config.Get().Images.Exporter
 }
 
 func GetRedisSentinelImage() string {
-
-	var envVariable = "SENTINEL_IMAGE"
-	if image, found := os.LookupEnv(envVariable); found {
-		return image
-	}
-	panic("didn't find sentinel image")
+	return config.Get().Images.Sentinel
 }
 
 type Builder struct {
@@ -0,0 +1,61 @@
+package custom
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	v1 "redis.operator/api/v1"
+	"redis.operator/pkg/kube/dns"
+)
+
+// ServiceDNSNames returns the SANs needed to reach the pods and services of an instance
+func ServiceDNSNames(name string, namespace string) []string {
+	headless := dns.GetServiceDNS(name+"-headless", namespace)
+	service := dns.GetServiceDNS(name+"-service", namespace)
+	return []string{
+		"*." + headless,
+		"*." + service,
+		headless,
+		service,
+	}
+}
+
+// NewCertificate returns a cert-manager Certificate issued by tlsConfig.IssuerRef
+func NewCertificate(name string, namespace string, labels map[string]string, owner metav1.OwnerReference, secretName string, dnsNames []string, tlsConfig *v1.RedisTLSConfiguration) *unstructured.Unstructured {
+	issuerRef := tlsConfig.IssuerRef
+
+	kind := issuerRef.Kind
+	if kind == "" {
+		kind = "Issuer"
+	}
+	group := issuerRef.Group
+	if group == "" {
+		group = "cert-manager.io"
+	}
+
+	sans := []interface{}{}
+	for _, dnsName := range dnsNames {
+		sans = append(sans, dnsName)
+	}
+
+	spec := map[string]interface{}{
+		"secretName": secretName,
+		"dnsNames":   sans,
+		"usages": []interface{}{
+			"server auth",
+			"client auth",
+		},
+		"issuerRef": map[string]interface{}{
+			"name":  issuerRef.Name,
+			"kind":  kind,
+			"group": group,
+		},
+	}
+	if tlsConfig.Duration != nil {
+		spec["duration"] = tlsConfig.Duration.Duration.String()
+	}
+	if tlsConfig.RenewBefore != nil {
+		spec["renewBefore"] = tlsConfig.RenewBefore.Duration.String()
+	}
+
+	return NewObject("cert-manager.io/v1", "Certificate", name, namespace, labels, owner, spec)
+}
@@ -0,0 +1,129 @@
+package custom
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/dynamic"
+)
+
+var (
+	ServiceMonitorResource = schema.GroupVersionResource{
+		Group:    "monitoring.coreos.com",
+		Version:  "v1",
+		Resource: "servicemonitors",
+	}
+	PrometheusRuleResource = schema.GroupVersionResource{
+		Group:    "monitoring.coreos.com",
+		Version:  "v1",
+		Resource: "prometheusrules",
+	}
+	CertificateResource = schema.GroupVersionResource{
+		Group:    "cert-manager.io",
+		Version:  "v1",
+		Resource: "certificates",
+	}
+)
+
+// discovery results are cached so optional CRDs aren't looked up on every reconcile
+const discoveryTTL = time.Minute
+
+type discoveryEntry struct {
+	resources map[string]bool
+	expires   time.Time
+}
+
+var (
+	discoveryLock  sync.Mutex
+	discoveryCache = map[string]discoveryEntry{}
+)
+
+// HasResource returns true when the API server serves the resource. Used to skip optional CRDs that are not installed
+func HasResource(client discovery.DiscoveryInterface, gvr schema.GroupVersionResource) (bool, error) {
+	groupVersion := gvr.GroupVersion().String()
+
+	discoveryLock.Lock()
+	defer discoveryLock.Unlock()
+
+	if entry, ok := discoveryCache[groupVersion]; ok && time.Now().Before(entry.expires) {
+		return entry.resources[gvr.Resource], nil
+	}
+
+	resources := map[string]bool{}
+	resourceList, err := client.ServerResourcesForGroupVersion(groupVersion)
+	if err != nil && !apierrors.IsNotFound(err) {
+		return false, err
+	}
+	if resourceList != nil {
+		for _, resource := range resourceList.APIResources {
+			resources[resource.Name] = true
+		}
+	}
+
+	discoveryCache[groupVersion] = discoveryEntry{resources: resources, expires: time.Now().Add(discoveryTTL)}
+	return resources[gvr.Resource], nil
+}
+
+// NewObject returns a namespaced custom resource owned by owner
+func NewObject(apiVersion string, kind string, name string, namespace string, labels map[string]string, owner metav1.OwnerReference, spec map[string]interface{}) *unstructured.Unstructured {
+	objectLabels := map[string]interface{}{}
+	for key, value := range labels {
+		objectLabels[key] = value
+	}
+
+	return &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": apiVersion,
+		"kind":       kind,
+		"metadata": map[string]interface{}{
+			"name":      name,
+			"namespace": namespace,
+			"labels":    objectLabels,
+			"ownerReferences": []interface{}{
+				map[string]interface{}{
+					"apiVersion": owner.APIVersion,
+					"kind":       owner.Kind,
+					"name":       owner.Name,
+					"uid":        string(owner.UID),
+					"controller": true,
+				},
+			},
+		},
+		"spec": spec,
+	}}
+}
+
+// CreateOrUpdate creates the object or replaces the spec of an existing one. Returns true if the object was created
+func CreateOrUpdate(ctx context.Context, client dynamic.Interface, gvr schema.GroupVersionResource, obj *unstructured.Unstructured) (bool, error) {
+	resourceClient := client.Resource(gvr).Namespace(obj.GetNamespace())
+
+	current, err := resourceClient.Get(ctx, obj.GetName(), metav1.GetOptions{})
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			_, err = resourceClient.Create(ctx, obj, metav1.CreateOptions{})
+			return err == nil, err
+		}
+		return false, err
+	}
+
+	obj.SetResourceVersion(current.GetResourceVersion())
+	_, err = resourceClient.Update(ctx, obj, metav1.UpdateOptions{})
+	return false, err
+}
+
+// Delete removes the object if it exists. Returns true if an object was deleted
+func Delete(ctx context.Context, client dynamic.Interface, gvr schema.GroupVersionResource, namespace string, name string) (bool, error) {
+	err := client.Resource(gvr).Namespace(namespace).Delete(ctx, name, metav1.DeleteOptions{})
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
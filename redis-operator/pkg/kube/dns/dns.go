@@ -0,0 +1,87 @@
+// Package dns builds the addresses of services and pods under the cluster domain of the operator
+package dns
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+)
+
+const DefaultClusterDomain = "cluster.local"
+
+var (
+	mu            sync.RWMutex
+	clusterDomain = DefaultClusterDomain
+	announcePodIP = false
+)
+
+// SetClusterDomain sets the domain of every address built by this package, the default domain when empty
+func SetClusterDomain(domain string) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	clusterDomain = strings.Trim(domain, ".")
+	if clusterDomain == "" {
+		clusterDomain = DefaultClusterDomain
+	}
+}
+
+func GetClusterDomain() string {
+	mu.RLock()
+	defer mu.RUnlock()
+
+	return clusterDomain
+}
+
+// SetAnnouncePodIP makes redis and sentinel pods announce their ip instead of their hostname. Cluster nodes always
+// announce their hostname, the operator matches them by it
+func SetAnnouncePodIP(announce bool) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	announcePodIP = announce
+}
+
+func IsAnnouncingPodIP() bool {
+	mu.RLock()
+	defer mu.RUnlock()
+
+	return announcePodIP
+}
+
+// DetectClusterDomain returns the cluster domain found in the search domains of resolv.conf, the entry following
+// "svc.". Empty when no search domain matches
+func DetectClusterDomain(resolvConf string) (string, error) {
+	file, err := os.Open(resolvConf)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 || fields[0] != "search" {
+			continue
+		}
+		for _, domain := range fields[1:] {
+			if strings.HasPrefix(domain, "svc.") {
+				return strings.Trim(strings.TrimPrefix(domain, "svc."), "."), nil
+			}
+		}
+	}
+	return "", scanner.Err()
+}
+
+// GetServiceDNS returns the address of a service
+func GetServiceDNS(service string, namespace string) string {
+	return fmt.Sprintf("%s.%s.svc.%s", service, namespace, GetClusterDomain())
+}
+
+// GetPodDNS returns the address of a pod behind a headless service. pod may be a shell or env variable reference
+// like ${POD_NAME}
+func GetPodDNS(pod string, headlessService string, namespace string) string {
+	return pod + "." + GetServiceDNS(headlessService, namespace)
+}
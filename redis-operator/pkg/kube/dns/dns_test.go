@@ -0,0 +1,45 @@
+package dns
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestDetectClusterDomain(t *testing.T) {
+	tests := []struct {
+		resolvConf string
+		domain     string
+	}{
+		{"search default.svc.example.org svc.example.org example.org\nnameserver 10.96.0.10\noptions ndots:5\n", "example.org"},
+		{"nameserver 10.96.0.10\nsearch svc.cluster.local.\n", "cluster.local"},
+		{"search example.org\nnameserver 8.8.8.8\n", ""},
+	}
+
+	for _, test := range tests {
+		path := filepath.Join(t.TempDir(), "resolv.conf")
+		if err := os.WriteFile(path, []byte(test.resolvConf), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		domain, err := DetectClusterDomain(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if domain != test.domain {
+			t.Errorf("expected %q from %q, got %q", test.domain, test.resolvConf, domain)
+		}
+	}
+}
+
+func TestGetPodDNS(t *testing.T) {
+	defer SetClusterDomain("")
+
+	if dns := GetPodDNS("redis-0", "redis-headless", "default"); dns != "redis-0.redis-headless.default.svc.cluster.local" {
+		t.Errorf("unexpected default address %s", dns)
+	}
+
+	SetClusterDomain("example.org.")
+	if dns := GetPodDNS("${POD_NAME}", "redis-headless", "default"); dns != "${POD_NAME}.redis-headless.default.svc.example.org" {
+		t.Errorf("unexpected address %s", dns)
+	}
+}
@@ -4,6 +4,7 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/util/intstr"
 	"k8s.io/utils/ptr"
+	"redis.operator/pkg/config"
 )
 
 type Builder struct {
@@ -74,6 +75,23 @@ func (b *Builder) SetGRPCAction(port int32, service string) *Builder {
 	return b
 }
 
+// SetTiming overrides the timings set by timing, the unset ones are kept
+func (b *Builder) SetTiming(timing config.ProbeTiming) *Builder {
+	if timing.InitialDelaySeconds != nil {
+		b.probe.InitialDelaySeconds = *timing.InitialDelaySeconds
+	}
+	if timing.TimeoutSeconds != nil {
+		b.probe.TimeoutSeconds = *timing.TimeoutSeconds
+	}
+	if timing.PeriodSeconds != nil {
+		b.probe.PeriodSeconds = *timing.PeriodSeconds
+	}
+	if timing.FailureThreshold != nil {
+		b.probe.FailureThreshold = *timing.FailureThreshold
+	}
+	return b
+}
+
 func (b *Builder) Build() *corev1.Probe {
 	return &b.probe
 }
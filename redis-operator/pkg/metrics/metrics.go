@@ -0,0 +1,87 @@
+package metrics
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+const (
+	StepService     = "service"
+	StepConfigMap   = "configmap"
+	StepStatefulSet = "statefulset"
+	StepMonitoring  = "monitoring"
+	StepMaster      = "master"
+	StepSentinel    = "sentinel"
+	StepCluster     = "cluster"
+	StepResharding  = "resharding"
+)
+
+// kinds of the instances the series belong to, resources of different kinds may share a name
+const (
+	KindReplication = "RedisReplication"
+	KindSentinel    = "RedisSentinel"
+	KindCluster     = "RedisCluster"
+)
+
+var (
+	MasterChanges = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "redis_operator_master_changes_total",
+		Help: "Number of times the operator promoted a new master",
+	}, []string{"kind", "namespace", "name"})
+
+	FailoverDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "redis_operator_failover_duration_seconds",
+		Help:    "Time between the operator detecting a missing or duplicate master and a single master being restored",
+		Buckets: prometheus.ExponentialBuckets(1, 2, 10),
+	}, []string{"kind", "namespace", "name"})
+
+	ReplicaLag = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "redis_operator_replica_lag_bytes",
+		Help: "Replication offset difference between the master and a replica",
+	}, []string{"kind", "namespace", "name", "pod"})
+
+	SentinelAgreement = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "redis_operator_sentinel_agreement",
+		Help: "Number of sentinels agreeing on the current master of a monitored master name",
+	}, []string{"kind", "namespace", "name", "master"})
+
+	ReconcileStepDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "redis_operator_reconcile_step_duration_seconds",
+		Help:    "Latency of the individual reconcile steps",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"kind", "namespace", "name", "step"})
+
+	RedisConnectionErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "redis_operator_redis_connection_errors_total",
+		Help: "Number of failed connections to redis and sentinel pods",
+	}, []string{"kind", "namespace", "name"})
+)
+
+func init() {
+	metrics.Registry.MustRegister(
+		MasterChanges,
+		FailoverDuration,
+		ReplicaLag,
+		SentinelAgreement,
+		ReconcileStepDuration,
+		RedisConnectionErrors,
+	)
+}
+
+// ObserveStep records the time taken by a reconcile step since start
+func ObserveStep(kind string, namespace string, name string, step string, start time.Time) {
+	ReconcileStepDuration.WithLabelValues(kind, namespace, name, step).Observe(time.Since(start).Seconds())
+}
+
+// DeleteInstanceMetrics removes every series belonging to a deleted instance
+func DeleteInstanceMetrics(kind string, namespace string, name string) {
+	labels := prometheus.Labels{"kind": kind, "namespace": namespace, "name": name}
+	MasterChanges.DeletePartialMatch(labels)
+	FailoverDuration.DeletePartialMatch(labels)
+	ReplicaLag.DeletePartialMatch(labels)
+	SentinelAgreement.DeletePartialMatch(labels)
+	ReconcileStepDuration.DeletePartialMatch(labels)
+	RedisConnectionErrors.DeletePartialMatch(labels)
+}
@@ -0,0 +1,21 @@
+package metrics
+
+import (
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestDeleteInstanceMetrics(t *testing.T) {
+	RedisConnectionErrors.WithLabelValues(KindReplication, "default", "redis").Inc()
+	RedisConnectionErrors.WithLabelValues(KindSentinel, "default", "redis").Inc()
+
+	DeleteInstanceMetrics(KindSentinel, "default", "redis")
+
+	if count := testutil.CollectAndCount(RedisConnectionErrors); count != 1 {
+		t.Fatalf("expected only the series of the replication to remain, got %d series", count)
+	}
+	if value := testutil.ToFloat64(RedisConnectionErrors.WithLabelValues(KindReplication, "default", "redis")); value != 1 {
+		t.Errorf("expected the replication series to be kept, got %v", value)
+	}
+}
@@ -0,0 +1,265 @@
+package k8sredis
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"redis.operator/pkg/config"
+	"redis.operator/pkg/kube/dns"
+)
+
+const (
+	// pods probed at once by a single call
+	maxConcurrentProbes = 8
+	// clients not used for this long are closed
+	clientIdleTimeout = 10 * time.Minute
+	// read and write timeout of cached clients, calls are bounded by the deadline of their context first
+	clientTimeout = 5 * time.Second
+)
+
+// returns the time a pod has to answer a probe
+func getProbeTimeout() time.Duration {
+	return config.Get().Redis.ProbeTimeout.Duration
+}
+
+// clients are shared by every reconcile, so connections to a pod are reused instead of dialed on every call
+var clients = newClientCache()
+
+type cachedClient struct {
+	client    *redis.Client
+	sentinel  *redis.SentinelClient
+	tlsConfig *tls.Config
+	password  string
+	lastUsed  time.Time
+	// users counts the calls holding the client, a retired client is closed once the last of them released it
+	users   int
+	retired bool
+}
+
+func (c *cachedClient) Close() error {
+	if c.sentinel != nil {
+		return c.sentinel.Close()
+	}
+	return c.client.Close()
+}
+
+// clientCache keeps a client per address. A client is replaced when the credentials given for its address change
+// and dropped when a call through it fails, so restarted pods are dialed again. Clients are handed out with a
+// release func, a replaced or dropped client is only closed after every call using it released it
+type clientCache struct {
+	mu      sync.Mutex
+	clients map[string]*cachedClient
+}
+
+func newClientCache() *clientCache {
+	return &clientCache{clients: map[string]*cachedClient{}}
+}
+
+// sameTLSConfig compares the settings of the tls configs built by GetTLSConfig
+func sameTLSConfig(a *tls.Config, b *tls.Config) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	if a.MinVersion != b.MinVersion || !reflect.DeepEqual(a.CipherSuites, b.CipherSuites) || len(a.Certificates) != len(b.Certificates) {
+		return false
+	}
+	if (a.RootCAs == nil) != (b.RootCAs == nil) || (a.RootCAs != nil && !a.RootCAs.Equal(b.RootCAs)) {
+		return false
+	}
+	for i := range a.Certificates {
+		if len(a.Certificates[i].Certificate) != len(b.Certificates[i].Certificate) {
+			return false
+		}
+		for j := range a.Certificates[i].Certificate {
+			if !bytes.Equal(a.Certificates[i].Certificate[j], b.Certificates[i].Certificate[j]) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// retire drops the client of key from the cache and closes it unless a call is still using it. c.mu must be held
+func (c *clientCache) retire(key string, cached *cachedClient) {
+	delete(c.clients, key)
+	cached.retired = true
+	if cached.users == 0 {
+		cached.Close()
+	}
+}
+
+// release ends a use of cached, a client retired meanwhile is closed by its last user
+func (c *clientCache) release(cached *cachedClient) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	cached.users--
+	cached.lastUsed = time.Now()
+	if cached.retired && cached.users == 0 {
+		cached.Close()
+	}
+}
+
+func (c *clientCache) get(key string, tlsConfig *tls.Config, password string, create func() *cachedClient) (*cachedClient, func()) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	for other, cached := range c.clients {
+		if cached.users == 0 && now.Sub(cached.lastUsed) > clientIdleTimeout {
+			c.retire(other, cached)
+		}
+	}
+
+	cached, ok := c.clients[key]
+	if ok && (cached.password != password || !sameTLSConfig(cached.tlsConfig, tlsConfig)) {
+		c.retire(key, cached)
+		ok = false
+	}
+	if !ok {
+		cached = create()
+		cached.tlsConfig = tlsConfig
+		cached.password = password
+		c.clients[key] = cached
+	}
+	cached.lastUsed = now
+	cached.users++
+	return cached, func() { c.release(cached) }
+}
+
+func getClientOptions(addr string, tlsConfig *tls.Config, password string) *redis.Options {
+	return &redis.Options{
+		Addr:                  addr,
+		Password:              password,
+		DB:                    0,
+		TLSConfig:             tlsConfig,
+		ReadTimeout:           clientTimeout,
+		WriteTimeout:          clientTimeout,
+		DialTimeout:           clientTimeout,
+		ContextTimeoutEnabled: true,
+	}
+}
+
+// getClient returns the cached client of a redis pod and the func releasing it once the caller is done
+func (c *clientCache) getClient(host string, port string, tlsConfig *tls.Config, password string) (*redis.Client, func()) {
+	addr := host + ":" + port
+	cached, release := c.get("redis/"+addr, tlsConfig, password, func() *cachedClient {
+		return &cachedClient{client: redis.NewClient(getClientOptions(addr, tlsConfig, password))}
+	})
+	return cached.client, release
+}
+
+// getSentinelClient returns the cached client of a sentinel pod and the func releasing it once the caller is done
+func (c *clientCache) getSentinelClient(host string, port string, tlsConfig *tls.Config, password string) (*redis.SentinelClient, func()) {
+	addr := host + ":" + port
+	cached, release := c.get("sentinel/"+addr, tlsConfig, password, func() *cachedClient {
+		return &cachedClient{sentinel: redis.NewSentinelClient(getClientOptions(addr, tlsConfig, password))}
+	})
+	return cached.sentinel, release
+}
+
+// getMigrationClient returns the cached client of a redis pod used for MIGRATE, its read timeout covers the timeout
+// of the migration
+func (c *clientCache) getMigrationClient(host string, port string, tlsConfig *tls.Config, password string, timeout time.Duration) (*redis.Client, func()) {
+	addr := host + ":" + port
+	cached, release := c.get(fmt.Sprintf("migrate-%s/%s", timeout, addr), tlsConfig, password, func() *cachedClient {
+		options := getClientOptions(addr, tlsConfig, password)
+		options.ReadTimeout = timeout + clientTimeout
+		return &cachedClient{client: redis.NewClient(options)}
+	})
+	return cached.client, release
+}
+
+// call runs fn with the cached client of a redis pod and a context bounded by the probe timeout. The clients of
+// host are invalidated when fn fails for another reason than an error reply, so a restarted pod is dialed again
+func (c *clientCache) call(ctx context.Context, host string, port string, tlsConfig *tls.Config, password string, fn func(ctx context.Context, client *redis.Client) error) error {
+	callCtx, cancel := context.WithTimeout(ctx, getProbeTimeout())
+	defer cancel()
+
+	client, release := c.getClient(host, port, tlsConfig, password)
+	err := fn(callCtx, client)
+	release()
+	if err != nil && !isErrorReply(err) {
+		c.invalidate(host)
+	}
+	return err
+}
+
+// isErrorReply returns whether err was replied by the server, the connection is still usable then
+func isErrorReply(err error) bool {
+	var reply redis.Error
+	return errors.As(err, &reply)
+}
+
+// invalidate retires the clients of host, the next call dials it again
+func (c *clientCache) invalidate(host string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for key, cached := range c.clients {
+		if strings.HasPrefix(strings.SplitN(key, "/", 2)[1], host+":") {
+			c.retire(key, cached)
+		}
+	}
+}
+
+// InvalidateClients retires the clients of every pod behind a headless service, used once an instance is deleted
+func InvalidateClients(headlessService string, namespace string) {
+	clients.mu.Lock()
+	defer clients.mu.Unlock()
+
+	suffix := "." + dns.GetServiceDNS(headlessService, namespace) + ":"
+	for key, cached := range clients.clients {
+		if strings.Contains(key, suffix) {
+			clients.retire(key, cached)
+		}
+	}
+}
+
+// probePods runs probe for pods 0 to pods-1, at most maxConcurrentProbes at once and each within timeout. The
+// results are ordered by pod index, pods for which probe returns false are skipped. The first error is returned
+// once every probe finished
+func probePods[T any](ctx context.Context, pods int, timeout time.Duration, probe func(ctx context.Context, index int) (T, bool, error)) ([]T, error) {
+	type probeResult struct {
+		value T
+		ok    bool
+		err   error
+	}
+
+	results := make([]probeResult, pods)
+	semaphore := make(chan struct{}, maxConcurrentProbes)
+	wg := sync.WaitGroup{}
+	for i := 0; i < pods; i++ {
+		wg.Add(1)
+		go func(index int) {
+			defer wg.Done()
+			semaphore <- struct{}{}
+			defer func() { <-semaphore }()
+
+			probeCtx, cancel := context.WithTimeout(ctx, timeout)
+			defer cancel()
+			value, ok, err := probe(probeCtx, index)
+			results[index] = probeResult{value: value, ok: ok, err: err}
+		}(i)
+	}
+	wg.Wait()
+
+	values := []T{}
+	for _, result := range results {
+		if result.err != nil {
+			return nil, result.err
+		}
+		if result.ok {
+			values = append(values, result.value)
+		}
+	}
+	return values, nil
+}
@@ -0,0 +1,148 @@
+package k8sredis
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+func TestProbePods(t *testing.T) {
+	running := int32(0)
+	maxRunning := int32(0)
+	values, err := probePods(context.Background(), 20, time.Second, func(ctx context.Context, index int) (int, bool, error) {
+		current := atomic.AddInt32(&running, 1)
+		defer atomic.AddInt32(&running, -1)
+		for {
+			seen := atomic.LoadInt32(&maxRunning)
+			if current <= seen || atomic.CompareAndSwapInt32(&maxRunning, seen, current) {
+				break
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+		return index, index%2 == 0, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(values) != 10 {
+		t.Fatalf("expected 10 values, got %v", values)
+	}
+	for i, value := range values {
+		if value != i*2 {
+			t.Fatalf("expected values ordered by pod index, got %v", values)
+		}
+	}
+	if maxRunning > maxConcurrentProbes {
+		t.Errorf("expected at most %d concurrent probes, got %d", maxConcurrentProbes, maxRunning)
+	}
+
+	_, err = probePods(context.Background(), 3, 10*time.Millisecond, func(ctx context.Context, index int) (int, bool, error) {
+		if index == 1 {
+			<-ctx.Done()
+			return 0, false, fmt.Errorf("pod %d: %v", index, ctx.Err())
+		}
+		return index, true, nil
+	})
+	if err == nil {
+		t.Error("expected the error of a probe exceeding its deadline")
+	}
+}
+
+func TestClientCache(t *testing.T) {
+	cache := newClientCache()
+	host := "redis-0.redis-headless.default.svc.cluster.local"
+	getClient := func(tlsConfig *tls.Config, password string) *redis.Client {
+		client, release := cache.getClient(host, "6379", tlsConfig, password)
+		release()
+		return client
+	}
+
+	client := getClient(nil, "secret")
+	if getClient(nil, "secret") != client {
+		t.Error("expected the client to be reused")
+	}
+	if getClient(nil, "other") == client {
+		t.Error("expected a new client when the password changes")
+	}
+	client = getClient(nil, "other")
+	if getClient(&tls.Config{MinVersion: tls.VersionTLS12}, "other") == client {
+		t.Error("expected a new client when the tls config changes")
+	}
+	if !sameTLSConfig(&tls.Config{MinVersion: tls.VersionTLS12}, &tls.Config{MinVersion: tls.VersionTLS12}) {
+		t.Error("expected tls configs with the same settings to be equal")
+	}
+
+	client = getClient(nil, "other")
+	cache.invalidate(host)
+	if getClient(nil, "other") == client {
+		t.Error("expected a new client once the host is invalidated")
+	}
+}
+
+func TestClientCacheRelease(t *testing.T) {
+	cache := newClientCache()
+
+	client, release := cache.getClient("127.0.0.1", "1", nil, "")
+	cache.invalidate("127.0.0.1")
+	other, releaseOther := cache.getClient("127.0.0.1", "1", nil, "other")
+	releaseOther()
+	if other == client {
+		t.Fatal("expected a new client once the host is invalidated")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	if err := client.Ping(ctx).Err(); errors.Is(err, redis.ErrClosed) {
+		t.Error("expected a client in use to stay open when it is invalidated")
+	}
+	release()
+	if err := client.Ping(ctx).Err(); !errors.Is(err, redis.ErrClosed) {
+		t.Errorf("expected an invalidated client to be closed once released, got %v", err)
+	}
+
+	replaced, release := cache.getClient("127.0.0.1", "1", nil, "other")
+	_, releaseNew := cache.getClient("127.0.0.1", "1", nil, "new")
+	releaseNew()
+	if err := replaced.Ping(ctx).Err(); errors.Is(err, redis.ErrClosed) {
+		t.Error("expected a client in use to stay open when its credentials change")
+	}
+	release()
+	if err := replaced.Ping(ctx).Err(); !errors.Is(err, redis.ErrClosed) {
+		t.Errorf("expected a replaced client to be closed once released, got %v", err)
+	}
+}
+
+func TestClientCacheCall(t *testing.T) {
+	cache := newClientCache()
+
+	client, release := cache.getClient("127.0.0.1", "1", nil, "")
+	release()
+	err := cache.call(context.Background(), "127.0.0.1", "1", nil, "", func(ctx context.Context, client *redis.Client) error {
+		if _, ok := ctx.Deadline(); !ok {
+			t.Error("expected the call to be bounded by the probe timeout")
+		}
+		return client.Ping(ctx).Err()
+	})
+	if err == nil {
+		t.Fatal("expected the call to an unreachable pod to fail")
+	}
+	if other, release := cache.getClient("127.0.0.1", "1", nil, ""); other == client {
+		t.Error("expected the client of the unreachable pod to be invalidated")
+	} else {
+		release()
+	}
+
+	migration, releaseMigration := cache.getMigrationClient("127.0.0.1", "1", nil, "", 10*time.Second)
+	defer releaseMigration()
+	redisClient, release := cache.getClient("127.0.0.1", "1", nil, "")
+	defer release()
+	if migration == redisClient || migration.Options().ReadTimeout <= 10*time.Second {
+		t.Errorf("expected a separate migration client covering the migration timeout, got %v", migration.Options().ReadTimeout)
+	}
+}
@@ -0,0 +1,459 @@
+package k8sredis
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+
+	"github.com/go-logr/logr"
+	"github.com/redis/go-redis/v9"
+	"k8s.io/client-go/kubernetes"
+	v1 "redis.operator/api/v1"
+)
+
+// SlotRange is an inclusive range of hash slots
+type SlotRange struct {
+	Start int
+	End   int
+}
+
+func (s SlotRange) Count() int {
+	return s.End - s.Start + 1
+}
+
+func (s SlotRange) String() string {
+	if s.Start == s.End {
+		return strconv.Itoa(s.Start)
+	}
+	return fmt.Sprintf("%d-%d", s.Start, s.End)
+}
+
+// ClusterNode is a line of CLUSTER NODES, a node as seen by the node which was asked
+type ClusterNode struct {
+	ID   string
+	Addr string
+	// name announced with cluster-announce-hostname, empty on servers which don't announce one
+	Hostname  string
+	Flags     []string
+	MasterID  string
+	LinkState string
+	Slots     []SlotRange
+}
+
+func (n ClusterNode) HasFlag(flag string) bool {
+	for _, f := range n.Flags {
+		if f == flag {
+			return true
+		}
+	}
+	return false
+}
+
+func (n ClusterNode) IsMaster() bool {
+	return n.HasFlag("master")
+}
+
+// IsFailed is true once the cluster agreed the node is down. PFAIL, reported as fail?, is not enough
+func (n ClusterNode) IsFailed() bool {
+	return n.HasFlag("fail")
+}
+
+func (n ClusterNode) SlotCount() int {
+	count := 0
+	for _, slots := range n.Slots {
+		count += slots.Count()
+	}
+	return count
+}
+
+// ParseClusterNodes parses the output of CLUSTER NODES. Slots being imported or migrated are skipped
+func ParseClusterNodes(nodes string) ([]ClusterNode, error) {
+	clusterNodes := []ClusterNode{}
+
+	for _, line := range strings.Split(nodes, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		if len(fields) < 8 {
+			return nil, fmt.Errorf("invalid cluster nodes line: %s", line)
+		}
+
+		node := ClusterNode{
+			ID:        fields[0],
+			Flags:     strings.Split(fields[2], ","),
+			LinkState: fields[7],
+		}
+
+		addr := strings.SplitN(fields[1], ",", 2)
+		node.Addr = addr[0]
+		if len(addr) == 2 {
+			node.Hostname = addr[1]
+		}
+
+		if fields[3] != "-" {
+			node.MasterID = fields[3]
+		}
+
+		for _, field := range fields[8:] {
+			if strings.HasPrefix(field, "[") {
+				continue
+			}
+			bounds := strings.SplitN(field, "-", 2)
+			start, err := strconv.Atoi(bounds[0])
+			if err != nil {
+				return nil, fmt.Errorf("invalid slot %s of %s: %v", field, node.ID, err)
+			}
+			end := start
+			if len(bounds) == 2 {
+				if end, err = strconv.Atoi(bounds[1]); err != nil {
+					return nil, fmt.Errorf("invalid slot %s of %s: %v", field, node.ID, err)
+				}
+			}
+			node.Slots = append(node.Slots, SlotRange{Start: start, End: end})
+		}
+
+		clusterNodes = append(clusterNodes, node)
+	}
+	return clusterNodes, nil
+}
+
+// GetSlotRanges splits the hash slots into a contiguous range per shard
+func GetSlotRanges(shards int) []SlotRange {
+	ranges := make([]SlotRange, 0, shards)
+	for i := 0; i < shards; i++ {
+		ranges = append(ranges, SlotRange{
+			Start: i * v1.ClusterSlots / shards,
+			End:   (i+1)*v1.ClusterSlots/shards - 1,
+		})
+	}
+	return ranges
+}
+
+// ClusterNodeInfo is the view of the cluster of a reachable pod
+type ClusterNodeInfo struct {
+	DNS      string
+	Shard    int
+	PodIndex int
+	Myself   ClusterNode
+	Nodes    []ClusterNode
+	// parsed CLUSTER INFO
+	Info map[string]string
+}
+
+// Knows is true when the node has met the node with id
+func (c *ClusterNodeInfo) Knows(id string) bool {
+	for _, node := range c.Nodes {
+		if node.ID == id {
+			return true
+		}
+	}
+	return false
+}
+
+// GetSeedNode returns the reachable node knowing the most nodes, its view is used to plan the cluster
+func GetSeedNode(nodes []ClusterNodeInfo) *ClusterNodeInfo {
+	var seed *ClusterNodeInfo
+	for i := range nodes {
+		if seed == nil || len(nodes[i].Nodes) > len(seed.Nodes) {
+			seed = &nodes[i]
+		}
+	}
+	return seed
+}
+
+// GetShardMasters returns the pod serving as master of every shard, keyed by shard. A master already owning
+// slots is kept, otherwise the master with the lowest pod index is picked. Shards without a reachable master are missing
+func GetShardMasters(nodes []ClusterNodeInfo) map[int]*ClusterNodeInfo {
+	masters := map[int]*ClusterNodeInfo{}
+	for i := range nodes {
+		node := &nodes[i]
+		if !node.Myself.IsMaster() {
+			continue
+		}
+		current, ok := masters[node.Shard]
+		if !ok || (node.Myself.SlotCount() > 0 && current.Myself.SlotCount() == 0) {
+			masters[node.Shard] = node
+		}
+	}
+	return masters
+}
+
+type ClusterMeet struct {
+	From string
+	To   string
+}
+
+type ClusterSlotAssignment struct {
+	DNS   string
+	Shard int
+	Slots []SlotRange
+}
+
+type ClusterReplicate struct {
+	DNS       string
+	MasterID  string
+	MasterDNS string
+}
+
+// ClusterPlan holds the commands bringing the cluster closer to every shard owning its slots, with every
+// other pod of the shard replicating its master
+type ClusterPlan struct {
+	Meet      []ClusterMeet
+	Forget    []ClusterNode
+	AddSlots  []ClusterSlotAssignment
+	Replicate []ClusterReplicate
+}
+
+func (p *ClusterPlan) IsEmpty() bool {
+	return len(p.Meet) == 0 && len(p.Forget) == 0 && len(p.AddSlots) == 0 && len(p.Replicate) == 0
+}
+
+// PlanCluster compares the views of the reachable nodes with the desired topology. Slots and replicas are only
+// assigned once every reachable node joined, so the nodes agree on the assignment.
+//
+// Failed nodes are forgotten when they own no slots, or when their pod was replaced by a node with a new id and
+// no replica is left to take over their slots. Slots they owned are assigned again to the master of their shard
+func PlanCluster(nodes []ClusterNodeInfo, shards int) *ClusterPlan {
+	plan := &ClusterPlan{}
+
+	seed := GetSeedNode(nodes)
+	if seed == nil {
+		return plan
+	}
+
+	reachable := map[string]bool{}
+	hostnames := map[string]string{}
+	for _, node := range nodes {
+		reachable[node.Myself.ID] = true
+		hostnames[node.DNS] = node.Myself.ID
+	}
+
+	for _, node := range nodes {
+		if node.Myself.ID != seed.Myself.ID && !seed.Knows(node.Myself.ID) {
+			plan.Meet = append(plan.Meet, ClusterMeet{From: seed.DNS, To: node.DNS})
+		}
+	}
+
+	forgotten := map[string]bool{}
+	for _, known := range seed.Nodes {
+		if !known.IsFailed() || reachable[known.ID] {
+			continue
+		}
+		if known.SlotCount() > 0 {
+			if id, ok := hostnames[known.Hostname]; !ok || id == known.ID {
+				continue // may come back with its data
+			}
+			if hasLiveReplica(seed.Nodes, known.ID) {
+				continue // the cluster fails over to the replica
+			}
+		}
+		plan.Forget = append(plan.Forget, known)
+		forgotten[known.ID] = true
+	}
+
+	if len(plan.Meet) > 0 {
+		return plan
+	}
+
+	assigned := make([]bool, v1.ClusterSlots)
+	for _, known := range seed.Nodes {
+		if forgotten[known.ID] {
+			continue
+		}
+		for _, slots := range known.Slots {
+			for slot := slots.Start; slot <= slots.End && slot < v1.ClusterSlots; slot++ {
+				assigned[slot] = true
+			}
+		}
+	}
+
+	masters := GetShardMasters(nodes)
+	for shard, slots := range GetSlotRanges(shards) {
+		master, ok := masters[shard]
+		if !ok {
+			continue
+		}
+		if unassigned := getUnassignedSlots(assigned, slots); len(unassigned) > 0 {
+			plan.AddSlots = append(plan.AddSlots, ClusterSlotAssignment{DNS: master.DNS, Shard: shard, Slots: unassigned})
+		}
+	}
+
+	for _, node := range nodes {
+		master, ok := masters[node.Shard]
+		if !ok || master.Myself.ID == node.Myself.ID {
+			continue
+		}
+		if node.Myself.MasterID == master.Myself.ID {
+			continue
+		}
+		if node.Myself.IsMaster() && node.Myself.SlotCount() > 0 {
+			continue // serving slots, never demote a master holding data
+		}
+		if !node.Knows(master.Myself.ID) {
+			continue // not gossiped yet
+		}
+		plan.Replicate = append(plan.Replicate, ClusterReplicate{DNS: node.DNS, MasterID: master.Myself.ID, MasterDNS: master.DNS})
+	}
+
+	return plan
+}
+
+// hasLiveReplica is true when a node not considered failed replicates the node with id
+func hasLiveReplica(nodes []ClusterNode, id string) bool {
+	for _, node := range nodes {
+		if node.MasterID == id && !node.IsFailed() && !node.HasFlag("fail?") {
+			return true
+		}
+	}
+	return false
+}
+
+// getUnassignedSlots returns the ranges of slots which are not assigned yet
+func getUnassignedSlots(assigned []bool, slots SlotRange) []SlotRange {
+	unassigned := []SlotRange{}
+	for slot := slots.Start; slot <= slots.End; slot++ {
+		if assigned[slot] {
+			continue
+		}
+		if n := len(unassigned); n > 0 && unassigned[n-1].End == slot-1 {
+			unassigned[n-1].End = slot
+			continue
+		}
+		unassigned = append(unassigned, SlotRange{Start: slot, End: slot})
+	}
+	return unassigned
+}
+
+func getClusterCredentials(ctx context.Context, k8Client kubernetes.Interface, instance *v1.RedisCluster) (*tls.Config, string, error) {
+	var tlsConfig *tls.Config = nil
+	var err error
+
+	if instance.Spec.TLSConfig != nil {
+		if tlsConfig, err = GetTLSConfig(ctx, k8Client, instance.Namespace, instance.GetTLSSecretName(), instance.Spec.TLSConfig); err != nil {
+			return nil, "", err
+		}
+	}
+
+	password, err := instance.Spec.RedisConfig.GetValue("redis.conf", "requirepass")
+	if err != nil {
+		return nil, "", err
+	}
+	return tlsConfig, password, nil
+}
+
+// GetClusterNodes returns the view of the cluster of every reachable pod, ordered by shard and pod index. Shards
+// being removed are included
+func GetClusterNodes(ctx context.Context, k8Client kubernetes.Interface, instance *v1.RedisCluster, reqLogger logr.Logger) ([]ClusterNodeInfo, error) {
+	tlsConfig, password, err := getClusterCredentials(ctx, k8Client, instance)
+	if err != nil {
+		return nil, err
+	}
+
+	shardSize := instance.GetShardSize()
+	return probePods(ctx, instance.GetDeployedShards()*shardSize, getProbeTimeout(), func(ctx context.Context, index int) (ClusterNodeInfo, bool, error) {
+		shard, i := index/shardSize, index%shardSize
+		podDNS := instance.GetPodDNS(shard, i)
+
+		redisClient, release := clients.getClient(podDNS, instance.GetRedisPort(), tlsConfig, password)
+		defer release()
+		if result := redisClient.Ping(ctx); result.Val() != "PONG" {
+			clients.invalidate(podDNS)
+			return ClusterNodeInfo{}, false, nil // down, ignore
+		}
+
+		clusterNodes, err := redisClient.ClusterNodes(ctx).Result()
+		if err != nil {
+			return ClusterNodeInfo{}, false, fmt.Errorf("error getting cluster nodes of %s: %v", podDNS, err)
+		}
+		parsed, err := ParseClusterNodes(clusterNodes)
+		if err != nil {
+			return ClusterNodeInfo{}, false, err
+		}
+
+		clusterInfo, err := redisClient.ClusterInfo(ctx).Result()
+		if err != nil {
+			return ClusterNodeInfo{}, false, fmt.Errorf("error getting cluster info of %s: %v", podDNS, err)
+		}
+
+		node := ClusterNodeInfo{DNS: podDNS, Shard: shard, PodIndex: i, Nodes: parsed, Info: ParseInfo(clusterInfo)}
+		for _, clusterNode := range parsed {
+			if clusterNode.HasFlag("myself") {
+				node.Myself = clusterNode
+			}
+		}
+		if node.Myself.ID == "" {
+			reqLogger.Info("node doesn't report itself in cluster nodes", "pod", podDNS)
+			return ClusterNodeInfo{}, false, nil
+		}
+		return node, true, nil
+	})
+}
+
+// ApplyClusterPlan sends the commands of plan. Nodes which refuse to replicate are logged and retried with the next
+// plan, every other failure is returned
+func ApplyClusterPlan(ctx context.Context, k8Client kubernetes.Interface, instance *v1.RedisCluster, nodes []ClusterNodeInfo, plan *ClusterPlan, reqLogger logr.Logger) error {
+	tlsConfig, password, err := getClusterCredentials(ctx, k8Client, instance)
+	if err != nil {
+		return err
+	}
+
+	for _, meet := range plan.Meet {
+		// CLUSTER MEET only accepts addresses
+		addrs, err := net.DefaultResolver.LookupHost(ctx, meet.To)
+		if err != nil || len(addrs) == 0 {
+			return fmt.Errorf("error resolving %s: %v", meet.To, err)
+		}
+
+		err = clients.call(ctx, meet.From, instance.GetRedisPort(), tlsConfig, password, func(ctx context.Context, client *redis.Client) error {
+			return client.ClusterMeet(ctx, addrs[0], instance.GetRedisPort()).Err()
+		})
+		if err != nil {
+			return fmt.Errorf("error meeting %s from %s: %v", meet.To, meet.From, err)
+		}
+		reqLogger.Info("cluster node met", "from", meet.From, "to", meet.To)
+	}
+
+	// a node is forgotten on every node separately, otherwise it is gossiped back
+	for _, forget := range plan.Forget {
+		for _, node := range nodes {
+			if !node.Knows(forget.ID) {
+				continue
+			}
+			err := clients.call(ctx, node.DNS, instance.GetRedisPort(), tlsConfig, password, func(ctx context.Context, client *redis.Client) error {
+				return client.ClusterForget(ctx, forget.ID).Err()
+			})
+			if err != nil {
+				return fmt.Errorf("error forgetting %s on %s: %v", forget.ID, node.DNS, err)
+			}
+		}
+		reqLogger.Info("cluster node forgotten", "node", forget.ID, "hostname", forget.Hostname)
+	}
+
+	for _, assignment := range plan.AddSlots {
+		for _, slots := range assignment.Slots {
+			err := clients.call(ctx, assignment.DNS, instance.GetRedisPort(), tlsConfig, password, func(ctx context.Context, client *redis.Client) error {
+				return client.ClusterAddSlotsRange(ctx, slots.Start, slots.End).Err()
+			})
+			if err != nil {
+				return fmt.Errorf("error assigning slots %s to %s: %v", slots, assignment.DNS, err)
+			}
+		}
+		reqLogger.Info("cluster slots assigned", "shard", assignment.Shard, "master", assignment.DNS)
+	}
+
+	for _, replicate := range plan.Replicate {
+		err := clients.call(ctx, replicate.DNS, instance.GetRedisPort(), tlsConfig, password, func(ctx context.Context, client *redis.Client) error {
+			return client.ClusterReplicate(ctx, replicate.MasterID).Err()
+		})
+		if err != nil {
+			reqLogger.Info("failed to replicate shard master", "pod", replicate.DNS, "master", replicate.MasterDNS, "error", err)
+			continue
+		}
+		reqLogger.Info("cluster replica assigned", "pod", replicate.DNS, "master", replicate.MasterDNS)
+	}
+	return nil
+}
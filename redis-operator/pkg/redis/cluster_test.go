@@ -0,0 +1,207 @@
+package k8sredis
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const clusterNodes = `
+07c37dfeb235213a872192d90877d0cd55635b91 10.0.0.1:6379@16379,redis-shard-0-0.redis-headless.default.svc.cluster.local myself,master - 0 0 1 connected 0-5460
+67ed2db8d677e59ec4a4cefb06858cf2a1a89fa1 10.0.0.2:6379@16379,redis-shard-1-0.redis-headless.default.svc.cluster.local master - 0 1426238316232 2 connected 5461-10921 [10922->-e7d1eecce10fd6bb5eb35b9f99a514335d9ba9ca]
+292f8b365bb7edb5e285caf0b7e6ddc7265d2f4f 10.0.0.3:6379@16379 master,fail - 1426238316232 1426238316232 3 disconnected 10922 10923-16383
+6ec23923021cf3ffec47632106199cb7f496ce01 10.0.0.4:6379@16379 slave 67ed2db8d677e59ec4a4cefb06858cf2a1a89fa1 0 1426238316232 2 connected
+`
+
+func TestParseClusterNodes(t *testing.T) {
+	nodes, err := ParseClusterNodes(clusterNodes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(nodes) != 4 {
+		t.Fatalf("expected 4 nodes, got %d", len(nodes))
+	}
+
+	if !nodes[0].HasFlag("myself") || !nodes[0].IsMaster() {
+		t.Errorf("expected the first node to be myself and a master, got %v", nodes[0].Flags)
+	}
+	if nodes[0].Hostname != "redis-shard-0-0.redis-headless.default.svc.cluster.local" || nodes[0].Addr != "10.0.0.1:6379@16379" {
+		t.Errorf("unexpected address %s and hostname %s", nodes[0].Addr, nodes[0].Hostname)
+	}
+	if nodes[1].SlotCount() != 5461 {
+		t.Errorf("expected migrating slots to be skipped, got %d slots", nodes[1].SlotCount())
+	}
+	if !nodes[2].IsFailed() || nodes[2].SlotCount() != 5462 {
+		t.Errorf("expected a failed node owning 5462 slots, got %v and %d slots", nodes[2].Flags, nodes[2].SlotCount())
+	}
+	if nodes[3].MasterID != nodes[1].ID || nodes[3].IsMaster() {
+		t.Errorf("expected a replica of %s, got %s", nodes[1].ID, nodes[3].MasterID)
+	}
+
+	if _, err := ParseClusterNodes("07c37dfeb235213a872192d90877d0cd55635b91 10.0.0.1:6379@16379 myself,master"); err == nil {
+		t.Error("expected an error for a truncated line")
+	}
+}
+
+func TestGetSlotRanges(t *testing.T) {
+	ranges := GetSlotRanges(3)
+	expected := []SlotRange{{0, 5460}, {5461, 10921}, {10922, 16383}}
+	if !reflect.DeepEqual(ranges, expected) {
+		t.Fatalf("expected %v, got %v", expected, ranges)
+	}
+}
+
+// testNode describes a pod of a test cluster and the nodes it knows
+type testNode struct {
+	shard, index int
+	id           string
+	role         string // master or the id of its master
+	slots        string
+	knows        []string
+	failed       []string
+	down         bool // only known by the other nodes
+}
+
+func getTestClusterNodes(testNodes []testNode) []ClusterNodeInfo {
+	byID := map[string]testNode{}
+	for _, node := range testNodes {
+		byID[node.id] = node
+	}
+
+	line := func(node testNode, myself bool, failed bool) string {
+		flags := []string{}
+		if myself {
+			flags = append(flags, "myself")
+		}
+		master := "-"
+		if node.role == "master" {
+			flags = append(flags, "master")
+		} else {
+			flags = append(flags, "slave")
+			master = node.role
+		}
+		if failed {
+			flags = append(flags, "fail")
+		}
+		hostname := fmt.Sprintf("redis-shard-%d-%d.redis-headless.default.svc.cluster.local", node.shard, node.index)
+		return fmt.Sprintf("%s 10.0.0.1:6379@16379,%s %s %s 0 0 1 connected %s", node.id, hostname, strings.Join(flags, ","), master, node.slots)
+	}
+
+	nodes := []ClusterNodeInfo{}
+	for _, node := range testNodes {
+		if node.down {
+			continue
+		}
+		lines := []string{line(node, true, false)}
+		for _, id := range node.knows {
+			lines = append(lines, line(byID[id], false, false))
+		}
+		for _, id := range node.failed {
+			lines = append(lines, line(byID[id], false, true))
+		}
+
+		parsed, _ := ParseClusterNodes(strings.Join(lines, "\n"))
+		nodes = append(nodes, ClusterNodeInfo{
+			DNS:      fmt.Sprintf("redis-shard-%d-%d.redis-headless.default.svc.cluster.local", node.shard, node.index),
+			Shard:    node.shard,
+			PodIndex: node.index,
+			Myself:   parsed[0],
+			Nodes:    parsed,
+		})
+	}
+	return nodes
+}
+
+func TestPlanCluster(t *testing.T) {
+	t.Run("new nodes meet the seed first", func(t *testing.T) {
+		nodes := getTestClusterNodes([]testNode{
+			{shard: 0, index: 0, id: "a", role: "master", knows: []string{"b"}},
+			{shard: 0, index: 1, id: "b", role: "master"},
+			{shard: 1, index: 0, id: "c", role: "master"},
+			{shard: 2, index: 0, id: "d", role: "master"},
+		})
+
+		plan := PlanCluster(nodes, 3)
+		if len(plan.Meet) != 2 || plan.Meet[0].From != nodes[0].DNS || plan.Meet[0].To != nodes[2].DNS {
+			t.Fatalf("expected the seed to meet shard 1 and 2, got %v", plan.Meet)
+		}
+		if len(plan.AddSlots) != 0 || len(plan.Replicate) != 0 {
+			t.Fatalf("expected no assignment before every node joined, got %v and %v", plan.AddSlots, plan.Replicate)
+		}
+	})
+
+	t.Run("slots and replicas are assigned once joined", func(t *testing.T) {
+		all := []string{"a", "b", "c", "d", "e", "f"}
+		without := func(id string) []string {
+			others := []string{}
+			for _, other := range all {
+				if other != id {
+					others = append(others, other)
+				}
+			}
+			return others
+		}
+		nodes := getTestClusterNodes([]testNode{
+			{shard: 0, index: 0, id: "a", role: "master", knows: without("a")},
+			{shard: 0, index: 1, id: "b", role: "master", knows: without("b")},
+			{shard: 1, index: 0, id: "c", role: "master", slots: "5461-6000", knows: without("c")},
+			{shard: 1, index: 1, id: "d", role: "c", knows: without("d")},
+			{shard: 2, index: 0, id: "e", role: "master", knows: without("e")},
+			{shard: 2, index: 1, id: "f", role: "c", knows: without("f")},
+		})
+
+		plan := PlanCluster(nodes, 3)
+		if len(plan.Meet) != 0 || len(plan.Forget) != 0 {
+			t.Fatalf("expected nothing to meet or forget, got %v and %v", plan.Meet, plan.Forget)
+		}
+
+		expectedSlots := []ClusterSlotAssignment{
+			{DNS: nodes[0].DNS, Shard: 0, Slots: []SlotRange{{0, 5460}}},
+			{DNS: nodes[2].DNS, Shard: 1, Slots: []SlotRange{{6001, 10921}}},
+			{DNS: nodes[4].DNS, Shard: 2, Slots: []SlotRange{{10922, 16383}}},
+		}
+		if !reflect.DeepEqual(plan.AddSlots, expectedSlots) {
+			t.Fatalf("expected %v, got %v", expectedSlots, plan.AddSlots)
+		}
+
+		expectedReplicas := []ClusterReplicate{
+			{DNS: nodes[1].DNS, MasterID: "a", MasterDNS: nodes[0].DNS},
+			{DNS: nodes[5].DNS, MasterID: "e", MasterDNS: nodes[4].DNS},
+		}
+		if !reflect.DeepEqual(plan.Replicate, expectedReplicas) {
+			t.Fatalf("expected %v, got %v", expectedReplicas, plan.Replicate)
+		}
+	})
+
+	t.Run("replaced master is forgotten and its slots reassigned", func(t *testing.T) {
+		nodes := getTestClusterNodes([]testNode{
+			{shard: 0, index: 0, id: "old", role: "master", slots: "0-5460", down: true},
+			{shard: 0, index: 0, id: "a", role: "master", knows: []string{"c", "e"}, failed: []string{"old"}},
+			{shard: 1, index: 0, id: "c", role: "master", slots: "5461-10921", knows: []string{"a", "e"}, failed: []string{"old"}},
+			{shard: 2, index: 0, id: "e", role: "master", slots: "10922-16383", knows: []string{"a", "c"}, failed: []string{"old"}},
+		})
+
+		plan := PlanCluster(nodes, 3)
+		if len(plan.Forget) != 1 || plan.Forget[0].ID != "old" {
+			t.Fatalf("expected the replaced node to be forgotten, got %v", plan.Forget)
+		}
+		expectedSlots := []ClusterSlotAssignment{{DNS: nodes[0].DNS, Shard: 0, Slots: []SlotRange{{0, 5460}}}}
+		if !reflect.DeepEqual(plan.AddSlots, expectedSlots) {
+			t.Fatalf("expected %v, got %v", expectedSlots, plan.AddSlots)
+		}
+	})
+
+	t.Run("failed master is kept while its pod is down", func(t *testing.T) {
+		nodes := getTestClusterNodes([]testNode{
+			{shard: 0, index: 0, id: "a", role: "master", slots: "0-5460", down: true},
+			{shard: 1, index: 0, id: "c", role: "master", slots: "5461-10921", knows: []string{"e"}, failed: []string{"a"}},
+			{shard: 2, index: 0, id: "e", role: "master", slots: "10922-16383", knows: []string{"c"}, failed: []string{"a"}},
+		})
+
+		plan := PlanCluster(nodes, 3)
+		if !plan.IsEmpty() {
+			t.Fatalf("expected an empty plan, got %+v", plan)
+		}
+	})
+}
@@ -0,0 +1,190 @@
+package k8sredis
+
+import (
+	"fmt"
+	"sort"
+)
+
+// default replica-priority of redis. Masters don't report it
+const defaultReplicaPriority = 100
+
+// ElectionCandidate is the replication state of a reachable instance, parsed from INFO replication
+type ElectionCandidate struct {
+	Info RedisCommandInfo
+
+	Role   string
+	ReplID string
+	// replid of the previous master, set after a promotion so the history can be followed
+	ReplID2 string
+	Offset  int64
+	// first offset written under ReplID, -1 when the instance was never promoted
+	SecondOffset    int64
+	ConnectedSlaves int
+	// 0 means the instance must never be promoted
+	Priority int
+	// seconds since the instance last heard from its master, 0 for masters
+	LastSeen int64
+}
+
+// IsEmpty is true for instances which never received a write, their history doesn't matter
+func (c *ElectionCandidate) IsEmpty() bool {
+	return c.Offset == 0
+}
+
+// SharesHistory is true when both instances replicated from the same master, or one of them was promoted from the other
+func (c *ElectionCandidate) SharesHistory(other *ElectionCandidate) bool {
+	if c.IsEmpty() || other.IsEmpty() {
+		return true
+	}
+	if c.ReplID == other.ReplID {
+		return true
+	}
+	return (c.ReplID2 != "" && c.ReplID2 == other.ReplID) || (other.ReplID2 != "" && other.ReplID2 == c.ReplID)
+}
+
+// NewElectionCandidate reads the replication fields of info
+func NewElectionCandidate(info RedisCommandInfo) (*ElectionCandidate, error) {
+	if info.Redis == nil {
+		return nil, fmt.Errorf("missing replication info of %s", info.DNS)
+	}
+
+	replication := info.Redis.Replication
+	candidate := &ElectionCandidate{
+		Info:            info,
+		Role:            replication.Role,
+		ReplID:          replication.ReplID,
+		ReplID2:         replication.ReplID2,
+		Offset:          replication.MasterOffset,
+		SecondOffset:    replication.SecondOffset,
+		ConnectedSlaves: len(info.Redis.Replicas),
+		Priority:        replication.Priority,
+	}
+
+	if candidate.Role == "slave" {
+		candidate.LastSeen = replication.MasterLastIO
+		if replication.MasterLinkStatus != "up" {
+			candidate.LastSeen = replication.MasterLinkDownSince
+		}
+		if candidate.LastSeen < 0 {
+			candidate.LastSeen = -1 // never connected
+		}
+	}
+
+	return candidate, nil
+}
+
+// Election is the result of ElectMaster. Master is empty when no instance can be promoted safely, Reason tells why
+type Election struct {
+	Master     string
+	Reason     string
+	Candidates []*ElectionCandidate
+}
+
+// ElectMaster picks the instance to promote. Only instances holding every write seen by a reachable instance
+// are eligible, so no acknowledged write known to the replication is discarded. Eligible instances are ranked by
+// the preferred zone, replica-priority, the time they last heard from their master and the pod index, so every
+// reconcile picks the same instance
+func ElectMaster(replicationInfo []RedisCommandInfo, preferredZone string) (*Election, error) {
+	election := &Election{}
+
+	for _, info := range replicationInfo {
+		candidate, err := NewElectionCandidate(info)
+		if err != nil {
+			return nil, err
+		}
+		election.Candidates = append(election.Candidates, candidate)
+	}
+
+	if len(election.Candidates) == 0 {
+		election.Reason = "no reachable instance"
+		return election, nil
+	}
+
+	for i, candidate := range election.Candidates {
+		for _, other := range election.Candidates[i+1:] {
+			if !candidate.SharesHistory(other) {
+				election.Reason = fmt.Sprintf("%s and %s have diverged histories", candidate.Info.DNS, other.Info.DNS)
+				return election, nil
+			}
+		}
+	}
+
+	sort.SliceStable(election.Candidates, func(i, j int) bool {
+		return election.Candidates[i].rank(election.Candidates[j], preferredZone)
+	})
+
+	highest := election.Candidates[0]
+	for _, candidate := range election.Candidates {
+		if candidate.Offset > highest.Offset {
+			highest = candidate
+		}
+	}
+
+	for _, candidate := range election.Candidates {
+		if candidate.Offset != highest.Offset {
+			continue
+		}
+		if candidate.Priority == 0 {
+			continue
+		}
+		election.Master = candidate.Info.DNS
+		return election, nil
+	}
+
+	election.Reason = fmt.Sprintf("%s holds the most recent writes at offset %d but has replica-priority 0", highest.Info.DNS, highest.Offset)
+	return election, nil
+}
+
+// CanPromote returns an error when promoting dns would discard writes held by another reachable instance
+func CanPromote(replicationInfo []RedisCommandInfo, dns string) error {
+	candidates := []*ElectionCandidate{}
+	var promoted *ElectionCandidate
+	for _, info := range replicationInfo {
+		candidate, err := NewElectionCandidate(info)
+		if err != nil {
+			return err
+		}
+		if info.DNS == dns {
+			promoted = candidate
+		}
+		candidates = append(candidates, candidate)
+	}
+
+	if promoted == nil {
+		return fmt.Errorf("%s is not reachable", dns)
+	}
+
+	for _, candidate := range candidates {
+		if !promoted.SharesHistory(candidate) {
+			return fmt.Errorf("%s and %s have diverged histories", dns, candidate.Info.DNS)
+		}
+		if candidate.Offset > promoted.Offset {
+			return fmt.Errorf("promoting %s at offset %d would lose writes held by %s at offset %d", dns, promoted.Offset, candidate.Info.DNS, candidate.Offset)
+		}
+	}
+	return nil
+}
+
+// rank orders by offset, promotable instances, preferred zone, lowest replica-priority, most recently seen master
+// and pod index. The zone comes before the priority, which is derived from the zones when a zone config is set
+func (c *ElectionCandidate) rank(other *ElectionCandidate, preferredZone string) bool {
+	if c.Offset != other.Offset {
+		return c.Offset > other.Offset
+	}
+	if (c.Priority == 0) != (other.Priority == 0) {
+		return other.Priority == 0
+	}
+	if preferredZone != "" && (c.Info.Zone == preferredZone) != (other.Info.Zone == preferredZone) {
+		return c.Info.Zone == preferredZone
+	}
+	if c.Priority != other.Priority {
+		return c.Priority < other.Priority
+	}
+	if c.LastSeen != other.LastSeen {
+		if c.LastSeen < 0 || other.LastSeen < 0 {
+			return other.LastSeen < 0
+		}
+		return c.LastSeen < other.LastSeen
+	}
+	return c.Info.PodIndex < other.Info.PodIndex
+}
@@ -0,0 +1,179 @@
+package k8sredis
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// loadSnapshots reads the recorded INFO replication output of testdata/info, one pod per snapshot
+func loadSnapshots(t *testing.T, snapshots ...string) []RedisCommandInfo {
+	t.Helper()
+
+	replicationInfo := []RedisCommandInfo{}
+	for i, snapshot := range snapshots {
+		data, err := os.ReadFile(filepath.Join("testdata", "info", snapshot+".txt"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		info, err := ParseRedisInfo(string(data))
+		if err != nil {
+			t.Fatal(err)
+		}
+		replicationInfo = append(replicationInfo, RedisCommandInfo{
+			Redis:    info,
+			DNS:      fmt.Sprintf("redis-%d.redis-headless.default.svc.cluster.local", i),
+			PodIndex: i,
+		})
+	}
+	return replicationInfo
+}
+
+func TestElectMaster(t *testing.T) {
+	tests := []struct {
+		name          string
+		snapshots     []string
+		preferredZone string
+		zones         []string
+		master        int // pod index, -1 when the election is refused
+		reason        string
+	}{
+		{
+			name:      "replica with the highest offset",
+			snapshots: []string{"replica-behind", "replica-ahead"},
+			master:    1,
+		},
+		{
+			name:      "lowest replica-priority",
+			snapshots: []string{"replica-ahead", "replica-low-priority"},
+			master:    1,
+		},
+		{
+			name:      "most recently seen master",
+			snapshots: []string{"replica-ahead", "replica-recently-seen"},
+			master:    1,
+		},
+		{
+			name:      "pod index breaks ties",
+			snapshots: []string{"master-empty", "master-empty", "master-empty"},
+			master:    0,
+		},
+		{
+			name:          "preferred zone breaks ties",
+			snapshots:     []string{"master-empty", "master-empty", "master-empty"},
+			preferredZone: "zone-b",
+			zones:         []string{"zone-a", "zone-b", "zone-c"},
+			master:        1,
+		},
+		{
+			name:          "preferred zone wins over the replica-priority of the other zones",
+			snapshots:     []string{"replica-low-priority", "replica-ahead", "replica-behind"},
+			preferredZone: "zone-b",
+			zones:         []string{"zone-a", "zone-b", "zone-b"},
+			master:        1,
+		},
+		{
+			name:          "replica-priority ranks the replicas of the preferred zone",
+			snapshots:     []string{"replica-ahead", "replica-low-priority", "replica-recently-seen"},
+			preferredZone: "zone-b",
+			zones:         []string{"zone-a", "zone-b", "zone-b"},
+			master:        1,
+		},
+		{
+			name:      "empty instances don't block an election",
+			snapshots: []string{"master-empty", "replica-behind"},
+			master:    1,
+		},
+		{
+			name:      "promoted replica continues the history",
+			snapshots: []string{"replica-behind", "master-promoted"},
+			master:    1,
+		},
+		{
+			name:      "skips replica-priority 0 holding the same writes",
+			snapshots: []string{"replica-no-promote", "replica-behind", "replica-ahead"},
+			master:    2,
+		},
+		{
+			name:      "refuses to lose the writes of replica-priority 0",
+			snapshots: []string{"replica-behind", "replica-no-promote"},
+			master:    -1,
+			reason:    "replica-priority 0",
+		},
+		{
+			name:      "refuses diverged histories",
+			snapshots: []string{"replica-ahead", "master-diverged"},
+			master:    -1,
+			reason:    "diverged histories",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			replicationInfo := loadSnapshots(t, test.snapshots...)
+			for i, zone := range test.zones {
+				replicationInfo[i].Zone = zone
+			}
+
+			election, err := ElectMaster(replicationInfo, test.preferredZone)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if test.master < 0 {
+				if election.Master != "" {
+					t.Fatalf("expected the election to be refused, promoted %s", election.Master)
+				}
+				if !strings.Contains(election.Reason, test.reason) {
+					t.Fatalf("expected reason to contain %q, got %q", test.reason, election.Reason)
+				}
+				return
+			}
+
+			if election.Master != replicationInfo[test.master].DNS {
+				t.Fatalf("expected %s to be elected, got %q (%s)", replicationInfo[test.master].DNS, election.Master, election.Reason)
+			}
+		})
+	}
+}
+
+func TestElectMasterIsDeterministic(t *testing.T) {
+	replicationInfo := loadSnapshots(t, "replica-ahead", "replica-recently-seen", "replica-low-priority", "replica-behind")
+
+	first, err := ElectMaster(replicationInfo, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	reversed := make([]RedisCommandInfo, len(replicationInfo))
+	for i := range replicationInfo {
+		reversed[len(replicationInfo)-1-i] = replicationInfo[i]
+	}
+	second, err := ElectMaster(reversed, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if first.Master != second.Master {
+		t.Fatalf("election depends on the order of the pods: %s and %s", first.Master, second.Master)
+	}
+}
+
+func TestCanPromote(t *testing.T) {
+	replicationInfo := loadSnapshots(t, "replica-behind", "replica-ahead", "master-diverged")
+
+	if err := CanPromote(replicationInfo[:2], replicationInfo[1].DNS); err != nil {
+		t.Errorf("expected the most recent replica to be promotable, got %v", err)
+	}
+	if err := CanPromote(replicationInfo[:2], replicationInfo[0].DNS); err == nil {
+		t.Error("expected promoting the replica behind to lose writes")
+	}
+	if err := CanPromote(replicationInfo, replicationInfo[2].DNS); err == nil {
+		t.Error("expected diverged histories to be refused")
+	}
+	if err := CanPromote(replicationInfo, "redis-9.redis-headless.default.svc.cluster.local"); err == nil {
+		t.Error("expected an unreachable instance to be refused")
+	}
+}
@@ -0,0 +1,715 @@
+// Package fake provides an in-memory k8sredis.RedisTopologyClient for tests
+package fake
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-logr/logr"
+	v1 "redis.operator/api/v1"
+	k8sredis "redis.operator/pkg/redis"
+)
+
+// Pod is the replication state of a fake redis pod
+type Pod struct {
+	Down bool
+	// "master" or "slave"
+	Role       string
+	MasterHost string
+	ReplID     string
+	ReplID2    string
+	Offset     int64
+	// first offset written under ReplID, -1 when the pod was never promoted
+	SecondOffset int64
+	Priority     int
+	Fenced       bool
+	// time the pod went down, replicas report the link to it down since then
+	downSince time.Time
+	// certificates loaded by the pod, keyed by secret
+	certificates map[string]string
+}
+
+// SentinelPod is the state of a fake sentinel pod, the fields of the masters it monitors are keyed by master name
+// and reported as they are by SENTINEL MASTER
+type SentinelPod struct {
+	Down    bool
+	Masters map[string]map[string]string
+	// refuses CONFIG SET for the tls settings like older sentinels, the certificate is only loaded on restart
+	RefusesReload bool
+	// certificates loaded by the pod, keyed by secret
+	certificates map[string]string
+}
+
+// Topology is a scriptable k8sredis.RedisTopologyClient. Pods are keyed by their dns like the real ones, pods
+// which were never added are unreachable. Replicas follow their master and sentinels apply the commands of the
+// reconcilers, but nothing fails over on its own: tests script master deaths, sentinel disagreements, outages and
+// certificate renewals with the methods of Topology. Every command sent to a pod is logged and returned by Commands
+type Topology struct {
+	mu           sync.Mutex
+	pods         map[string]*Pod
+	sentinels    map[string]*SentinelPod
+	errors       map[string]error
+	replIDs      int
+	commands     []string
+	certificates map[string]string
+}
+
+var _ k8sredis.RedisTopologyClient = &Topology{}
+
+func NewTopology() *Topology {
+	return &Topology{
+		pods:         map[string]*Pod{},
+		sentinels:    map[string]*SentinelPod{},
+		errors:       map[string]error{},
+		certificates: map[string]string{},
+	}
+}
+
+// ReplicationPodDNS returns the dns of a pod of a replication
+func ReplicationPodDNS(instance *v1.RedisReplication, index int) string {
+	return instance.GetPodDNS(index)
+}
+
+// SentinelPodDNS returns the dns of a pod of a sentinel
+func SentinelPodDNS(instance *v1.RedisSentinel, index int) string {
+	return instance.GetPodDNS(index)
+}
+
+func (t *Topology) newReplID() string {
+	t.replIDs++
+	return fmt.Sprintf("%040d", t.replIDs)
+}
+
+// Commands returns the commands sent to the pods, each prefixed by the dns of its pod
+func (t *Topology) Commands() []string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return append([]string{}, t.commands...)
+}
+
+// ResetCommands clears the commands returned by Commands
+func (t *Topology) ResetCommands() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.commands = nil
+}
+
+func (t *Topology) record(dns string, format string, args ...interface{}) {
+	t.commands = append(t.commands, dns+" "+fmt.Sprintf(format, args...))
+}
+
+// AddReplication adds the pods of instance replicating the pod at index master. Every pod is a master without
+// replicas when master is negative
+func (t *Topology) AddReplication(instance *v1.RedisReplication, master int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	replID := t.newReplID()
+	for i := 0; i < instance.GetReplicas(); i++ {
+		pod := &Pod{Role: "master", ReplID: replID, SecondOffset: -1, Priority: 100, certificates: t.loadCertificates()}
+		if master < 0 {
+			pod.ReplID = t.newReplID()
+		} else if i != master {
+			pod.Role = "slave"
+			pod.MasterHost = ReplicationPodDNS(instance, master)
+		}
+		t.pods[ReplicationPodDNS(instance, i)] = pod
+	}
+}
+
+// AddSentinel adds the pods of instance, monitoring no master yet
+func (t *Topology) AddSentinel(instance *v1.RedisSentinel) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for i := 0; i < instance.Spec.StatefulsetConfig.GetReplicas(); i++ {
+		t.sentinels[SentinelPodDNS(instance, i)] = &SentinelPod{Masters: map[string]map[string]string{}, certificates: t.loadCertificates()}
+	}
+}
+
+// Pod returns a copy of the state of a redis pod, nil when it was never added
+func (t *Topology) Pod(dns string) *Pod {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	pod, ok := t.pods[dns]
+	if !ok {
+		return nil
+	}
+	copied := *pod
+	return &copied
+}
+
+// SentinelMaster returns a copy of the fields of masterName reported by a sentinel pod, nil when it doesn't monitor
+// it
+func (t *Topology) SentinelMaster(dns string, masterName string) map[string]string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	sentinel, ok := t.sentinels[dns]
+	if !ok {
+		return nil
+	}
+	master, ok := sentinel.Masters[masterName]
+	if !ok {
+		return nil
+	}
+	copied := map[string]string{}
+	for field, value := range master {
+		copied[field] = value
+	}
+	return copied
+}
+
+// KillPod makes a redis or sentinel pod unreachable
+func (t *Topology) KillPod(dns string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if pod, ok := t.pods[dns]; ok && !pod.Down {
+		pod.Down = true
+		pod.downSince = time.Now()
+	}
+	if sentinel, ok := t.sentinels[dns]; ok {
+		sentinel.Down = true
+	}
+}
+
+// RestorePod makes a redis or sentinel pod reachable again, with the state it had when it went down
+func (t *Topology) RestorePod(dns string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if pod, ok := t.pods[dns]; ok {
+		pod.Down = false
+	}
+	if sentinel, ok := t.sentinels[dns]; ok {
+		sentinel.Down = false
+	}
+}
+
+// RestartPod makes a redis or sentinel pod reachable again, loading the certificates currently stored in the secrets
+// as a restarted pod does. The replication and monitoring state is kept
+func (t *Topology) RestartPod(dns string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if pod, ok := t.pods[dns]; ok {
+		pod.Down = false
+		pod.certificates = t.loadCertificates()
+	}
+	if sentinel, ok := t.sentinels[dns]; ok {
+		sentinel.Down = false
+		sentinel.certificates = t.loadCertificates()
+	}
+}
+
+// RefuseCertificateReload makes a sentinel refuse to reload its certificate, it only loads a renewed one on restart
+func (t *Topology) RefuseCertificateReload(dns string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if sentinel, ok := t.sentinels[dns]; ok {
+		sentinel.RefusesReload = true
+	}
+}
+
+// SetCertificate renews the certificate stored in a tls secret. Pods keep serving the previous one until they reload
+// it or restart
+func (t *Topology) SetCertificate(namespace string, secret string, certificate string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.certificates[namespace+"/"+secret] = certificate
+}
+
+// ServesCertificate returns whether a redis or sentinel pod serves the certificate currently stored in a secret
+func (t *Topology) ServesCertificate(dns string, namespace string, secret string) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return t.servesCertificate(dns, namespace+"/"+secret)
+}
+
+func (t *Topology) servesCertificate(dns string, secret string) bool {
+	if pod, ok := t.pods[dns]; ok {
+		return pod.certificates[secret] == t.certificates[secret]
+	}
+	if sentinel, ok := t.sentinels[dns]; ok {
+		return sentinel.certificates[secret] == t.certificates[secret]
+	}
+	return false
+}
+
+func (t *Topology) loadCertificates() map[string]string {
+	certificates := make(map[string]string, len(t.certificates))
+	for secret, certificate := range t.certificates {
+		certificates[secret] = certificate
+	}
+	return certificates
+}
+
+// Write simulates writes of bytes on a master, its replicas catch up the next time they are read
+func (t *Topology) Write(dns string, bytes int64) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	pod, ok := t.pods[dns]
+	if !ok || pod.Down || pod.Role != "master" {
+		return fmt.Errorf("%s is not a reachable master", dns)
+	}
+	pod.Offset += bytes
+	return nil
+}
+
+// SetSentinelMaster makes a sentinel pod report masterName at address, so sentinels can disagree on the master
+func (t *Topology) SetSentinelMaster(dns string, masterName string, address string) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	master, err := t.getSentinelMaster(dns, masterName)
+	if err != nil {
+		return err
+	}
+	master["ip"] = address
+	return nil
+}
+
+// SetSentinelDownTime makes a sentinel pod report masterName subjectively down for downTime, up again when
+// downTime is 0
+func (t *Topology) SetSentinelDownTime(dns string, masterName string, downTime time.Duration) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	master, err := t.getSentinelMaster(dns, masterName)
+	if err != nil {
+		return err
+	}
+	if downTime == 0 {
+		delete(master, "s-down-time")
+		master["flags"] = "master"
+		return nil
+	}
+	master["s-down-time"] = strconv.FormatInt(downTime.Milliseconds(), 10)
+	master["flags"] = "s_down,master"
+	return nil
+}
+
+// SetError makes every call to method, like "GetReplicaInfo", fail with err. A nil err clears it
+func (t *Topology) SetError(method string, err error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if err == nil {
+		delete(t.errors, method)
+		return
+	}
+	t.errors[method] = err
+}
+
+func (t *Topology) getSentinelMaster(dns string, masterName string) (map[string]string, error) {
+	sentinel, ok := t.sentinels[dns]
+	if !ok {
+		return nil, fmt.Errorf("unknown sentinel %s", dns)
+	}
+	master, ok := sentinel.Masters[masterName]
+	if !ok {
+		return nil, fmt.Errorf("sentinel %s doesn't monitor %s", dns, masterName)
+	}
+	return master, nil
+}
+
+// getUpPod returns a reachable redis pod, nil when it is down or was never added
+func (t *Topology) getUpPod(dns string) *Pod {
+	if pod, ok := t.pods[dns]; ok && !pod.Down {
+		return pod
+	}
+	return nil
+}
+
+// getInfo returns INFO replication of pod as parsed from the redis output, replicas with a reachable master catch
+// up with it first
+func (t *Topology) getInfo(dns string, pod *Pod) (*k8sredis.RedisInfo, error) {
+	info := map[string]string{
+		"role":           pod.Role,
+		"master_replid":  pod.ReplID,
+		"master_replid2": fmt.Sprintf("%040d", 0),
+	}
+	if pod.ReplID2 != "" {
+		info["master_replid2"] = pod.ReplID2
+	}
+
+	if pod.Role == "master" {
+		replicas := []string{}
+		for otherDNS, other := range t.pods {
+			if !other.Down && other.Role == "slave" && other.MasterHost == dns {
+				replicas = append(replicas, otherDNS)
+			}
+		}
+		sort.Strings(replicas)
+		for i, replica := range replicas {
+			info[fmt.Sprintf("slave%d", i)] = fmt.Sprintf("ip=%s,port=6379,state=online,offset=%d,lag=0", replica, pod.Offset)
+		}
+		info["connected_slaves"] = strconv.Itoa(len(replicas))
+	} else {
+		info["master_host"] = pod.MasterHost
+		info["slave_priority"] = strconv.Itoa(pod.Priority)
+		if master, ok := t.pods[pod.MasterHost]; ok && !master.Down && master.Role == "master" {
+			pod.ReplID, pod.ReplID2, pod.Offset, pod.SecondOffset = master.ReplID, master.ReplID2, master.Offset, master.SecondOffset
+			info["master_replid"] = pod.ReplID
+			info["master_link_status"] = "up"
+			info["master_last_io_seconds_ago"] = "0"
+		} else {
+			info["master_link_status"] = "down"
+			info["master_link_down_since_seconds"] = "-1"
+			if ok && master.Down {
+				info["master_link_down_since_seconds"] = strconv.Itoa(int(time.Since(master.downSince).Seconds()))
+			}
+		}
+		info["slave_repl_offset"] = strconv.FormatInt(pod.Offset, 10)
+	}
+	info["master_repl_offset"] = strconv.FormatInt(pod.Offset, 10)
+	info["second_repl_offset"] = strconv.FormatInt(pod.SecondOffset, 10)
+
+	fields := []string{}
+	for field, value := range info {
+		fields = append(fields, field+":"+value)
+	}
+	sort.Strings(fields)
+	return k8sredis.ParseRedisInfo("# Replication\r\n" + strings.Join(fields, "\r\n") + "\r\n")
+}
+
+func (t *Topology) GetReplicaInfo(ctx context.Context, instance *v1.RedisReplication, reqLogger logr.Logger) ([]k8sredis.RedisCommandInfo, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if err := t.errors["GetReplicaInfo"]; err != nil {
+		return nil, err
+	}
+
+	replicaInfo := []k8sredis.RedisCommandInfo{}
+	for i := 0; i < instance.GetReplicas(); i++ {
+		dns := ReplicationPodDNS(instance, i)
+		if pod := t.getUpPod(dns); pod != nil {
+			info, err := t.getInfo(dns, pod)
+			if err != nil {
+				return nil, err
+			}
+			replicaInfo = append(replicaInfo, k8sredis.RedisCommandInfo{Redis: info, DNS: dns, PodIndex: i})
+		}
+	}
+	return replicaInfo, nil
+}
+
+func (t *Topology) SetReplicationMaster(ctx context.Context, instance *v1.RedisReplication, masterDNS string, reqLogger logr.Logger) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if err := t.errors["SetReplicationMaster"]; err != nil {
+		return err
+	}
+
+	master := t.getUpPod(masterDNS)
+	if master == nil {
+		return fmt.Errorf("error setting replication master: dial tcp: lookup %s: no such host", masterDNS)
+	}
+	t.record(masterDNS, "SLAVEOF NO ONE")
+	if master.Role != "master" {
+		master.Role = "master"
+		master.MasterHost = ""
+		master.ReplID2 = master.ReplID
+		master.SecondOffset = master.Offset + 1
+		master.ReplID = t.newReplID()
+	}
+
+	for i := 0; i < instance.GetReplicas(); i++ {
+		dns := ReplicationPodDNS(instance, i)
+		if pod := t.getUpPod(dns); pod != nil && dns != masterDNS {
+			t.record(dns, "SLAVEOF %s %s", masterDNS, instance.GetReplicationPort())
+			pod.Role = "slave"
+			pod.MasterHost = masterDNS
+		}
+	}
+	return nil
+}
+
+func (t *Topology) SetReplicaPriority(ctx context.Context, instance *v1.RedisReplication, priorities map[int]int, reqLogger logr.Logger) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if err := t.errors["SetReplicaPriority"]; err != nil {
+		return err
+	}
+
+	for index, priority := range priorities {
+		dns := ReplicationPodDNS(instance, index)
+		if pod := t.getUpPod(dns); pod != nil && pod.Priority != priority {
+			t.record(dns, "CONFIG SET replica-priority %d", priority)
+			pod.Priority = priority
+		}
+	}
+	return nil
+}
+
+func (t *Topology) FenceStaleMasters(ctx context.Context, instance *v1.RedisReplication, splitBrain *k8sredis.SplitBrain, reqLogger logr.Logger) (map[string]*k8sredis.Fence, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if err := t.errors["FenceStaleMasters"]; err != nil {
+		return nil, err
+	}
+
+	fences := map[string]*k8sredis.Fence{}
+	for _, stale := range splitBrain.Stale {
+		dns := stale.Candidate.Info.DNS
+		pod := t.getUpPod(dns)
+		if pod == nil {
+			return fences, fmt.Errorf("error fencing %s: no such host", dns)
+		}
+		t.record(dns, "CLIENT PAUSE WRITE")
+		pod.Fenced = true
+		fences[dns] = &k8sredis.Fence{Paused: true}
+	}
+	return fences, nil
+}
+
+func (t *Topology) UnfenceMasters(ctx context.Context, instance *v1.RedisReplication, fences map[string]*k8sredis.Fence, reqLogger logr.Logger) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if err := t.errors["UnfenceMasters"]; err != nil {
+		return err
+	}
+
+	for dns := range fences {
+		pod := t.getUpPod(dns)
+		if pod == nil {
+			return fmt.Errorf("error unfencing %s: no such host", dns)
+		}
+		t.record(dns, "CLIENT UNPAUSE")
+		pod.Fenced = false
+	}
+	return nil
+}
+
+// countSentinels returns the number of reachable sentinels of instance monitoring masterName
+func (t *Topology) countSentinels(instance *v1.RedisSentinel, masterName string) int {
+	count := 0
+	for i := 0; i < instance.Spec.StatefulsetConfig.GetReplicas(); i++ {
+		if sentinel, ok := t.sentinels[SentinelPodDNS(instance, i)]; ok && !sentinel.Down {
+			if _, ok := sentinel.Masters[masterName]; ok {
+				count++
+			}
+		}
+	}
+	return count
+}
+
+func (t *Topology) GetSentinelMasters(ctx context.Context, instance *v1.RedisSentinel, tlsReplication *v1.RedisReplication, masterName string) ([]k8sredis.SentinelMaster, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if err := t.errors["GetSentinelMasters"]; err != nil {
+		return nil, err
+	}
+
+	sentinelMasters := []k8sredis.SentinelMaster{}
+	for i := 0; i < instance.Spec.StatefulsetConfig.GetReplicas(); i++ {
+		dns := SentinelPodDNS(instance, i)
+		sentinel, ok := t.sentinels[dns]
+		if !ok || sentinel.Down {
+			continue
+		}
+		master, ok := sentinel.Masters[masterName]
+		if !ok {
+			continue
+		}
+
+		fields := map[string]string{}
+		for field, value := range master {
+			fields[field] = value
+		}
+		fields["num-other-sentinels"] = strconv.Itoa(t.countSentinels(instance, masterName) - 1)
+		sentinelMaster, err := k8sredis.NewSentinelMaster(fields)
+		if err != nil {
+			return nil, err
+		}
+		sentinelMaster.SentinelDNS = dns
+		sentinelMaster.SentinelPodIndex = i
+		sentinelMasters = append(sentinelMasters, *sentinelMaster)
+	}
+	return sentinelMasters, nil
+}
+
+func (t *Topology) UpdateSentinelMonitors(ctx context.Context, instance *v1.RedisSentinel, tlsReplication *v1.RedisReplication, monitors []k8sredis.SentinelMonitor, removed []string, reqLogger logr.Logger) (k8sredis.SentinelMonitorChanges, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	changes := k8sredis.SentinelMonitorChanges{}
+	if err := t.errors["UpdateSentinelMonitors"]; err != nil {
+		return changes, err
+	}
+
+	added := map[string]bool{}
+	updated := map[string]bool{}
+	dropped := map[string]bool{}
+	for i := 0; i < instance.Spec.StatefulsetConfig.GetReplicas(); i++ {
+		dns := SentinelPodDNS(instance, i)
+		sentinel, ok := t.sentinels[dns]
+		if !ok || sentinel.Down {
+			continue
+		}
+
+		for _, name := range removed {
+			if _, ok := sentinel.Masters[name]; ok {
+				t.record(dns, "SENTINEL REMOVE %s", name)
+				delete(sentinel.Masters, name)
+				dropped[name] = true
+			}
+		}
+
+		for _, monitor := range monitors {
+			master, ok := sentinel.Masters[monitor.Name]
+			if !ok {
+				t.record(dns, "SENTINEL MONITOR %s %s %s %d", monitor.Name, monitor.DNS, monitor.Port, monitor.Quorum)
+				master = map[string]string{
+					"name":                    monitor.Name,
+					"ip":                      monitor.DNS,
+					"port":                    monitor.Port,
+					"quorum":                  strconv.Itoa(monitor.Quorum),
+					"flags":                   "master",
+					"down-after-milliseconds": "30000",
+					"failover-timeout":        "180000",
+					"parallel-syncs":          "1",
+				}
+				sentinel.Masters[monitor.Name] = master
+				added[monitor.Name] = true
+			}
+
+			options := k8sredis.GetSentinelSetOptions(master, monitor, !ok)
+			for _, option := range options {
+				value := monitor.Settings[option]
+				if option == "quorum" {
+					value = strconv.Itoa(monitor.Quorum)
+				}
+				t.record(dns, "SENTINEL SET %s %s %s", monitor.Name, option, value)
+				master[option] = value
+			}
+			if ok && len(options) > 0 {
+				updated[monitor.Name] = true
+			}
+		}
+	}
+
+	for _, monitor := range monitors {
+		if added[monitor.Name] {
+			changes.Added = append(changes.Added, monitor.Name)
+		} else if updated[monitor.Name] {
+			changes.Updated = append(changes.Updated, monitor.Name)
+		}
+	}
+	for _, name := range removed {
+		if dropped[name] {
+			changes.Removed = append(changes.Removed, name)
+		}
+	}
+	return changes, nil
+}
+
+func (t *Topology) ResetSentinelMaster(ctx context.Context, instance *v1.RedisSentinel, tlsReplication *v1.RedisReplication, podIndex int, masterName string) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if err := t.errors["ResetSentinelMaster"]; err != nil {
+		return err
+	}
+
+	dns := SentinelPodDNS(instance, podIndex)
+	sentinel, ok := t.sentinels[dns]
+	if !ok || sentinel.Down {
+		return fmt.Errorf("error resetting master %s on %s: no such host", masterName, dns)
+	}
+	t.record(dns, "SENTINEL RESET %s", masterName)
+	if master, ok := sentinel.Masters[masterName]; ok {
+		delete(master, "s-down-time")
+		master["flags"] = "master"
+	}
+	return nil
+}
+
+func (t *Topology) RemoveSentinelMaster(ctx context.Context, instance *v1.RedisSentinel, tlsReplication *v1.RedisReplication, podIndex int, masterName string) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if err := t.errors["RemoveSentinelMaster"]; err != nil {
+		return err
+	}
+
+	dns := SentinelPodDNS(instance, podIndex)
+	sentinel, ok := t.sentinels[dns]
+	if !ok || sentinel.Down {
+		return fmt.Errorf("error removing master %s from %s: no such host", masterName, dns)
+	}
+	t.record(dns, "SENTINEL REMOVE %s", masterName)
+	delete(sentinel.Masters, masterName)
+	return nil
+}
+
+func (t *Topology) ReloadReplicationCertificates(ctx context.Context, instance *v1.RedisReplication, reqLogger logr.Logger) (bool, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if err := t.errors["ReloadReplicationCertificates"]; err != nil {
+		return false, err
+	}
+
+	secret := instance.Namespace + "/" + instance.GetTLSSecretName()
+	for i := 0; i < instance.GetReplicas(); i++ {
+		dns := ReplicationPodDNS(instance, i)
+		pod := t.getUpPod(dns)
+		if pod == nil || t.servesCertificate(dns, secret) {
+			continue
+		}
+		t.record(dns, "CONFIG SET tls-cert-file")
+		pod.certificates[secret] = t.certificates[secret]
+	}
+	return true, nil
+}
+
+func (t *Topology) ReloadSentinelCertificates(ctx context.Context, instance *v1.RedisSentinel, tlsReplication *v1.RedisReplication, reqLogger logr.Logger) (*k8sredis.SentinelCertificateReload, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if err := t.errors["ReloadSentinelCertificates"]; err != nil {
+		return nil, err
+	}
+
+	secret := instance.Namespace + "/" + instance.GetTLSSecretName(tlsReplication)
+	reload := &k8sredis.SentinelCertificateReload{Reloaded: true, Refused: []int{}}
+	for i := 0; i < instance.Spec.StatefulsetConfig.GetReplicas(); i++ {
+		dns := SentinelPodDNS(instance, i)
+		sentinel, ok := t.sentinels[dns]
+		if !ok || sentinel.Down {
+			continue
+		}
+		reload.Reachable++
+		if t.servesCertificate(dns, secret) {
+			continue
+		}
+		t.record(dns, "CONFIG SET tls-cert-file")
+		if sentinel.RefusesReload {
+			reload.Refused = append(reload.Refused, i)
+			reload.Reloaded = false
+			continue
+		}
+		sentinel.certificates[secret] = t.certificates[secret]
+	}
+	return reload, nil
+}
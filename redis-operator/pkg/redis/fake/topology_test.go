@@ -0,0 +1,133 @@
+package fake
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	v1 "redis.operator/api/v1"
+	k8sredis "redis.operator/pkg/redis"
+)
+
+func TestTopologyFailover(t *testing.T) {
+	ctx := context.Background()
+	replicas := int32(3)
+	instance := &v1.RedisReplication{ObjectMeta: metav1.ObjectMeta{Name: "redis", Namespace: "default"}}
+	instance.Spec.StatefulsetConfig.Wrapper.Spec.Replicas = &replicas
+
+	topology := NewTopology()
+	topology.AddReplication(instance, 0)
+	if err := topology.Write(ReplicationPodDNS(instance, 0), 100); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := topology.GetReplicaInfo(ctx, instance, logr.Discard()); err != nil {
+		t.Fatal(err)
+	}
+
+	topology.KillPod(ReplicationPodDNS(instance, 0))
+	replicaInfo, err := topology.GetReplicaInfo(ctx, instance, logr.Discard())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(replicaInfo) != 2 || replicaInfo[0].Redis.Replication.MasterLinkStatus != "down" || replicaInfo[0].Redis.Replication.ReplicaOffset != 100 {
+		t.Fatalf("expected 2 replicas with their master link down, got %+v", replicaInfo)
+	}
+
+	election, err := k8sredis.ElectMaster(replicaInfo, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if election.Master == "" {
+		t.Fatalf("expected a replica to be elected: %s", election.Reason)
+	}
+	if err := topology.SetReplicationMaster(ctx, instance, election.Master, logr.Discard()); err != nil {
+		t.Fatal(err)
+	}
+
+	topology.RestorePod(ReplicationPodDNS(instance, 0))
+	if master := topology.Pod(election.Master); master.Role != "master" || master.SecondOffset != 101 {
+		t.Errorf("expected the elected replica to be promoted, got %+v", master)
+	}
+	if stale := topology.Pod(ReplicationPodDNS(instance, 0)); stale.Role != "master" {
+		t.Errorf("expected the restored master to still be a master until it is demoted, got %+v", stale)
+	}
+}
+
+func TestTopologySentinels(t *testing.T) {
+	ctx := context.Background()
+	replicas := int32(3)
+	instance := &v1.RedisSentinel{ObjectMeta: metav1.ObjectMeta{Name: "sentinel", Namespace: "default"}}
+	instance.Spec.StatefulsetConfig.Wrapper.Spec.Replicas = &replicas
+
+	topology := NewTopology()
+	topology.AddSentinel(instance)
+	monitors := []k8sredis.SentinelMonitor{{Name: "mymaster", DNS: "redis-0", Port: "6379", Quorum: 2}}
+	changes, err := topology.UpdateSentinelMonitors(ctx, instance, nil, monitors, nil, logr.Discard())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(changes.Added) != 1 {
+		t.Fatalf("expected mymaster to be added, got %+v", changes)
+	}
+
+	topology.KillPod(SentinelPodDNS(instance, 2))
+	if err := topology.SetSentinelMaster(SentinelPodDNS(instance, 1), "mymaster", "redis-1"); err != nil {
+		t.Fatal(err)
+	}
+	if err := topology.SetSentinelDownTime(SentinelPodDNS(instance, 1), "mymaster", 30*time.Second); err != nil {
+		t.Fatal(err)
+	}
+
+	sentinelMasters, err := topology.GetSentinelMasters(ctx, instance, nil, "mymaster")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sentinelMasters) != 2 || sentinelMasters[1].IP != "redis-1" || sentinelMasters[1].NumOtherSentinels != 1 {
+		t.Fatalf("expected 2 sentinels disagreeing on the master, got %+v", sentinelMasters)
+	}
+	sick := k8sredis.FindSickSentinels(sentinelMasters, "mymaster", true, 2, 20*time.Second)
+	if len(sick) != 1 || sick[0].PodIndex != 1 {
+		t.Errorf("expected sentinel 1 to be sick, got %+v", sick)
+	}
+}
+
+func TestTopologyCertificates(t *testing.T) {
+	ctx := context.Background()
+	replicas := int32(3)
+	replication := &v1.RedisReplication{ObjectMeta: metav1.ObjectMeta{Name: "redis", Namespace: "default"}}
+	replication.Spec.StatefulsetConfig.Wrapper.Spec.Replicas = &replicas
+	replication.Spec.TLSConfig = &v1.RedisTLSConfiguration{SecretName: "redis-tls"}
+	sentinel := &v1.RedisSentinel{ObjectMeta: metav1.ObjectMeta{Name: "sentinel", Namespace: "default"}}
+	sentinel.Spec.StatefulsetConfig.Wrapper.Spec.Replicas = &replicas
+
+	topology := NewTopology()
+	topology.SetCertificate("default", "redis-tls", "first")
+	topology.AddReplication(replication, 0)
+	topology.AddSentinel(sentinel)
+
+	topology.SetCertificate("default", "redis-tls", "renewed")
+	topology.KillPod(ReplicationPodDNS(replication, 2))
+	reloaded, err := topology.ReloadReplicationCertificates(ctx, replication, logr.Discard())
+	if err != nil || !reloaded {
+		t.Fatalf("expected the reachable pods to reload the certificate, got %v %v", reloaded, err)
+	}
+	if !topology.ServesCertificate(ReplicationPodDNS(replication, 0), "default", "redis-tls") || topology.ServesCertificate(ReplicationPodDNS(replication, 2), "default", "redis-tls") {
+		t.Error("expected only the reachable pods to serve the renewed certificate")
+	}
+
+	topology.RefuseCertificateReload(SentinelPodDNS(sentinel, 0))
+	reload, err := topology.ReloadSentinelCertificates(ctx, sentinel, replication, logr.Discard())
+	if err != nil || reload.Reloaded || len(reload.Refused) != 1 || reload.Refused[0] != 0 || reload.Reachable != 3 {
+		t.Fatalf("expected only sentinel 0 to refuse the reload, got %+v %v", reload, err)
+	}
+	if !topology.ServesCertificate(SentinelPodDNS(sentinel, 1), "default", "redis-tls") {
+		t.Error("expected the other sentinels to reload the renewed certificate")
+	}
+	topology.RestartPod(SentinelPodDNS(sentinel, 0))
+	reload, _ = topology.ReloadSentinelCertificates(ctx, sentinel, replication, logr.Discard())
+	if !reload.Reloaded || len(reload.Refused) != 0 {
+		t.Errorf("expected the restarted sentinel to serve the renewed certificate, got %+v", reload)
+	}
+}
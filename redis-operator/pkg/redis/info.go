@@ -0,0 +1,257 @@
+package k8sredis
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// replicaLine matches the slaveN fields of INFO replication, dbN matches the fields of INFO keyspace
+var (
+	replicaLine  = regexp.MustCompile(`^slave(\d+)$`)
+	keyspaceLine = regexp.MustCompile(`^db(\d+)$`)
+)
+
+// RedisInfo is the output of INFO. Fields are kept as reported, the slaveN and dbN fields are also parsed
+type RedisInfo struct {
+	// fields of every section, by lower case section name
+	Sections map[string]map[string]string
+	// typed fields of the replication section
+	Replication InfoReplication
+	// replicas connected to a master, ordered by N of slaveN
+	Replicas []InfoReplica
+	// keyspace of every database, ordered by database
+	Keyspace []InfoKeyspace
+}
+
+// InfoReplication is the replication section of INFO
+type InfoReplication struct {
+	Role       string
+	MasterHost string
+	ReplID     string
+	// replid of the previous master, empty until the instance is promoted
+	ReplID2      string
+	MasterOffset int64
+	// first offset written under ReplID, -1 when the instance was never promoted
+	SecondOffset int64
+	// replica-priority, defaultReplicaPriority when not reported
+	Priority int
+	// offset processed by a replica, -1 for masters
+	ReplicaOffset    int64
+	MasterLinkStatus string
+	// seconds since a replica last heard from its master and since its link went down, -1 when not reported
+	MasterLastIO        int64
+	MasterLinkDownSince int64
+}
+
+// InfoReplica is a slaveN field of INFO replication. Addresses may be IPv6 or hostnames, fields missing from older
+// versions are left empty
+type InfoReplica struct {
+	Index  int
+	IP     string
+	Port   int
+	State  string
+	Offset int64
+	Lag    int64
+}
+
+// InfoKeyspace is a dbN field of INFO keyspace
+type InfoKeyspace struct {
+	DB      int
+	Keys    int64
+	Expires int64
+	AvgTTL  int64
+}
+
+// parseInfoSections splits the output of INFO by section. Fields before the first header are put in an empty
+// section
+func parseInfoSections(info string) map[string]map[string]string {
+	sections := map[string]map[string]string{}
+	section := ""
+	for _, line := range strings.Split(info, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		if strings.HasPrefix(line, "#") {
+			section = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(line, "#")))
+			continue
+		}
+		values := strings.SplitN(line, ":", 2)
+		if len(values) != 2 {
+			continue
+		}
+		if _, ok := sections[section]; !ok {
+			sections[section] = map[string]string{}
+		}
+		sections[section][values[0]] = values[1]
+	}
+	return sections
+}
+
+// parseInfoValues parses the comma separated key=value pairs of a slaveN or dbN field
+func parseInfoValues(value string) map[string]string {
+	values := map[string]string{}
+	for _, pair := range strings.Split(value, ",") {
+		if key, value, ok := strings.Cut(pair, "="); ok {
+			values[key] = value
+		}
+	}
+	return values
+}
+
+// parseInfoInt parses the optional integer key of values, 0 when missing
+func parseInfoInt(values map[string]string, key string, field string) (int64, error) {
+	value, ok := values[key]
+	if !ok {
+		return 0, nil
+	}
+	parsed, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s of %s: %v", key, field, err)
+	}
+	return parsed, nil
+}
+
+// parseReplicationInt parses the optional integer field of INFO replication, fallback when missing
+func parseReplicationInt(fields map[string]string, field string, fallback int64) (int64, error) {
+	value, ok := fields[field]
+	if !ok {
+		return fallback, nil
+	}
+	parsed, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s: %v", field, err)
+	}
+	return parsed, nil
+}
+
+// parseReplication parses the typed fields of INFO replication
+func parseReplication(fields map[string]string) (InfoReplication, error) {
+	replication := InfoReplication{
+		Role:             fields["role"],
+		MasterHost:       fields["master_host"],
+		ReplID:           fields["master_replid"],
+		MasterLinkStatus: fields["master_link_status"],
+	}
+	if replID2 := fields["master_replid2"]; strings.Trim(replID2, "0") != "" {
+		replication.ReplID2 = replID2
+	}
+
+	var err error
+	if replication.MasterOffset, err = parseReplicationInt(fields, "master_repl_offset", 0); err != nil {
+		return replication, err
+	}
+	if replication.SecondOffset, err = parseReplicationInt(fields, "second_repl_offset", -1); err != nil {
+		return replication, err
+	}
+	priority, err := parseReplicationInt(fields, "slave_priority", defaultReplicaPriority)
+	if err != nil {
+		return replication, err
+	}
+	replication.Priority = int(priority)
+	if replication.ReplicaOffset, err = parseReplicationInt(fields, "slave_repl_offset", -1); err != nil {
+		return replication, err
+	}
+	if replication.MasterLastIO, err = parseReplicationInt(fields, "master_last_io_seconds_ago", -1); err != nil {
+		return replication, err
+	}
+	if replication.MasterLinkDownSince, err = parseReplicationInt(fields, "master_link_down_since_seconds", -1); err != nil {
+		return replication, err
+	}
+	return replication, nil
+}
+
+// ParseRedisInfo parses the output of INFO of redis and valkey, fails on malformed slaveN and dbN fields
+func ParseRedisInfo(info string) (*RedisInfo, error) {
+	parsed := &RedisInfo{Sections: parseInfoSections(info)}
+
+	replication, err := parseReplication(parsed.Sections["replication"])
+	if err != nil {
+		return nil, err
+	}
+	parsed.Replication = replication
+
+	for _, fields := range parsed.Sections {
+		for field, value := range fields {
+			if match := replicaLine.FindStringSubmatch(field); match != nil {
+				values := parseInfoValues(value)
+				replica := InfoReplica{IP: values["ip"], State: values["state"]}
+				replica.Index, _ = strconv.Atoi(match[1])
+
+				port, err := parseInfoInt(values, "port", field)
+				if err != nil {
+					return nil, err
+				}
+				replica.Port = int(port)
+				if replica.Offset, err = parseInfoInt(values, "offset", field); err != nil {
+					return nil, err
+				}
+				if replica.Lag, err = parseInfoInt(values, "lag", field); err != nil {
+					return nil, err
+				}
+				parsed.Replicas = append(parsed.Replicas, replica)
+			}
+
+			if match := keyspaceLine.FindStringSubmatch(field); match != nil {
+				values := parseInfoValues(value)
+				keyspace := InfoKeyspace{}
+				keyspace.DB, _ = strconv.Atoi(match[1])
+
+				var err error
+				if keyspace.Keys, err = parseInfoInt(values, "keys", field); err != nil {
+					return nil, err
+				}
+				if keyspace.Expires, err = parseInfoInt(values, "expires", field); err != nil {
+					return nil, err
+				}
+				if keyspace.AvgTTL, err = parseInfoInt(values, "avg_ttl", field); err != nil {
+					return nil, err
+				}
+				parsed.Keyspace = append(parsed.Keyspace, keyspace)
+			}
+		}
+	}
+
+	sort.Slice(parsed.Replicas, func(i, j int) bool { return parsed.Replicas[i].Index < parsed.Replicas[j].Index })
+	sort.Slice(parsed.Keyspace, func(i, j int) bool { return parsed.Keyspace[i].DB < parsed.Keyspace[j].DB })
+	return parsed, nil
+}
+
+// Get returns a field of any section
+func (i *RedisInfo) Get(field string) (string, bool) {
+	for _, fields := range i.Sections {
+		if value, ok := fields[field]; ok {
+			return value, true
+		}
+	}
+	return "", false
+}
+
+// Fields returns the fields of every section in a single map
+func (i *RedisInfo) Fields() map[string]string {
+	merged := map[string]string{}
+	for _, fields := range i.Sections {
+		for field, value := range fields {
+			merged[field] = value
+		}
+	}
+	return merged
+}
+
+// GetVersion returns the server version, valkey_version for valkey which also reports the redis version it is
+// compatible with
+func (i *RedisInfo) GetVersion() string {
+	if version, ok := i.Get("valkey_version"); ok {
+		return version
+	}
+	version, _ := i.Get("redis_version")
+	return version
+}
+
+// ParseInfo converts the output of INFO into a map. Section headers and comments are skipped
+func ParseInfo(info string) map[string]string {
+	return (&RedisInfo{Sections: parseInfoSections(info)}).Fields()
+}
@@ -0,0 +1,131 @@
+package k8sredis
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestParseInfo(t *testing.T) {
+	info := ParseInfo("# Replication\r\nrole:slave\r\nslave0:ip=10.0.0.1,port=6379,state=online\r\n\r\n# CPU\r\nused_cpu_sys:1.5\r\n")
+
+	expected := map[string]string{
+		"role":         "slave",
+		"slave0":       "ip=10.0.0.1,port=6379,state=online",
+		"used_cpu_sys": "1.5",
+	}
+	if len(info) != len(expected) {
+		t.Fatalf("expected %d fields, got %v", len(expected), info)
+	}
+	for key, value := range expected {
+		if info[key] != value {
+			t.Errorf("expected %s to be %q, got %q", key, value, info[key])
+		}
+	}
+}
+
+func TestParseRedisInfo(t *testing.T) {
+	tests := []struct {
+		snapshot string
+		version  string
+		role     string
+		replicas []InfoReplica
+		keyspace []InfoKeyspace
+	}{
+		{
+			snapshot: "server-redis6",
+			version:  "6.2.14",
+			role:     "master",
+			replicas: []InfoReplica{
+				{Index: 0, IP: "10.244.0.12", Port: 6379, State: "online", Offset: 8812},
+				{Index: 1, IP: "10.244.0.13", Port: 6379, State: "wait_bgsave", Lag: 1},
+			},
+			keyspace: []InfoKeyspace{{DB: 0, Keys: 12, Expires: 2, AvgTTL: 35012}},
+		},
+		{
+			snapshot: "server-redis7",
+			version:  "7.2.5",
+			role:     "master",
+			replicas: []InfoReplica{
+				{Index: 0, IP: "fd00:10:244::12", Port: 6379, State: "online", Offset: 1500},
+				{Index: 1, IP: "redis-2.redis-headless.default.svc.cluster.local", Port: 6380, State: "online", Offset: 1490, Lag: 1},
+			},
+			keyspace: []InfoKeyspace{{DB: 0, Keys: 3}, {DB: 3, Keys: 1, Expires: 1, AvgTTL: 1000}},
+		},
+		{
+			snapshot: "server-valkey8",
+			version:  "8.0.1",
+			role:     "slave",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.snapshot, func(t *testing.T) {
+			data, err := os.ReadFile(filepath.Join("testdata", "info", test.snapshot+".txt"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			info, err := ParseRedisInfo(string(data))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if info.GetVersion() != test.version {
+				t.Errorf("expected version %s, got %s", test.version, info.GetVersion())
+			}
+			if role := info.Sections["replication"]["role"]; role != test.role {
+				t.Errorf("expected role %s, got %s", test.role, role)
+			}
+			if !reflect.DeepEqual(info.Replicas, test.replicas) {
+				t.Errorf("expected replicas %+v, got %+v", test.replicas, info.Replicas)
+			}
+			if !reflect.DeepEqual(info.Keyspace, test.keyspace) {
+				t.Errorf("expected keyspace %+v, got %+v", test.keyspace, info.Keyspace)
+			}
+		})
+	}
+
+	if _, err := ParseRedisInfo("# Replication\nslave0:ip=10.0.0.1,port=abc,state=online\n"); err == nil {
+		t.Error("expected an error for an invalid port")
+	}
+}
+
+func TestParseRedisInfoReplication(t *testing.T) {
+	data, err := os.ReadFile(filepath.Join("testdata", "info", "replica-low-priority.txt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	info, err := ParseRedisInfo(string(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := InfoReplication{
+		Role:                "slave",
+		MasterHost:          "redis-0.redis-headless.default.svc.cluster.local",
+		ReplID:              "6f2a1c9d7b3e4a5f8c0d1e2f3a4b5c6d7e8f9a0b",
+		MasterOffset:        1500,
+		SecondOffset:        -1,
+		Priority:            50,
+		ReplicaOffset:       1500,
+		MasterLinkStatus:    "down",
+		MasterLastIO:        -1,
+		MasterLinkDownSince: 12,
+	}
+	if info.Replication != expected {
+		t.Errorf("expected %+v, got %+v", expected, info.Replication)
+	}
+
+	master, err := ParseRedisInfo("# Replication\r\nrole:master\r\nmaster_repl_offset:10\r\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if master.Replication.Priority != defaultReplicaPriority || master.Replication.ReplicaOffset != -1 {
+		t.Errorf("expected the defaults of a master, got %+v", master.Replication)
+	}
+
+	if _, err := ParseRedisInfo("# Replication\r\nmaster_repl_offset:abc\r\n"); err == nil {
+		t.Error("expected an error for an invalid offset")
+	}
+}
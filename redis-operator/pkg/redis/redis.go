@@ -3,253 +3,197 @@ package k8sredis
 import (
 	"context"
 	"fmt"
-	"regexp"
-	"strings"
-	"time"
+	"strconv"
 
 	"crypto/tls"
-	"crypto/x509"
 
 	"github.com/go-logr/logr"
 	"github.com/redis/go-redis/v9"
-	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes"
 	v1 "redis.operator/api/v1"
 )
 
+// RedisCommandInfo is the reply of a pod. Info holds the fields of cluster replies, Redis the INFO replication of
+// redis pods
 type RedisCommandInfo struct {
 	Info     map[string]string
+	Redis    *RedisInfo
 	DNS      string
 	PodIndex int
+	Zone     string
 }
 
-func GetClient(ip string, port string, tlsConfig *tls.Config, password string, timeout time.Duration) *redis.Client {
-	opts := &redis.Options{
-		Addr:         ip + ":" + port,
-		Password:     password, // "supersecretpasswordnobodywillguess",
-		DB:           0,
-		TLSConfig:    tlsConfig,
-		ReadTimeout:  timeout,
-		WriteTimeout: timeout,
-		DialTimeout:  timeout,
+// GetReplicationInfo returns INFO replication of a redis pod
+func GetReplicationInfo(client *redis.Client, ctx context.Context) (*RedisInfo, error) {
+	info, err := client.Info(ctx, "Replication").Result()
+	if err != nil {
+		return nil, err
 	}
-	return redis.NewClient(opts)
-}
 
-func GetSentinelClient(ip string, port string, tlsConfig *tls.Config, password string, timeout time.Duration) *redis.SentinelClient {
-	opts := &redis.Options{
-		Addr:         ip + ":" + port,
-		Password:     password,
-		DB:           0,
-		TLSConfig:    tlsConfig,
-		WriteTimeout: timeout,
-		ReadTimeout:  timeout,
-		DialTimeout:  timeout,
-	}
-	return redis.NewSentinelClient(opts)
+	return ParseRedisInfo(info)
 }
 
-func ConvertRedisInterfaceToMap(value string) (map[string]string, error) {
-
-	redisMap := make(map[string]string)
-
-	// get the start and end of the sequence
-	sequenceStart := strings.Index(value, "map")
-	if sequenceStart == -1 {
-		return nil, fmt.Errorf("failed to find start of map sequence")
-	}
-
-	mapSequence := value[sequenceStart+4:]
-
-	regex := regexp.MustCompile(`[\[\]]`)
-	mapSequence = regex.ReplaceAllString(mapSequence, "")
-
-	lines := strings.Split(mapSequence, " ")
-	for _, line := range lines {
-		if strings.Contains(line, ":") {
-			values := strings.Split(line, ":")
-			if len(values) == 2 {
-				redisMap[values[0]] = values[1]
-			}
-		}
-	}
-	return redisMap, nil
-}
-
-func GetReplicationInfo(client *redis.Client, ctx context.Context) (map[string]string, error) {
-	info, err := client.Info(ctx, "Replication").Result()
+// GetSentinelMasters returns the view of masterName of every reachable sentinel. Sentinels not monitoring
+// masterName yet are skipped
+func GetSentinelMasters(ctx context.Context, k8Client kubernetes.Interface, instance *v1.RedisSentinel, tlsReplication *v1.RedisReplication, masterName string) ([]SentinelMaster, error) {
+	tlsConfig, password, err := getSentinelCredentials(ctx, k8Client, instance, tlsReplication)
 	if err != nil {
 		return nil, err
 	}
 
-	redisInfo := map[string]string{}
+	return probePods(ctx, instance.Spec.StatefulsetConfig.GetReplicas(), getProbeTimeout(), func(ctx context.Context, i int) (SentinelMaster, bool, error) {
+		podDNS := instance.GetPodDNS(i)
 
-	lines := strings.Split(info, "\r\n")
-	for _, line := range lines {
-		if strings.Contains(line, ":") {
-			values := strings.Split(line, ":")
-			if len(values) == 2 {
-				redisInfo[values[0]] = values[1]
+		redisClient, release := clients.getSentinelClient(podDNS, instance.GetRedisPort(), tlsConfig, password)
+		defer release()
+		if redisClient.Ping(ctx).Val() != "PONG" {
+			clients.invalidate(podDNS)
+			return SentinelMaster{}, false, nil
+		}
+
+		master, err := getSentinelMaster(ctx, redisClient, masterName)
+		if err != nil {
+			if isNoSuchMaster(err) {
+				return SentinelMaster{}, false, nil
 			}
+			return SentinelMaster{}, false, fmt.Errorf("error getting master %s from %s: %v", masterName, podDNS, err)
 		}
-	}
-	return redisInfo, nil
+		master.SentinelDNS = podDNS
+		master.SentinelPodIndex = i
+		return master, true, nil
+	})
 }
 
-func GetTLSConfig(ctx context.Context, k8Client kubernetes.Interface, secretName string, configmap map[string]string, namespace string) (*tls.Config, error) {
-	secret, err := k8Client.CoreV1().Secrets(namespace).Get(ctx, secretName, metav1.GetOptions{})
+// getSentinelMaster returns the master reported by SENTINEL MASTER
+func getSentinelMaster(ctx context.Context, client *redis.SentinelClient, masterName string) (SentinelMaster, error) {
+	fields, err := client.Master(ctx, masterName).Result()
 	if err != nil {
-		return nil, err
+		return SentinelMaster{}, err
 	}
-
-	tlsCert, ok := secret.Data["tls.crt"]
-	if !ok {
-		return nil, fmt.Errorf("ca.crt not found in secret")
+	master, err := NewSentinelMaster(fields)
+	if err != nil {
+		return SentinelMaster{}, err
 	}
+	return *master, nil
+}
 
-	tlsKey, ok := secret.Data["tls.key"]
-	if !ok {
-		return nil, fmt.Errorf("tls.key not found in secret")
-	}
+func getReplicationCredentials(ctx context.Context, k8Client kubernetes.Interface, instance *v1.RedisReplication) (*tls.Config, string, error) {
+	var tlsConfig *tls.Config = nil
+	var err error
 
-	caCert, ok := secret.Data["ca.crt"]
-	if !ok {
-		return nil, fmt.Errorf("ca.crt not found in secret")
-	}
-	caCertPool := x509.NewCertPool()
-	if !caCertPool.AppendCertsFromPEM(caCert) {
-		return nil, fmt.Errorf("failed to append CA cert")
+	if instance.Spec.TLSConfig != nil {
+		if tlsConfig, err = GetTLSConfig(ctx, k8Client, instance.Namespace, instance.GetTLSSecretName(), instance.Spec.TLSConfig); err != nil {
+			return nil, "", err
+		}
 	}
 
-	cert, err := tls.X509KeyPair(tlsCert, tlsKey)
+	password, err := instance.Spec.RedisConfig.GetValue("redis.conf", "requirepass")
 	if err != nil {
-		return nil, err
+		return nil, "", err
 	}
-
-	return &tls.Config{
-		Certificates: []tls.Certificate{cert},
-		MinVersion:   tls.VersionTLS13,
-		RootCAs:      caCertPool,
-	}, nil
+	return tlsConfig, password, nil
 }
 
-func GetSentinelMasters(ctx context.Context, k8Client kubernetes.Interface, instance *v1.RedisSentinel, replicaInstance *v1.RedisReplication) ([]RedisCommandInfo, error) {
-
-	tlsConfig := &tls.Config{}
-	password, err := instance.Spec.RedisConfig.GetValue("sentinel.conf", "requirepass")
+// GetReplicaInfo returns INFO replication of every reachable pod, ordered by pod index. Pods are probed in parallel
+// through cached clients
+func GetReplicaInfo(ctx context.Context, k8Client kubernetes.Interface, instance *v1.RedisReplication, reqLogger logr.Logger) ([]RedisCommandInfo, error) {
+	tlsConfig, password, err := getReplicationCredentials(ctx, k8Client, instance)
 	if err != nil {
 		return nil, err
 	}
 
-	if replicaInstance.Spec.TLSConfig != nil {
-		if tlsConfig, err = GetTLSConfig(ctx, k8Client, replicaInstance.Spec.TLSConfig.SecretName, replicaInstance.Spec.RedisConfig.Data, replicaInstance.Namespace); err != nil {
-			return nil, err
-		}
-	}
-
-	replicas := instance.Spec.StatefulsetConfig.GetReplicas()
-	replicaInfo := []RedisCommandInfo{}
-
-	for i := 0; i < replicas; i++ {
-		podDNS := fmt.Sprintf("%s-%d.%s.%s.svc.cluster.local", instance.Name, i, instance.GetHeadlessServiceName(), instance.Namespace)
-
-		redisClient := GetSentinelClient(podDNS, instance.GetRedisPort(), tlsConfig, password, time.Second*1)
-		defer redisClient.Close()
-
-		if redisClient.Ping(ctx).Val() != "PONG" {
-			continue
-		}
+	return probePods(ctx, instance.GetReplicas(), getProbeTimeout(), func(ctx context.Context, i int) (RedisCommandInfo, bool, error) {
+		podDNS := instance.GetPodDNS(i)
 
-		slicecmd := redisClient.Masters(ctx)
-		err = slicecmd.Err()
-		if err != nil {
-			return nil, err
+		redisClient, release := clients.getClient(podDNS, instance.GetRedisPort(), tlsConfig, password)
+		defer release()
+		if result := redisClient.Ping(ctx); result.Val() != "PONG" {
+			clients.invalidate(podDNS)
+			return RedisCommandInfo{}, false, nil // down, ignore
 		}
 
-		cmdMap, err := ConvertRedisInterfaceToMap(slicecmd.String())
+		info, err := GetReplicationInfo(redisClient, ctx)
 		if err != nil {
-			return nil, err
+			return RedisCommandInfo{}, false, err
 		}
-
-		replicaInfo = append(replicaInfo, RedisCommandInfo{Info: cmdMap, DNS: podDNS, PodIndex: i})
-	}
-	return replicaInfo, nil
+		return RedisCommandInfo{Redis: info, DNS: podDNS, PodIndex: i}, true, nil
+	})
 }
 
-func GetReplicaInfo(ctx context.Context, k8Client kubernetes.Interface, instance *v1.RedisReplication, reqLogger logr.Logger) ([]RedisCommandInfo, error) {
-
-	tlsConfig := &tls.Config{}
-	var err error
-
-	if instance.Spec.TLSConfig != nil {
-		if tlsConfig, err = GetTLSConfig(ctx, k8Client, instance.Spec.TLSConfig.SecretName, instance.Spec.RedisConfig.Data, instance.Namespace); err != nil {
-			return nil, err
-		}
+// SetReplicationMaster promotes masterDNS, then makes every other pod replicate it in parallel. The tls-replication
+// of the migration mode is applied to every pod first, so pods which haven't rolled yet follow the replication port
+func SetReplicationMaster(ctx context.Context, k8Client kubernetes.Interface, instance *v1.RedisReplication, masterDNS string, reqLogger logr.Logger) error {
+	tlsConfig, password, err := getReplicationCredentials(ctx, k8Client, instance)
+	if err != nil {
+		return err
 	}
 
-	password, err := instance.Spec.RedisConfig.GetValue("redis.conf", "requirepass")
+	mode := instance.Spec.TLSConfig.GetMode()
+	if err := setReplicationTLS(ctx, masterDNS, instance.GetRedisPort(), tlsConfig, password, mode); err != nil {
+		return err
+	}
+	err = clients.call(ctx, masterDNS, instance.GetRedisPort(), tlsConfig, password, func(ctx context.Context, client *redis.Client) error {
+		return client.SlaveOf(ctx, "NO", "ONE").Err()
+	})
 	if err != nil {
-		return nil, err
+		return fmt.Errorf("error setting replication master: %v", err)
 	}
 
-	replicas := instance.Spec.StatefulsetConfig.GetReplicas()
-	replicaInfo := []RedisCommandInfo{}
-
-	for i := 0; i < replicas; i++ {
-
-		podDNS := fmt.Sprintf("%s-%d.%s.%s.svc.cluster.local", instance.Name, i, instance.GetHeadlessServiceName(), instance.Namespace)
-
-		redisClient := GetClient(podDNS, instance.GetRedisPort(), tlsConfig, password, time.Second*1)
-		defer redisClient.Close()
-
-		if result := redisClient.Ping(ctx); result.Val() != "PONG" {
-			continue // down, ignore
+	_, err = probePods(ctx, instance.GetReplicas(), getProbeTimeout(), func(ctx context.Context, i int) (struct{}, bool, error) {
+		podDNS := instance.GetPodDNS(i)
+		if podDNS == masterDNS {
+			return struct{}{}, false, nil
 		}
 
-		info, err := GetReplicationInfo(redisClient, ctx)
+		if err := setReplicationTLS(ctx, podDNS, instance.GetRedisPort(), tlsConfig, password, mode); err != nil {
+			reqLogger.Info("failed to set tls-replication. slave is probably down ", "error", err)
+			return struct{}{}, true, nil
+		}
+		err := clients.call(ctx, podDNS, instance.GetRedisPort(), tlsConfig, password, func(ctx context.Context, client *redis.Client) error {
+			return client.SlaveOf(ctx, masterDNS, instance.GetReplicationPort()).Err()
+		})
 		if err != nil {
-			return nil, err
+			reqLogger.Info("failed to set replication master. slave is probably down ", "error", err)
 		}
-
-		replicaInfo = append(replicaInfo, RedisCommandInfo{Info: info, DNS: podDNS, PodIndex: i})
-	}
-	return replicaInfo, err
+		return struct{}{}, true, nil
+	})
+	return err
 }
 
-func SetReplicationMaster(ctx context.Context, k8Client kubernetes.Interface, instance *v1.RedisReplication, masterDNS string, reqLogger logr.Logger) error {
-	var tlsConfig *tls.Config = nil
-	var err error
-
-	if instance.Spec.TLSConfig != nil {
-		if tlsConfig, err = GetTLSConfig(ctx, k8Client, instance.Spec.TLSConfig.SecretName, instance.Spec.RedisConfig.Data, instance.Namespace); err != nil {
-			return err
-		}
-	}
-
-	password, err := instance.Spec.RedisConfig.GetValue("redis.conf", "requirepass")
+// SetReplicaPriority updates the replica-priority of every reachable pod. priorities is keyed by the pod index
+func SetReplicaPriority(ctx context.Context, k8Client kubernetes.Interface, instance *v1.RedisReplication, priorities map[int]int, reqLogger logr.Logger) error {
+	tlsConfig, password, err := getReplicationCredentials(ctx, k8Client, instance)
 	if err != nil {
 		return err
 	}
 
-	replicas := instance.Spec.StatefulsetConfig.GetReplicas()
-	for i := 0; i < replicas; i++ {
-
-		podDNS := fmt.Sprintf("%s-%d.%s.%s.svc.cluster.local", instance.Name, i, instance.GetHeadlessServiceName(), instance.Namespace)
-
-		redisClient := GetClient(podDNS, instance.GetRedisPort(), tlsConfig, password, time.Second*1)
-		defer redisClient.Close()
+	_, err = probePods(ctx, instance.GetReplicas(), getProbeTimeout(), func(ctx context.Context, index int) (int, bool, error) {
+		priority, ok := priorities[index]
+		if !ok {
+			return 0, false, nil
+		}
+		podDNS := instance.GetPodDNS(index)
 
-		if masterDNS == podDNS {
-			if err := redisClient.SlaveOf(ctx, "NO", "ONE").Err(); err != nil {
-				return fmt.Errorf("error setting replication master: %v", err)
-			}
+		redisClient, release := clients.getClient(podDNS, instance.GetRedisPort(), tlsConfig, password)
+		defer release()
+		current, err := redisClient.ConfigGet(ctx, "replica-priority").Result()
+		if err != nil {
+			clients.invalidate(podDNS)
+			reqLogger.Info("failed to get replica-priority. pod is probably down", "pod", podDNS, "error", err)
+			return 0, false, nil
+		}
+		if current["replica-priority"] == strconv.Itoa(priority) {
+			return 0, false, nil
+		}
 
-		} else {
-			if err := redisClient.SlaveOf(ctx, masterDNS, instance.GetRedisPort()).Err(); err != nil {
-				reqLogger.Info("failed to set replication master. slave is probably down ", "error", err)
+		if err := redisClient.ConfigSet(ctx, "replica-priority", strconv.Itoa(priority)).Err(); err != nil {
+			if !isErrorReply(err) {
+				clients.invalidate(podDNS)
 			}
+			return 0, false, fmt.Errorf("error setting replica-priority of %s: %v", podDNS, err)
 		}
-	}
-
-	return nil
+		reqLogger.Info("updated replica-priority", "pod", podDNS, "priority", priority)
+		return priority, true, nil
+	})
+	return err
 }
@@ -0,0 +1,230 @@
+package k8sredis
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/redis/go-redis/v9"
+	"k8s.io/client-go/kubernetes"
+	v1 "redis.operator/api/v1"
+)
+
+// SlotMigration moves a slot from the master of a shard to the master of another shard
+type SlotMigration struct {
+	Slot        int
+	SourceShard int
+	TargetShard int
+	Source      *ClusterNodeInfo
+	Target      *ClusterNodeInfo
+}
+
+// GetShardSlotTargets returns the number of slots every deployed shard should own. Shards beyond shards are
+// being removed and own none
+func GetShardSlotTargets(shards int, deployedShards int) []int {
+	targets := make([]int, deployedShards)
+	for shard := 0; shard < shards && shard < deployedShards; shard++ {
+		targets[shard] = v1.ClusterSlots / shards
+		if shard < v1.ClusterSlots%shards {
+			targets[shard]++
+		}
+	}
+	return targets
+}
+
+// PlanSlotMigrations returns the slot migrations balancing the slots over shards. Shards owning more slots than
+// their share give away their highest slots, starting with the shards being removed. Fails when a deployed shard
+// has no reachable master
+func PlanSlotMigrations(nodes []ClusterNodeInfo, shards int, deployedShards int) ([]SlotMigration, error) {
+	masters := GetShardMasters(nodes)
+	for shard := 0; shard < deployedShards; shard++ {
+		if _, ok := masters[shard]; !ok {
+			return nil, fmt.Errorf("shard %d has no reachable master", shard)
+		}
+	}
+
+	owned := make([][]int, deployedShards)
+	for shard := 0; shard < deployedShards; shard++ {
+		for _, slots := range masters[shard].Myself.Slots {
+			for slot := slots.Start; slot <= slots.End; slot++ {
+				owned[shard] = append(owned[shard], slot)
+			}
+		}
+	}
+
+	targets := GetShardSlotTargets(shards, deployedShards)
+
+	type excessSlot struct {
+		slot  int
+		shard int
+	}
+	excess := []excessSlot{}
+	for shard := deployedShards - 1; shard >= 0; shard-- {
+		for i := len(owned[shard]) - 1; i >= targets[shard]; i-- {
+			excess = append(excess, excessSlot{slot: owned[shard][i], shard: shard})
+		}
+	}
+
+	migrations := []SlotMigration{}
+	for shard := 0; shard < deployedShards; shard++ {
+		for missing := targets[shard] - len(owned[shard]); missing > 0 && len(excess) > 0; missing-- {
+			slot := excess[0]
+			excess = excess[1:]
+			migrations = append(migrations, SlotMigration{
+				Slot:        slot.slot,
+				SourceShard: slot.shard,
+				TargetShard: shard,
+				Source:      masters[slot.shard],
+				Target:      masters[shard],
+			})
+		}
+	}
+	return migrations, nil
+}
+
+// ResumeSlotMigration returns the migration recorded in checkpoint. nil when the source no longer owns the slot.
+// Target is nil when the target node left the cluster, the migration has to be aborted
+func ResumeSlotMigration(nodes []ClusterNodeInfo, checkpoint *v1.RedisClusterReshardingStatus) *SlotMigration {
+	var source, target *ClusterNodeInfo
+	for i := range nodes {
+		switch nodes[i].Myself.ID {
+		case checkpoint.SourceID:
+			source = &nodes[i]
+		case checkpoint.TargetID:
+			target = &nodes[i]
+		}
+	}
+	if source == nil {
+		return nil
+	}
+
+	for _, slots := range source.Myself.Slots {
+		if checkpoint.Slot >= slots.Start && checkpoint.Slot <= slots.End {
+			return &SlotMigration{
+				Slot:        checkpoint.Slot,
+				SourceShard: checkpoint.SourceShard,
+				TargetShard: checkpoint.TargetShard,
+				Source:      source,
+				Target:      target,
+			}
+		}
+	}
+	return nil
+}
+
+// MigrateSlot moves the keys of a slot in batches of MIGRATE and assigns the slot to the target. Setting the
+// importing and migrating state again is safe, so an interrupted migration is resumed by calling MigrateSlot
+// again. Returns the number of keys moved
+func MigrateSlot(ctx context.Context, k8Client kubernetes.Interface, instance *v1.RedisCluster, nodes []ClusterNodeInfo, migration SlotMigration, reqLogger logr.Logger) (int, error) {
+	tlsConfig, password, err := getClusterCredentials(ctx, k8Client, instance)
+	if err != nil {
+		return 0, err
+	}
+
+	timeout := instance.Spec.Resharding.GetTimeout()
+	port := instance.GetRedisPort()
+	slot := migration.Slot
+	sourceID := migration.Source.Myself.ID
+	targetID := migration.Target.Myself.ID
+	setSlotOn := func(dns string, state string, nodeID string) error {
+		return clients.call(ctx, dns, port, tlsConfig, password, func(ctx context.Context, client *redis.Client) error {
+			return setSlot(ctx, client, slot, state, nodeID).Err()
+		})
+	}
+
+	if err := setSlotOn(migration.Target.DNS, "IMPORTING", sourceID); err != nil {
+		return 0, fmt.Errorf("error importing slot %d on %s: %v", slot, migration.Target.DNS, err)
+	}
+	if err := setSlotOn(migration.Source.DNS, "MIGRATING", targetID); err != nil {
+		return 0, fmt.Errorf("error migrating slot %d on %s: %v", slot, migration.Source.DNS, err)
+	}
+
+	moved := 0
+	for {
+		var keys []string
+		err := clients.call(ctx, migration.Source.DNS, port, tlsConfig, password, func(ctx context.Context, client *redis.Client) error {
+			var err error
+			keys, err = client.ClusterGetKeysInSlot(ctx, slot, instance.Spec.Resharding.GetKeysPerBatch()).Result()
+			return err
+		})
+		if err != nil {
+			return moved, fmt.Errorf("error getting keys of slot %d on %s: %v", slot, migration.Source.DNS, err)
+		}
+		if len(keys) == 0 {
+			break
+		}
+
+		args := []interface{}{"MIGRATE", migration.Target.DNS, instance.GetRedisPort(), "", 0, timeout.Milliseconds()}
+		if password != "" {
+			args = append(args, "AUTH", password)
+		}
+		args = append(args, "KEYS")
+		for _, key := range keys {
+			args = append(args, key)
+		}
+		if err := migrateKeys(ctx, migration.Source.DNS, port, tlsConfig, password, timeout, args); err != nil {
+			return moved, fmt.Errorf("error moving keys of slot %d to %s: %v", slot, migration.Target.DNS, err)
+		}
+		moved += len(keys)
+	}
+
+	// the target first, so the slot always has an owner
+	if err := setSlotOn(migration.Target.DNS, "NODE", targetID); err != nil {
+		return moved, fmt.Errorf("error assigning slot %d to %s: %v", slot, migration.Target.DNS, err)
+	}
+	if err := setSlotOn(migration.Source.DNS, "NODE", targetID); err != nil {
+		return moved, fmt.Errorf("error assigning slot %d to %s on %s: %v", slot, migration.Target.DNS, migration.Source.DNS, err)
+	}
+
+	// the other masters learn the new owner by gossip, telling them avoids redirects to the source meanwhile
+	for _, node := range nodes {
+		if !node.Myself.IsMaster() || node.Myself.ID == sourceID || node.Myself.ID == targetID {
+			continue
+		}
+		if err := setSlotOn(node.DNS, "NODE", targetID); err != nil {
+			reqLogger.Info("failed to announce the new owner of a slot", "slot", slot, "pod", node.DNS, "error", err)
+		}
+	}
+	return moved, nil
+}
+
+// AbortSlotMigration clears the migrating state of a slot on the source, used when the target left the cluster
+func AbortSlotMigration(ctx context.Context, k8Client kubernetes.Interface, instance *v1.RedisCluster, source *ClusterNodeInfo, slot int) error {
+	tlsConfig, password, err := getClusterCredentials(ctx, k8Client, instance)
+	if err != nil {
+		return err
+	}
+
+	err = clients.call(ctx, source.DNS, instance.GetRedisPort(), tlsConfig, password, func(ctx context.Context, client *redis.Client) error {
+		return setSlot(ctx, client, slot, "STABLE", "").Err()
+	})
+	if err != nil {
+		return fmt.Errorf("error aborting migration of slot %d on %s: %v", slot, source.DNS, err)
+	}
+	return nil
+}
+
+// migrateKeys sends a MIGRATE of args from source, the call may take the migration timeout on top of the probe
+// timeout
+func migrateKeys(ctx context.Context, source string, port string, tlsConfig *tls.Config, password string, timeout time.Duration, args []interface{}) error {
+	migrateCtx, cancel := context.WithTimeout(ctx, timeout+getProbeTimeout())
+	defer cancel()
+
+	client, release := clients.getMigrationClient(source, port, tlsConfig, password, timeout)
+	err := client.Do(migrateCtx, args...).Err()
+	release()
+	if err != nil && !isErrorReply(err) {
+		clients.invalidate(source)
+	}
+	return err
+}
+
+// setSlot sends CLUSTER SETSLOT, nodeID is omitted for STABLE
+func setSlot(ctx context.Context, client *redis.Client, slot int, state string, nodeID string) *redis.Cmd {
+	if nodeID == "" {
+		return client.Do(ctx, "CLUSTER", "SETSLOT", slot, state)
+	}
+	return client.Do(ctx, "CLUSTER", "SETSLOT", slot, state, nodeID)
+}
@@ -0,0 +1,112 @@
+package k8sredis
+
+import (
+	"testing"
+
+	v1 "redis.operator/api/v1"
+)
+
+func TestGetShardSlotTargets(t *testing.T) {
+	targets := GetShardSlotTargets(3, 4)
+	expected := []int{5462, 5461, 5461, 0}
+	for shard := range expected {
+		if targets[shard] != expected[shard] {
+			t.Fatalf("expected %v, got %v", expected, targets)
+		}
+	}
+}
+
+func TestPlanSlotMigrations(t *testing.T) {
+	tests := []struct {
+		name           string
+		shards         int
+		deployedShards int
+		slots          []string // slots owned by the master of every deployed shard
+		migrations     int
+		moved          map[int]int // slots moved into every shard, negative when moved out
+	}{
+		{
+			name:           "balanced cluster",
+			shards:         3,
+			deployedShards: 3,
+			slots:          []string{"0-5461", "5462-10922", "10923-16383"},
+			migrations:     0,
+		},
+		{
+			name:           "added shard receives slots of every shard",
+			shards:         4,
+			deployedShards: 4,
+			slots:          []string{"0-5461", "5462-10922", "10923-16383", ""},
+			migrations:     4096,
+			moved:          map[int]int{0: -1366, 1: -1365, 2: -1365, 3: 4096},
+		},
+		{
+			name:           "removed shard is drained",
+			shards:         3,
+			deployedShards: 4,
+			slots:          []string{"0-4095", "4096-8191", "8192-12287", "12288-16383"},
+			migrations:     4096,
+			moved:          map[int]int{0: 1366, 1: 1365, 2: 1365, 3: -4096},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			testNodes := []testNode{}
+			for shard, slots := range test.slots {
+				testNodes = append(testNodes, testNode{shard: shard, index: 0, id: string(rune('a' + shard)), role: "master", slots: slots})
+			}
+
+			migrations, err := PlanSlotMigrations(getTestClusterNodes(testNodes), test.shards, test.deployedShards)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(migrations) != test.migrations {
+				t.Fatalf("expected %d migrations, got %d", test.migrations, len(migrations))
+			}
+
+			moved := map[int]int{}
+			for _, migration := range migrations {
+				if migration.Source.Shard != migration.SourceShard || migration.Target.Shard != migration.TargetShard {
+					t.Fatalf("slot %d is moved between the wrong masters", migration.Slot)
+				}
+				moved[migration.SourceShard]--
+				moved[migration.TargetShard]++
+			}
+			for shard, expected := range test.moved {
+				if moved[shard] != expected {
+					t.Errorf("expected shard %d to move %d slots, got %d", shard, expected, moved[shard])
+				}
+			}
+		})
+	}
+
+	t.Run("shard without a master", func(t *testing.T) {
+		nodes := getTestClusterNodes([]testNode{
+			{shard: 0, index: 0, id: "a", role: "master", slots: "0-16383"},
+		})
+		if _, err := PlanSlotMigrations(nodes, 2, 2); err == nil {
+			t.Fatal("expected an error")
+		}
+	})
+}
+
+func TestResumeSlotMigration(t *testing.T) {
+	nodes := getTestClusterNodes([]testNode{
+		{shard: 0, index: 0, id: "a", role: "master", slots: "0-8191"},
+		{shard: 1, index: 0, id: "b", role: "master", slots: "8192-16383"},
+	})
+
+	migration := ResumeSlotMigration(nodes, &v1.RedisClusterReshardingStatus{Slot: 100, SourceID: "a", TargetID: "b", TargetShard: 1})
+	if migration == nil || migration.Source.Myself.ID != "a" || migration.Target.Myself.ID != "b" {
+		t.Fatalf("expected slot 100 to be resumed, got %+v", migration)
+	}
+
+	if migration := ResumeSlotMigration(nodes, &v1.RedisClusterReshardingStatus{Slot: 100, SourceID: "a", TargetID: "gone"}); migration == nil || migration.Target != nil {
+		t.Fatalf("expected a migration without target, got %+v", migration)
+	}
+
+	if migration := ResumeSlotMigration(nodes, &v1.RedisClusterReshardingStatus{Slot: 9000, SourceID: "a", TargetID: "b"}); migration != nil {
+		t.Fatalf("expected a finished migration, got %+v", migration)
+	}
+}
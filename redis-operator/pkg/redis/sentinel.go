@@ -1,6 +1,15 @@
 package k8sredis
 
 import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/go-logr/logr"
+	"k8s.io/client-go/kubernetes"
 	v1 "redis.operator/api/v1"
 )
 
@@ -34,3 +43,192 @@ func GetSentinelConfigMap(config *v1.RedisSentinelConfiguration) *v1.RedisSentin
 	}
 	return config
 }
+
+// SentinelMonitor is a master the sentinels have to monitor under a name
+type SentinelMonitor struct {
+	Name   string
+	DNS    string
+	Port   string
+	Quorum int
+	// options applied with SENTINEL SET. Options not reported by SENTINEL MASTER, like auth-pass, are only set
+	// when the master is added
+	Settings map[string]string
+}
+
+// getOptions returns the settings and the quorum of the master
+func (m SentinelMonitor) getOptions() map[string]string {
+	options := map[string]string{}
+	for option, value := range m.Settings {
+		options[option] = value
+	}
+	options["quorum"] = strconv.Itoa(m.Quorum)
+	return options
+}
+
+// SentinelMonitorChanges lists the master names added to, updated on and removed from at least one sentinel
+type SentinelMonitorChanges struct {
+	Added   []string
+	Updated []string
+	Removed []string
+}
+
+func getSentinelCredentials(ctx context.Context, k8Client kubernetes.Interface, instance *v1.RedisSentinel, tlsReplication *v1.RedisReplication) (*tls.Config, string, error) {
+	var tlsConfig *tls.Config = nil
+	var err error
+
+	if settings := instance.GetTLSConfig(tlsReplication); settings != nil {
+		if tlsConfig, err = GetTLSConfig(ctx, k8Client, instance.Namespace, instance.GetTLSSecretName(tlsReplication), settings); err != nil {
+			return nil, "", err
+		}
+	}
+
+	password, err := instance.Spec.RedisConfig.GetValue("sentinel.conf", "requirepass")
+	if err != nil {
+		return nil, "", err
+	}
+	return tlsConfig, password, nil
+}
+
+// isNoSuchMaster returns true for the error of sentinels asked about a master they do not monitor
+func isNoSuchMaster(err error) bool {
+	return err != nil && strings.Contains(err.Error(), "No such master")
+}
+
+// GetSentinelSetOptions returns the options of monitor, the quorum included, differing from the ones reported by
+// SENTINEL MASTER, sorted by name. Options missing from master are only returned when added is true
+func GetSentinelSetOptions(master map[string]string, monitor SentinelMonitor, added bool) []string {
+	options := []string{}
+	for option, value := range monitor.getOptions() {
+		current, ok := master[option]
+		if (ok && current != value) || (!ok && added) {
+			options = append(options, option)
+		}
+	}
+	sort.Strings(options)
+	return options
+}
+
+// UpdateSentinelMonitors converges the masters monitored by every reachable sentinel. Masters missing from a
+// sentinel are added with SENTINEL MONITOR, changed options are applied with SENTINEL SET and checked with SENTINEL
+// MASTER, the removed master names are dropped with SENTINEL REMOVE. Masters monitored under another address are
+// left alone, the sentinels may have failed them over. The tls-replication of tlsReplication is applied to every
+// sentinel first, so sentinels which haven't rolled yet reach the masters on the replication port
+func UpdateSentinelMonitors(ctx context.Context, k8Client kubernetes.Interface, instance *v1.RedisSentinel, tlsReplication *v1.RedisReplication, monitors []SentinelMonitor, removed []string, reqLogger logr.Logger) (SentinelMonitorChanges, error) {
+	changes := SentinelMonitorChanges{}
+	tlsConfig, password, err := getSentinelCredentials(ctx, k8Client, instance, tlsReplication)
+	if err != nil {
+		return changes, err
+	}
+
+	// each sentinel is updated within the probe timeout, so an unresponsive one doesn't hold up the others
+	results, err := probePods(ctx, instance.Spec.StatefulsetConfig.GetReplicas(), getProbeTimeout(), func(ctx context.Context, i int) (SentinelMonitorChanges, bool, error) {
+		podChanges := SentinelMonitorChanges{}
+		podDNS := instance.GetPodDNS(i)
+
+		sentinelClient, release := clients.getSentinelClient(podDNS, instance.GetRedisPort(), tlsConfig, password)
+		defer release()
+		if sentinelClient.Ping(ctx).Val() != "PONG" {
+			clients.invalidate(podDNS)
+			return podChanges, false, nil
+		}
+
+		if tlsReplication != nil {
+			if err := setReplicationTLS(ctx, podDNS, instance.GetRedisPort(), tlsConfig, password, tlsReplication.Spec.TLSConfig.GetMode()); err != nil {
+				return podChanges, false, err
+			}
+		}
+
+		reply, err := sentinelClient.Masters(ctx).Result()
+		if err != nil {
+			return podChanges, false, fmt.Errorf("error getting masters of %s: %v", podDNS, err)
+		}
+		masters, err := ParseSentinelMasters(reply)
+		if err != nil {
+			return podChanges, false, fmt.Errorf("error parsing masters of %s: %v", podDNS, err)
+		}
+		known := map[string]SentinelMaster{}
+		for _, master := range masters {
+			known[master.Name] = master
+		}
+
+		for _, name := range removed {
+			if _, ok := known[name]; !ok {
+				continue
+			}
+			if err := sentinelClient.Remove(ctx, name).Err(); err != nil && !isNoSuchMaster(err) {
+				return podChanges, false, fmt.Errorf("error removing master %s from %s: %v", name, podDNS, err)
+			}
+			podChanges.Removed = append(podChanges.Removed, name)
+		}
+
+		for _, monitor := range monitors {
+			master, ok := known[monitor.Name]
+			isNew := !ok
+			if isNew {
+				if err := sentinelClient.Monitor(ctx, monitor.Name, monitor.DNS, monitor.Port, strconv.Itoa(monitor.Quorum)).Err(); err != nil {
+					return podChanges, false, fmt.Errorf("error monitoring %s on %s: %v", monitor.Name, podDNS, err)
+				}
+				podChanges.Added = append(podChanges.Added, monitor.Name)
+				reqLogger.Info("added master to sentinel", "master", monitor.Name, "address", monitor.DNS, "pod", podDNS)
+				if master, err = getSentinelMaster(ctx, sentinelClient, monitor.Name); err != nil {
+					return podChanges, false, fmt.Errorf("error getting master %s from %s: %v", monitor.Name, podDNS, err)
+				}
+			}
+
+			options := GetSentinelSetOptions(master.Fields, monitor, isNew)
+			if len(options) == 0 {
+				continue
+			}
+			for _, option := range options {
+				if err := sentinelClient.Set(ctx, monitor.Name, option, monitor.getOptions()[option]).Err(); err != nil {
+					return podChanges, false, fmt.Errorf("error setting %s of %s on %s: %v", option, monitor.Name, podDNS, err)
+				}
+			}
+			if !isNew {
+				podChanges.Updated = append(podChanges.Updated, monitor.Name)
+			}
+
+			// sentinels reply OK to values they clamp or ignore
+			master, err = getSentinelMaster(ctx, sentinelClient, monitor.Name)
+			if err != nil {
+				return podChanges, false, fmt.Errorf("error getting master %s from %s: %v", monitor.Name, podDNS, err)
+			}
+			if unapplied := GetSentinelSetOptions(master.Fields, monitor, false); len(unapplied) > 0 {
+				return podChanges, false, fmt.Errorf("sentinel %s did not apply %s of master %s", podDNS, strings.Join(unapplied, ", "), monitor.Name)
+			}
+		}
+		return podChanges, true, nil
+	})
+	if err != nil {
+		return changes, err
+	}
+
+	added := map[string]bool{}
+	updated := map[string]bool{}
+	dropped := map[string]bool{}
+	for _, result := range results {
+		for _, name := range result.Added {
+			added[name] = true
+		}
+		for _, name := range result.Updated {
+			updated[name] = true
+		}
+		for _, name := range result.Removed {
+			dropped[name] = true
+		}
+	}
+
+	for _, monitor := range monitors {
+		if added[monitor.Name] {
+			changes.Added = append(changes.Added, monitor.Name)
+		} else if updated[monitor.Name] {
+			changes.Updated = append(changes.Updated, monitor.Name)
+		}
+	}
+	for _, name := range removed {
+		if dropped[name] {
+			changes.Removed = append(changes.Removed, name)
+		}
+	}
+	return changes, nil
+}
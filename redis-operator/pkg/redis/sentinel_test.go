@@ -0,0 +1,164 @@
+package k8sredis
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestGetSentinelSetOptions(t *testing.T) {
+	monitor := SentinelMonitor{
+		Name:   "mymaster",
+		Quorum: 2,
+		Settings: map[string]string{
+			"down-after-milliseconds": "5000",
+			"failover-timeout":        "10000",
+			"auth-pass":               "secret",
+		},
+	}
+	master := map[string]string{
+		"name":                    "mymaster",
+		"quorum":                  "3",
+		"down-after-milliseconds": "30000",
+		"failover-timeout":        "10000",
+	}
+
+	if options := GetSentinelSetOptions(master, monitor, false); !reflect.DeepEqual(options, []string{"down-after-milliseconds", "quorum"}) {
+		t.Errorf("expected only the changed options, got %v", options)
+	}
+	if options := GetSentinelSetOptions(nil, monitor, true); !reflect.DeepEqual(options, []string{"auth-pass", "down-after-milliseconds", "failover-timeout", "quorum"}) {
+		t.Errorf("expected every option of an added master, got %v", options)
+	}
+}
+
+// loadSentinelReply reads a recorded sentinel reply of testdata/sentinel. RESP3 maps are converted to the type
+// go-redis returns for them
+func loadSentinelReply(t *testing.T, server string, command string) []interface{} {
+	t.Helper()
+
+	data, err := os.ReadFile(filepath.Join("testdata", "sentinel", server, command+".json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	reply := []interface{}{}
+	if err := json.Unmarshal(data, &reply); err != nil {
+		t.Fatal(err)
+	}
+	for i, entry := range reply {
+		if fields, ok := entry.(map[string]interface{}); ok {
+			converted := map[interface{}]interface{}{}
+			for key, value := range fields {
+				converted[key] = value
+			}
+			reply[i] = converted
+		}
+	}
+	return reply
+}
+
+func TestParseSentinelMasters(t *testing.T) {
+	tests := []struct {
+		server string
+		ip     string
+	}{
+		{server: "redis6", ip: "fd00:10:244::12"},
+		{server: "redis7", ip: "redis-0.redis-headless.default.svc.cluster.local"},
+		{server: "valkey8", ip: "10.244.0.12"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.server, func(t *testing.T) {
+			masters, err := ParseSentinelMasters(loadSentinelReply(t, test.server, "masters"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(masters) != 2 {
+				t.Fatalf("expected 2 masters, got %+v", masters)
+			}
+
+			master := masters[0]
+			if master.Name != "mymaster" || master.IP != test.ip || master.Port != 6379 || master.Quorum != 2 || master.NumReplicas != 2 || master.NumOtherSentinels != 2 || master.IsDown() {
+				t.Errorf("unexpected master %+v", master)
+			}
+			if down := masters[1]; down.Name != "other" || down.DownTime.Milliseconds() != 1500 || !down.HasFlag("s_down") || !down.IsDown() {
+				t.Errorf("expected the other master to be down, got %+v", down)
+			}
+		})
+	}
+
+	if _, err := ParseSentinelMasters([]interface{}{[]interface{}{"name"}}); err == nil {
+		t.Error("expected an error for an odd number of fields")
+	}
+	if _, err := ParseSentinelMasters([]interface{}{[]interface{}{"name", "mymaster", "quorum", "two"}}); err == nil {
+		t.Error("expected an error for an invalid quorum")
+	}
+}
+
+func TestParseSentinelPeers(t *testing.T) {
+	tests := []struct {
+		server    string
+		replicas  []string
+		sentinels []string
+	}{
+		{server: "redis6", replicas: []string{"10.244.0.13", "10.244.0.14"}, sentinels: []string{"10.244.0.21", "10.244.0.22"}},
+		{
+			server:    "redis7",
+			replicas:  []string{"redis-1.redis-headless.default.svc.cluster.local", "fd00:10:244::14"},
+			sentinels: []string{"sentinel-1.sentinel-headless.default.svc.cluster.local", "sentinel-2.sentinel-headless.default.svc.cluster.local"},
+		},
+		{server: "valkey8", replicas: []string{"10.244.0.13", "10.244.0.14"}, sentinels: []string{"10.244.0.21", "10.244.0.22"}},
+	}
+
+	// go-redis returns SENTINEL REPLICAS and SENTINEL SENTINELS as field maps
+	toFields := func(reply []interface{}) []map[string]string {
+		fields := []map[string]string{}
+		for _, entry := range reply {
+			peer := map[string]string{}
+			for key, value := range entry.(map[interface{}]interface{}) {
+				peer[fmt.Sprint(key)] = fmt.Sprint(value)
+			}
+			fields = append(fields, peer)
+		}
+		return fields
+	}
+
+	for _, test := range tests {
+		t.Run(test.server, func(t *testing.T) {
+			replicas, err := ParseSentinelPeers(toFields(loadSentinelReply(t, test.server, "replicas")))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(replicas) != len(test.replicas) {
+				t.Fatalf("expected %d replicas, got %+v", len(test.replicas), replicas)
+			}
+			for i, replica := range replicas {
+				if replica.IP != test.replicas[i] || replica.Port != 6379 || replica.Name != test.replicas[i]+":6379" || !replica.HasFlag("slave") {
+					t.Errorf("unexpected replica %+v", replica)
+				}
+			}
+
+			sentinels, err := ParseSentinelPeers(toFields(loadSentinelReply(t, test.server, "sentinels")))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(sentinels) != len(test.sentinels) {
+				t.Fatalf("expected %d sentinels, got %+v", len(test.sentinels), sentinels)
+			}
+			for i, sentinel := range sentinels {
+				if sentinel.IP != test.sentinels[i] || sentinel.Port != 26379 || !sentinel.HasFlag("sentinel") {
+					t.Errorf("unexpected sentinel %+v", sentinel)
+				}
+			}
+			if sentinels[1].DownTime.Milliseconds() != 3000 || !sentinels[1].HasFlag("s_down") {
+				t.Errorf("expected the second sentinel to be down, got %+v", sentinels[1])
+			}
+		})
+	}
+
+	if _, err := ParseSentinelPeers([]map[string]string{{"name": "10.0.0.1:6379", "port": "abc"}}); err == nil {
+		t.Error("expected an error for an invalid port")
+	}
+}
@@ -0,0 +1,102 @@
+package k8sredis
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"k8s.io/client-go/kubernetes"
+	v1 "redis.operator/api/v1"
+)
+
+// SickSentinel is a sentinel reporting a master down while the operator reaches it
+type SickSentinel struct {
+	PodIndex   int
+	DNS        string
+	MasterName string
+	DownTime   time.Duration
+}
+
+// FindSickSentinels returns the sentinels reporting masterName down for longer than threshold. No sentinel is
+// returned when the master is the sick one, either because the operator can't reach it or because a quorum of
+// sentinels agrees it is down
+func FindSickSentinels(sentinelMasters []SentinelMaster, masterName string, masterReachable bool, quorum int, threshold time.Duration) []SickSentinel {
+	if !masterReachable {
+		return nil
+	}
+
+	sick := []SickSentinel{}
+	down := 0
+	for _, master := range sentinelMasters {
+		if !master.IsDown() {
+			continue
+		}
+		down++
+
+		if master.DownTime > threshold {
+			sick = append(sick, SickSentinel{
+				PodIndex:   master.SentinelPodIndex,
+				DNS:        master.SentinelDNS,
+				MasterName: masterName,
+				DownTime:   master.DownTime,
+			})
+		}
+	}
+
+	if down >= quorum {
+		return nil
+	}
+	return sick
+}
+
+// CanRestartSentinel returns true when restarting one of the reachable sentinels keeps a quorum of every monitored
+// master
+func CanRestartSentinel(instance *v1.RedisSentinel, reachable int) bool {
+	for _, monitor := range instance.GetMonitors() {
+		if reachable-1 < monitor.Quorum {
+			return false
+		}
+	}
+	return true
+}
+
+func getSentinelPodClient(ctx context.Context, k8Client kubernetes.Interface, instance *v1.RedisSentinel, tlsReplication *v1.RedisReplication, podIndex int) (*redis.SentinelClient, func(), string, error) {
+	tlsConfig, password, err := getSentinelCredentials(ctx, k8Client, instance, tlsReplication)
+	if err != nil {
+		return nil, nil, "", err
+	}
+
+	podDNS := instance.GetPodDNS(podIndex)
+	sentinelClient, release := clients.getSentinelClient(podDNS, instance.GetRedisPort(), tlsConfig, password)
+	return sentinelClient, release, podDNS, nil
+}
+
+// ResetSentinelMaster makes a sentinel forget the state of masterName, its replicas and the other sentinels are
+// discovered again
+func ResetSentinelMaster(ctx context.Context, k8Client kubernetes.Interface, instance *v1.RedisSentinel, tlsReplication *v1.RedisReplication, podIndex int, masterName string) error {
+	sentinelClient, release, podDNS, err := getSentinelPodClient(ctx, k8Client, instance, tlsReplication, podIndex)
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	if err := sentinelClient.Reset(ctx, masterName).Err(); err != nil {
+		return fmt.Errorf("error resetting master %s on %s: %v", masterName, podDNS, err)
+	}
+	return nil
+}
+
+// RemoveSentinelMaster makes a sentinel stop monitoring masterName, so it is monitored again from scratch
+func RemoveSentinelMaster(ctx context.Context, k8Client kubernetes.Interface, instance *v1.RedisSentinel, tlsReplication *v1.RedisReplication, podIndex int, masterName string) error {
+	sentinelClient, release, podDNS, err := getSentinelPodClient(ctx, k8Client, instance, tlsReplication, podIndex)
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	if err := sentinelClient.Remove(ctx, masterName).Err(); err != nil && !isNoSuchMaster(err) {
+		return fmt.Errorf("error removing master %s from %s: %v", masterName, podDNS, err)
+	}
+	return nil
+}
@@ -0,0 +1,45 @@
+package k8sredis
+
+import (
+	"testing"
+	"time"
+
+	v1 "redis.operator/api/v1"
+)
+
+func TestFindSickSentinels(t *testing.T) {
+	sentinelMasters := []SentinelMaster{
+		{SentinelPodIndex: 0, IP: "redis-0"},
+		{SentinelPodIndex: 1, IP: "redis-0", DownTime: 30 * time.Second},
+		{SentinelPodIndex: 2, IP: "redis-0", DownTime: time.Second},
+	}
+
+	sick := FindSickSentinels(sentinelMasters, "mymaster", true, 3, 20*time.Second)
+	if len(sick) != 1 || sick[0].PodIndex != 1 || sick[0].DownTime != 30*time.Second {
+		t.Fatalf("expected sentinel 1 to be sick, got %+v", sick)
+	}
+
+	if sick := FindSickSentinels(sentinelMasters, "mymaster", false, 3, 20*time.Second); len(sick) != 0 {
+		t.Errorf("expected no sick sentinel when the master is unreachable, got %+v", sick)
+	}
+	if sick := FindSickSentinels(sentinelMasters, "mymaster", true, 2, 20*time.Second); len(sick) != 0 {
+		t.Errorf("expected no sick sentinel when a quorum agrees the master is down, got %+v", sick)
+	}
+}
+
+func TestCanRestartSentinel(t *testing.T) {
+	instance := &v1.RedisSentinel{Spec: v1.RedisSentinelSpec{
+		RedisSentinelQuorum: 2,
+		Monitors: []v1.RedisSentinelMonitor{
+			{MasterName: "a", RedisReplicationName: "a"},
+			{MasterName: "b", RedisReplicationName: "b", Quorum: 3},
+		},
+	}}
+
+	if CanRestartSentinel(instance, 3) {
+		t.Error("expected the restart to be refused, master b would lose its quorum")
+	}
+	if !CanRestartSentinel(instance, 4) {
+		t.Error("expected the restart to be allowed")
+	}
+}
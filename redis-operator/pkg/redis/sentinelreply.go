@@ -0,0 +1,171 @@
+package k8sredis
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SentinelMaster is a master reported by SENTINEL MASTER or SENTINEL MASTERS
+type SentinelMaster struct {
+	Name string
+	// hostname when the sentinels announce hostnames
+	IP                string
+	Port              int
+	Flags             []string
+	Quorum            int
+	NumReplicas       int
+	NumOtherSentinels int
+	// time the master has been subjectively down, 0 while it is up
+	DownTime time.Duration
+	Fields   map[string]string
+
+	// sentinel reporting the master, set by GetSentinelMasters
+	SentinelDNS      string
+	SentinelPodIndex int
+}
+
+// SentinelPeer is a replica reported by SENTINEL REPLICAS or a sentinel reported by SENTINEL SENTINELS
+type SentinelPeer struct {
+	// ip:port for replicas, the run id for sentinels
+	Name  string
+	IP    string
+	Port  int
+	Flags []string
+	// time the peer has been subjectively down, 0 while it is up
+	DownTime time.Duration
+	Fields   map[string]string
+}
+
+// getSentinelInt parses the optional integer field of a sentinel reply, 0 when missing
+func getSentinelInt(fields map[string]string, field string) (int, error) {
+	value, ok := fields[field]
+	if !ok {
+		return 0, nil
+	}
+	parsed, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s %q: %v", field, value, err)
+	}
+	return parsed, nil
+}
+
+// NewSentinelMaster parses the fields of a master reported by a sentinel
+func NewSentinelMaster(fields map[string]string) (*SentinelMaster, error) {
+	master := &SentinelMaster{
+		Name:   fields["name"],
+		IP:     fields["ip"],
+		Flags:  strings.Split(fields["flags"], ","),
+		Fields: fields,
+	}
+
+	var err error
+	if master.Port, err = getSentinelInt(fields, "port"); err != nil {
+		return nil, err
+	}
+	if master.Quorum, err = getSentinelInt(fields, "quorum"); err != nil {
+		return nil, err
+	}
+	if master.NumReplicas, err = getSentinelInt(fields, "num-slaves"); err != nil {
+		return nil, err
+	}
+	if master.NumOtherSentinels, err = getSentinelInt(fields, "num-other-sentinels"); err != nil {
+		return nil, err
+	}
+	downTime, err := getSentinelInt(fields, "s-down-time")
+	if err != nil {
+		return nil, err
+	}
+	master.DownTime = time.Duration(downTime) * time.Millisecond
+	return master, nil
+}
+
+// NewSentinelPeer parses the fields of a replica or sentinel reported by a sentinel
+func NewSentinelPeer(fields map[string]string) (*SentinelPeer, error) {
+	peer := &SentinelPeer{
+		Name:   fields["name"],
+		IP:     fields["ip"],
+		Flags:  strings.Split(fields["flags"], ","),
+		Fields: fields,
+	}
+
+	var err error
+	if peer.Port, err = getSentinelInt(fields, "port"); err != nil {
+		return nil, err
+	}
+	downTime, err := getSentinelInt(fields, "s-down-time")
+	if err != nil {
+		return nil, err
+	}
+	peer.DownTime = time.Duration(downTime) * time.Millisecond
+	return peer, nil
+}
+
+func (m *SentinelMaster) HasFlag(flag string) bool {
+	for _, f := range m.Flags {
+		if f == flag {
+			return true
+		}
+	}
+	return false
+}
+
+// IsDown returns whether the reporting sentinel considers the master subjectively down
+func (m *SentinelMaster) IsDown() bool {
+	return m.DownTime > 0 || m.HasFlag("s_down")
+}
+
+func (p *SentinelPeer) HasFlag(flag string) bool {
+	for _, f := range p.Flags {
+		if f == flag {
+			return true
+		}
+	}
+	return false
+}
+
+// ParseSentinelMasters converts the reply of SENTINEL MASTERS, a list of field and value lists with RESP2 and a
+// list of maps with RESP3
+func ParseSentinelMasters(reply []interface{}) ([]SentinelMaster, error) {
+	masters := []SentinelMaster{}
+	for _, entry := range reply {
+		fields := map[string]string{}
+		switch entry := entry.(type) {
+		case []interface{}:
+			if len(entry)%2 != 0 {
+				return nil, fmt.Errorf("odd number of fields in sentinel master %v", entry)
+			}
+			for i := 0; i < len(entry); i += 2 {
+				fields[fmt.Sprint(entry[i])] = fmt.Sprint(entry[i+1])
+			}
+		case map[interface{}]interface{}:
+			for key, value := range entry {
+				fields[fmt.Sprint(key)] = fmt.Sprint(value)
+			}
+		default:
+			return nil, fmt.Errorf("unexpected sentinel master %T", entry)
+		}
+
+		master, err := NewSentinelMaster(fields)
+		if err != nil {
+			return nil, fmt.Errorf("invalid sentinel master %s: %v", fields["name"], err)
+		}
+		masters = append(masters, *master)
+	}
+	return masters, nil
+}
+
+// ParseSentinelPeers converts the reply of SENTINEL REPLICAS or SENTINEL SENTINELS, which go-redis already returns
+// as field maps for RESP2 and RESP3
+func ParseSentinelPeers(reply []map[string]string) ([]SentinelPeer, error) {
+	peers := []SentinelPeer{}
+	for _, fields := range reply {
+		peer, err := NewSentinelPeer(fields)
+		if err != nil {
+			return nil, fmt.Errorf("invalid sentinel peer %s: %v", fields["name"], err)
+		}
+		peers = append(peers, *peer)
+	}
+	return peers, nil
+}
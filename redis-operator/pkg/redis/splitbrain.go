@@ -0,0 +1,201 @@
+package k8sredis
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/redis/go-redis/v9"
+	"k8s.io/client-go/kubernetes"
+	v1 "redis.operator/api/v1"
+)
+
+// how long fenced masters refuse writes if the operator fails to demote them
+const fenceTimeout = 30 * time.Second
+
+// StaleMaster is a master losing the split-brain. Its writes outside of the history of the surviving master are
+// discarded once it resyncs as a replica
+type StaleMaster struct {
+	Candidate *ElectionCandidate
+	// first offset which is not part of the surviving history, 0 when the histories are unrelated
+	DivergedAt     int64
+	DiscardedBytes int64
+}
+
+func (s StaleMaster) String() string {
+	if s.DiscardedBytes == 0 {
+		return fmt.Sprintf("%s had no diverging writes", s.Candidate.Info.DNS)
+	}
+	if s.DivergedAt == 0 {
+		return fmt.Sprintf("%s diverged completely, its %d bytes of writes are discarded", s.Candidate.Info.DNS, s.DiscardedBytes)
+	}
+	return fmt.Sprintf("%s discards %d bytes of writes after offset %d", s.Candidate.Info.DNS, s.DiscardedBytes, s.DivergedAt)
+}
+
+// SplitBrain describes several masters which accepted writes, usually after a healed network partition
+type SplitBrain struct {
+	Master *ElectionCandidate
+	Stale  []StaleMaster
+}
+
+func (s *SplitBrain) String() string {
+	stale := []string{}
+	for _, master := range s.Stale {
+		stale = append(stale, master.String())
+	}
+	return fmt.Sprintf("kept %s as master. %s", s.Master.Info.DNS, strings.Join(stale, ", "))
+}
+
+// DetectSplitBrain compares the replication histories of the masters holding writes. Returns nil unless at least
+// two of them accepted writes. preferredMaster, usually agreed by the sentinels, wins when it is one of the masters.
+// Otherwise a master promoted from another one wins over its predecessor, then the master with the most replicas
+func DetectSplitBrain(replicationInfo []RedisCommandInfo, preferredMaster string, preferredZone string) (*SplitBrain, error) {
+	masters := []*ElectionCandidate{}
+	for _, info := range replicationInfo {
+		candidate, err := NewElectionCandidate(info)
+		if err != nil {
+			return nil, err
+		}
+		if candidate.Role == "master" && !candidate.IsEmpty() {
+			masters = append(masters, candidate)
+		}
+	}
+
+	if len(masters) < 2 {
+		return nil, nil
+	}
+
+	isSuccessor := func(candidate *ElectionCandidate) bool {
+		for _, other := range masters {
+			if candidate.ReplID2 != "" && candidate.ReplID2 == other.ReplID {
+				return true
+			}
+		}
+		return false
+	}
+
+	sort.SliceStable(masters, func(i, j int) bool {
+		if preferredMaster != "" && (masters[i].Info.DNS == preferredMaster) != (masters[j].Info.DNS == preferredMaster) {
+			return masters[i].Info.DNS == preferredMaster
+		}
+		if isSuccessor(masters[i]) != isSuccessor(masters[j]) {
+			return isSuccessor(masters[i])
+		}
+		if masters[i].ConnectedSlaves != masters[j].ConnectedSlaves {
+			return masters[i].ConnectedSlaves > masters[j].ConnectedSlaves
+		}
+		return masters[i].rank(masters[j], preferredZone)
+	})
+
+	splitBrain := &SplitBrain{Master: masters[0]}
+	for _, stale := range masters[1:] {
+		splitBrain.Stale = append(splitBrain.Stale, getStaleMaster(splitBrain.Master, stale))
+	}
+	return splitBrain, nil
+}
+
+// getStaleMaster computes the writes of stale which are not part of the history of master
+func getStaleMaster(master *ElectionCandidate, stale *ElectionCandidate) StaleMaster {
+	staleMaster := StaleMaster{Candidate: stale}
+
+	switch {
+	case master.ReplID2 == stale.ReplID && master.SecondOffset > 0:
+		// master was promoted from stale, both share the writes before the promotion
+		staleMaster.DivergedAt = master.SecondOffset
+		staleMaster.DiscardedBytes = stale.Offset - master.SecondOffset + 1
+	case stale.ReplID2 == master.ReplID && stale.SecondOffset > 0:
+		// stale was promoted from master, every write since the promotion is discarded
+		staleMaster.DivergedAt = stale.SecondOffset
+		staleMaster.DiscardedBytes = stale.Offset - stale.SecondOffset + 1
+	case master.ReplID == stale.ReplID:
+		staleMaster.DivergedAt = master.Offset + 1
+		staleMaster.DiscardedBytes = stale.Offset - master.Offset
+	default:
+		staleMaster.DiscardedBytes = stale.Offset
+	}
+
+	if staleMaster.DiscardedBytes < 0 {
+		staleMaster.DiscardedBytes = 0
+	}
+	return staleMaster
+}
+
+// Fence holds what FenceMaster changed on a master so UnfenceMaster can revert it
+type Fence struct {
+	Paused             bool
+	MinReplicasToWrite string
+}
+
+// FenceMaster stops a stale master from accepting writes before it is demoted. CLIENT PAUSE WRITE keeps reads
+// served and expires on its own. Servers older than 6.2 get min-replicas-to-write raised above the number of replicas
+func FenceMaster(ctx context.Context, client *redis.Client, replicas int) (*Fence, error) {
+	if err := client.Do(ctx, "CLIENT", "PAUSE", strconv.FormatInt(fenceTimeout.Milliseconds(), 10), "WRITE").Err(); err == nil {
+		return &Fence{Paused: true}, nil
+	}
+
+	current, err := client.ConfigGet(ctx, "min-replicas-to-write").Result()
+	if err != nil {
+		return nil, err
+	}
+	if err := client.ConfigSet(ctx, "min-replicas-to-write", strconv.Itoa(replicas)).Err(); err != nil {
+		return nil, err
+	}
+	return &Fence{MinReplicasToWrite: current["min-replicas-to-write"]}, nil
+}
+
+// UnfenceMaster reverts FenceMaster once the master is demoted
+func UnfenceMaster(ctx context.Context, client *redis.Client, fence *Fence) error {
+	if fence.Paused {
+		return client.ClientUnpause(ctx).Err()
+	}
+	return client.ConfigSet(ctx, "min-replicas-to-write", fence.MinReplicasToWrite).Err()
+}
+
+// FenceStaleMasters fences every stale master, keyed by the dns of the pod. Fails if any of them can't be fenced
+func FenceStaleMasters(ctx context.Context, k8Client kubernetes.Interface, instance *v1.RedisReplication, splitBrain *SplitBrain, reqLogger logr.Logger) (map[string]*Fence, error) {
+	tlsConfig, password, err := getReplicationCredentials(ctx, k8Client, instance)
+	if err != nil {
+		return nil, err
+	}
+
+	fences := map[string]*Fence{}
+	for _, stale := range splitBrain.Stale {
+		podDNS := stale.Candidate.Info.DNS
+
+		var fence *Fence
+		err := clients.call(ctx, podDNS, instance.GetRedisPort(), tlsConfig, password, func(ctx context.Context, client *redis.Client) error {
+			var err error
+			fence, err = FenceMaster(ctx, client, instance.GetReplicas())
+			return err
+		})
+		if err != nil {
+			return fences, fmt.Errorf("error fencing %s: %v", podDNS, err)
+		}
+		reqLogger.Info("fenced stale master", "pod", podDNS, "paused", fence.Paused)
+		fences[podDNS] = fence
+	}
+	return fences, nil
+}
+
+// UnfenceMasters reverts the fences of FenceStaleMasters
+func UnfenceMasters(ctx context.Context, k8Client kubernetes.Interface, instance *v1.RedisReplication, fences map[string]*Fence, reqLogger logr.Logger) error {
+	tlsConfig, password, err := getReplicationCredentials(ctx, k8Client, instance)
+	if err != nil {
+		return err
+	}
+
+	for podDNS, fence := range fences {
+		err := clients.call(ctx, podDNS, instance.GetRedisPort(), tlsConfig, password, func(ctx context.Context, client *redis.Client) error {
+			return UnfenceMaster(ctx, client, fence)
+		})
+		if err != nil {
+			return fmt.Errorf("error unfencing %s: %v", podDNS, err)
+		}
+		reqLogger.Info("unfenced demoted master", "pod", podDNS)
+	}
+	return nil
+}
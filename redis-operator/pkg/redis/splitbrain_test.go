@@ -0,0 +1,82 @@
+package k8sredis
+
+import (
+	"testing"
+)
+
+func TestDetectSplitBrain(t *testing.T) {
+	tests := []struct {
+		name            string
+		snapshots       []string
+		preferredMaster int // pod index, -1 without sentinels
+		master          int
+		divergedAt      int64
+		discardedBytes  int64
+	}{
+		{
+			name:            "promoted replica wins over its old master",
+			snapshots:       []string{"master-stale", "master-promoted"},
+			preferredMaster: -1,
+			master:          1,
+			divergedAt:      1401,
+			discardedBytes:  180,
+		},
+		{
+			name:            "sentinels keep the old master",
+			snapshots:       []string{"master-stale", "master-promoted"},
+			preferredMaster: 0,
+			master:          0,
+			divergedAt:      1401,
+			discardedBytes:  220,
+		},
+		{
+			name:            "unrelated histories discard every write",
+			snapshots:       []string{"master-stale", "master-diverged"},
+			preferredMaster: -1,
+			master:          1,
+			divergedAt:      0,
+			discardedBytes:  1580,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			replicationInfo := loadSnapshots(t, test.snapshots...)
+
+			preferredMaster := ""
+			if test.preferredMaster >= 0 {
+				preferredMaster = replicationInfo[test.preferredMaster].DNS
+			}
+
+			splitBrain, err := DetectSplitBrain(replicationInfo, preferredMaster, "")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if splitBrain == nil {
+				t.Fatal("expected a split-brain")
+			}
+
+			if splitBrain.Master.Info.DNS != replicationInfo[test.master].DNS {
+				t.Fatalf("expected %s to be kept, got %s", replicationInfo[test.master].DNS, splitBrain.Master.Info.DNS)
+			}
+			if len(splitBrain.Stale) != 1 {
+				t.Fatalf("expected one stale master, got %d", len(splitBrain.Stale))
+			}
+			if stale := splitBrain.Stale[0]; stale.DivergedAt != test.divergedAt || stale.DiscardedBytes != test.discardedBytes {
+				t.Fatalf("expected %d bytes discarded after offset %d, got %s", test.discardedBytes, test.divergedAt, stale)
+			}
+		})
+	}
+}
+
+func TestDetectSplitBrainIgnoresEmptyMasters(t *testing.T) {
+	replicationInfo := loadSnapshots(t, "master-promoted", "master-empty", "replica-behind")
+
+	splitBrain, err := DetectSplitBrain(replicationInfo, "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if splitBrain != nil {
+		t.Fatalf("expected no split-brain, got %s", splitBrain)
+	}
+}
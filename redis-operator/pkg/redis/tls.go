@@ -0,0 +1,491 @@
+package k8sredis
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/go-logr/logr"
+	"github.com/redis/go-redis/v9"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	v1 "redis.operator/api/v1"
+	"redis.operator/pkg/kube/configmap"
+)
+
+type tlsCacheEntry struct {
+	resourceVersion string
+	config          *tls.Config
+}
+
+// parsed tls configs keyed by namespace/secret, reused until the secret changes
+var tlsCache sync.Map
+
+// openssl names of the TLS 1.2 cipher suites, used for tls-ciphers
+var tls12CipherNames = map[string]string{
+	"TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256":       "ECDHE-ECDSA-AES128-GCM-SHA256",
+	"TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256":         "ECDHE-RSA-AES128-GCM-SHA256",
+	"TLS_ECDHE_ECDSA_WITH_AES_256_GCM_SHA384":       "ECDHE-ECDSA-AES256-GCM-SHA384",
+	"TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384":         "ECDHE-RSA-AES256-GCM-SHA384",
+	"TLS_ECDHE_ECDSA_WITH_CHACHA20_POLY1305_SHA256": "ECDHE-ECDSA-CHACHA20-POLY1305",
+	"TLS_ECDHE_RSA_WITH_CHACHA20_POLY1305_SHA256":   "ECDHE-RSA-CHACHA20-POLY1305",
+}
+
+// TLS 1.3 suites share their name with openssl and can't be restricted on the client side
+var tls13CipherSuites = map[string]bool{
+	"TLS_AES_128_GCM_SHA256":       true,
+	"TLS_AES_256_GCM_SHA384":       true,
+	"TLS_CHACHA20_POLY1305_SHA256": true,
+}
+
+// loadTLSConfig returns the parsed certificates of the secret
+func loadTLSConfig(ctx context.Context, k8Client kubernetes.Interface, namespace string, secretName string) (*tls.Config, error) {
+	secret, err := k8Client.CoreV1().Secrets(namespace).Get(ctx, secretName, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	key := namespace + "/" + secretName
+	if entry, ok := tlsCache.Load(key); ok && entry.(tlsCacheEntry).resourceVersion == secret.ResourceVersion {
+		return entry.(tlsCacheEntry).config.Clone(), nil
+	}
+
+	tlsCert, ok := secret.Data["tls.crt"]
+	if !ok {
+		return nil, fmt.Errorf("tls.crt not found in secret")
+	}
+
+	tlsKey, ok := secret.Data["tls.key"]
+	if !ok {
+		return nil, fmt.Errorf("tls.key not found in secret")
+	}
+
+	caCert, ok := secret.Data["ca.crt"]
+	if !ok {
+		return nil, fmt.Errorf("ca.crt not found in secret")
+	}
+	caCertPool := x509.NewCertPool()
+	if !caCertPool.AppendCertsFromPEM(caCert) {
+		return nil, fmt.Errorf("failed to append CA cert")
+	}
+
+	cert, err := tls.X509KeyPair(tlsCert, tlsKey)
+	if err != nil {
+		return nil, err
+	}
+
+	config := &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		RootCAs:      caCertPool,
+	}
+	tlsCache.Store(key, tlsCacheEntry{resourceVersion: secret.ResourceVersion, config: config})
+
+	return config.Clone(), nil
+}
+
+// GetSecretCertificate returns the DER encoded leaf certificate stored in the secret
+func GetSecretCertificate(ctx context.Context, k8Client kubernetes.Interface, namespace string, secretName string) ([]byte, error) {
+	config, err := loadTLSConfig(ctx, k8Client, namespace, secretName)
+	if err != nil {
+		return nil, err
+	}
+	return config.Certificates[0].Certificate[0], nil
+}
+
+// GetTLSConfig returns the client config used to connect to a server configured with settings
+func GetTLSConfig(ctx context.Context, k8Client kubernetes.Interface, namespace string, secretName string, settings *v1.RedisTLSConfiguration) (*tls.Config, error) {
+	config, err := loadTLSConfig(ctx, k8Client, namespace, secretName)
+	if err != nil {
+		return nil, err
+	}
+
+	config.MinVersion = tls.VersionTLS12
+	if settings.GetMinVersion() == "TLSv1.3" {
+		config.MinVersion = tls.VersionTLS13
+	}
+
+	if settings.GetAuthClients() == "no" {
+		config.Certificates = nil
+	}
+
+	if settings != nil && len(settings.CipherSuites) > 0 {
+		cipherSuites, err := getCipherSuiteIDs(settings.CipherSuites)
+		if err != nil {
+			return nil, err
+		}
+		config.CipherSuites = cipherSuites
+	}
+
+	return config, nil
+}
+
+func getCipherSuiteIDs(names []string) ([]uint16, error) {
+	ids := map[string]uint16{}
+	for _, suite := range tls.CipherSuites() {
+		ids[suite.Name] = suite.ID
+	}
+
+	cipherSuites := []uint16{}
+	for _, name := range names {
+		if tls13CipherSuites[name] {
+			continue
+		}
+		id, ok := ids[name]
+		if _, supported := tls12CipherNames[name]; !ok || !supported {
+			return nil, fmt.Errorf("unsupported cipher suite %s", name)
+		}
+		cipherSuites = append(cipherSuites, id)
+	}
+	return cipherSuites, nil
+}
+
+// GetServerTLSSettings returns the redis.conf or sentinel.conf directives for the tls settings which are set
+func GetServerTLSSettings(settings *v1.RedisTLSConfiguration) (map[string]string, error) {
+	directives := map[string]string{}
+	if settings == nil {
+		return directives, nil
+	}
+
+	if settings.AuthClients != "" {
+		directives["tls-auth-clients"] = settings.AuthClients
+	}
+	if settings.MinVersion != "" {
+		directives["tls-protocols"] = `"TLSv1.2 TLSv1.3"`
+		if settings.MinVersion == "TLSv1.3" {
+			directives["tls-protocols"] = "TLSv1.3"
+		}
+	}
+
+	ciphers := []string{}
+	cipherSuites := []string{}
+	for _, name := range settings.CipherSuites {
+		if tls13CipherSuites[name] {
+			cipherSuites = append(cipherSuites, name)
+		} else if opensslName, ok := tls12CipherNames[name]; ok {
+			ciphers = append(ciphers, opensslName)
+		} else {
+			return nil, fmt.Errorf("unsupported cipher suite %s", name)
+		}
+	}
+	if len(ciphers) > 0 {
+		directives["tls-ciphers"] = strings.Join(ciphers, ":")
+	}
+	if len(cipherSuites) > 0 {
+		directives["tls-ciphersuites"] = strings.Join(cipherSuites, ":")
+	}
+
+	return directives, nil
+}
+
+// ServerTLSOptions describes the tls directives written to a redis.conf or sentinel.conf
+type ServerTLSOptions struct {
+	// auth clients, protocols and ciphers, nil leaves them untouched
+	Settings *v1.RedisTLSConfiguration
+	// migration mode of the server ports
+	Mode string
+	// migration mode of the servers replicated from or monitored
+	ReplicationMode string
+	PlaintextPort   string
+	TLSPort         string
+}
+
+// GetServerPortSettings returns the port directive of the migration mode. Dual keeps the plaintext port open
+// next to tls-port, TLS closes it. Nothing is returned when no mode is set
+func GetServerPortSettings(mode string, plaintextPort string, tlsPort string) (map[string]string, error) {
+	directives := map[string]string{}
+
+	switch mode {
+	case v1.TLSModeDual:
+		if plaintextPort == tlsPort {
+			return nil, fmt.Errorf("plaintext port %s is already used by tls-port", plaintextPort)
+		}
+		directives["port"] = plaintextPort
+	case v1.TLSModeTLS:
+		directives["port"] = "0"
+	}
+	return directives, nil
+}
+
+// GetReplicationTLSSettings returns the tls-replication directive of the migration mode. Replicas and sentinels
+// use the plaintext port in Dual mode and tls-port in TLS mode
+func GetReplicationTLSSettings(mode string) map[string]string {
+	directives := map[string]string{}
+
+	switch mode {
+	case v1.TLSModeDual:
+		directives["tls-replication"] = "no"
+	case v1.TLSModeTLS:
+		directives["tls-replication"] = "yes"
+	}
+	return directives
+}
+
+// setReplicationTLS applies the tls-replication directive of mode with CONFIG SET. Pods and sentinels started before
+// the mode changed run the directive of the previous mode until they roll, they are switched before being pointed
+// at the port of the new mode
+func setReplicationTLS(ctx context.Context, host string, port string, tlsConfig *tls.Config, password string, mode string) error {
+	for directive, value := range GetReplicationTLSSettings(mode) {
+		err := clients.call(ctx, host, port, tlsConfig, password, func(ctx context.Context, client *redis.Client) error {
+			current, err := client.ConfigGet(ctx, directive).Result()
+			if err != nil {
+				return err
+			}
+			if current[directive] == value {
+				return nil
+			}
+			return client.ConfigSet(ctx, directive, value).Err()
+		})
+		if err != nil {
+			return fmt.Errorf("error setting %s of %s: %v", directive, host, err)
+		}
+	}
+	return nil
+}
+
+// UpdateConfigMapTLSSettings writes the tls directives which are set to the config file stored under key
+func UpdateConfigMapTLSSettings(configMap *corev1.ConfigMap, key string, options ServerTLSOptions) error {
+	directives, err := GetServerTLSSettings(options.Settings)
+	if err != nil {
+		return err
+	}
+
+	portDirectives, err := GetServerPortSettings(options.Mode, options.PlaintextPort, options.TLSPort)
+	if err != nil {
+		return err
+	}
+	for name, value := range portDirectives {
+		directives[name] = value
+	}
+	for name, value := range GetReplicationTLSSettings(options.ReplicationMode) {
+		directives[name] = value
+	}
+
+	names := []string{}
+	for name := range directives {
+		names = append(names, name)
+	}
+	sort.Strings(names) // lines missing from the config are appended, keep their order stable
+
+	for _, name := range names {
+		if !configmap.SetConfigMapDirective(configMap, key, name, directives[name]) {
+			return fmt.Errorf("%s not found in configmap", key)
+		}
+	}
+	return nil
+}
+
+// ReloadCertificate makes the server re-read its tls files by setting tls-cert-file to its current value.
+// Returns true when the server serves certificate afterwards. The files are updated by the
+// kubelet some time after the secret changes, so false means the reload has to be retried later
+func ReloadCertificate(ctx context.Context, client *redis.Client, host string, port string, tlsConfig *tls.Config, certificate []byte) (bool, error) {
+	current, err := client.ConfigGet(ctx, "tls-cert-file").Result()
+	if err != nil {
+		return false, err
+	}
+	certFile, ok := current["tls-cert-file"]
+	if !ok || certFile == "" {
+		return false, fmt.Errorf("tls-cert-file is not configured")
+	}
+
+	if err := client.ConfigSet(ctx, "tls-cert-file", certFile).Err(); err != nil {
+		return false, err
+	}
+
+	return IsServingCertificate(host, port, tlsConfig, certificate)
+}
+
+// IsServingCertificate compares the DER encoded certificate with the one presented by the server
+func IsServingCertificate(host string, port string, tlsConfig *tls.Config, certificate []byte) (bool, error) {
+	config := tlsConfig.Clone()
+	config.ServerName = host
+
+	conn, err := tls.DialWithDialer(&net.Dialer{Timeout: getProbeTimeout()}, "tcp", net.JoinHostPort(host, port), config)
+	if err != nil {
+		return false, err
+	}
+	defer conn.Close()
+
+	peerCertificates := conn.ConnectionState().PeerCertificates
+	if len(peerCertificates) == 0 {
+		return false, nil
+	}
+	return bytes.Equal(peerCertificates[0].Raw, certificate), nil
+}
+
+// ReloadReplicationCertificates reloads the certificate on every reachable pod. Returns true when all of them
+// serve the certificate currently stored in the secret
+func ReloadReplicationCertificates(ctx context.Context, k8Client kubernetes.Interface, instance *v1.RedisReplication, reqLogger logr.Logger) (bool, error) {
+	certificate, err := GetSecretCertificate(ctx, k8Client, instance.Namespace, instance.GetTLSSecretName())
+	if err != nil {
+		return false, err
+	}
+
+	tlsConfig, err := GetTLSConfig(ctx, k8Client, instance.Namespace, instance.GetTLSSecretName(), instance.Spec.TLSConfig)
+	if err != nil {
+		return false, err
+	}
+
+	password, err := instance.Spec.RedisConfig.GetValue("redis.conf", "requirepass")
+	if err != nil {
+		return false, err
+	}
+
+	pending, err := probePods(ctx, instance.GetReplicas(), getProbeTimeout(), func(ctx context.Context, i int) (string, bool, error) {
+		podDNS := instance.GetPodDNS(i)
+		serving, _, err := reloadPodCertificate(ctx, podDNS, instance.GetRedisPort(), tlsConfig, password, certificate)
+		if err != nil {
+			return "", false, err
+		}
+		return podDNS, !serving, nil
+	})
+	if err != nil {
+		return false, err
+	}
+	for _, podDNS := range pending {
+		reqLogger.Info("pod is not serving the new certificate yet", "pod", podDNS)
+	}
+	return len(pending) == 0, nil
+}
+
+// ReloadClusterCertificates reloads the certificate on the reachable nodes of one shard per call, the shards are
+// reloaded one after the other. Returns true once every reachable node serves the certificate currently stored in
+// the secret
+func ReloadClusterCertificates(ctx context.Context, k8Client kubernetes.Interface, instance *v1.RedisCluster, reqLogger logr.Logger) (bool, error) {
+	certificate, err := GetSecretCertificate(ctx, k8Client, instance.Namespace, instance.GetTLSSecretName())
+	if err != nil {
+		return false, err
+	}
+
+	tlsConfig, password, err := getClusterCredentials(ctx, k8Client, instance)
+	if err != nil {
+		return false, err
+	}
+
+	for shard := 0; shard < instance.GetDeployedShards(); shard++ {
+		pending, err := probePods(ctx, instance.GetShardSize(), getProbeTimeout(), func(ctx context.Context, i int) (string, bool, error) {
+			podDNS := instance.GetPodDNS(shard, i)
+			serving, reloaded, err := reloadPodCertificate(ctx, podDNS, instance.GetRedisPort(), tlsConfig, password, certificate)
+			if err != nil {
+				return "", false, err
+			}
+			if !serving {
+				reqLogger.Info("pod is not serving the new certificate yet", "pod", podDNS)
+			}
+			return podDNS, !serving || reloaded, nil
+		})
+		if err != nil {
+			return false, err
+		}
+		if len(pending) > 0 {
+			return false, nil // the next shard is reloaded by the next call
+		}
+	}
+	return true, nil
+}
+
+// reloadPodCertificate reloads the certificate of a redis pod not serving certificate yet. Returns whether the pod
+// serves it and whether it was reloaded. Unreachable pods count as serving, they load the new certificate on start
+func reloadPodCertificate(ctx context.Context, podDNS string, port string, tlsConfig *tls.Config, password string, certificate []byte) (bool, bool, error) {
+	err := clients.call(ctx, podDNS, port, tlsConfig, password, func(ctx context.Context, client *redis.Client) error {
+		return client.Ping(ctx).Err()
+	})
+	if err != nil {
+		return true, false, nil
+	}
+
+	if serving, err := IsServingCertificate(podDNS, port, tlsConfig, certificate); err == nil && serving {
+		return true, false, nil
+	}
+
+	var serving bool
+	err = clients.call(ctx, podDNS, port, tlsConfig, password, func(ctx context.Context, client *redis.Client) error {
+		var err error
+		serving, err = ReloadCertificate(ctx, client, podDNS, port, tlsConfig, certificate)
+		return err
+	})
+	if err != nil {
+		return false, true, fmt.Errorf("error reloading certificate of %s: %v", podDNS, err)
+	}
+	return serving, true, nil
+}
+
+// SentinelCertificateReload is the result of ReloadSentinelCertificates
+type SentinelCertificateReload struct {
+	// every reachable sentinel serves the certificate currently stored in the secret
+	Reloaded bool
+	// indexes of the sentinels refusing CONFIG SET for the tls settings, they load the certificate when restarted
+	Refused   []int
+	Reachable int
+}
+
+// ReloadSentinelCertificates reloads the certificate on every reachable sentinel in parallel. Sentinels refusing
+// the reload are returned rather than restarted
+func ReloadSentinelCertificates(ctx context.Context, k8Client kubernetes.Interface, instance *v1.RedisSentinel, tlsReplication *v1.RedisReplication, reqLogger logr.Logger) (*SentinelCertificateReload, error) {
+	certificate, err := GetSecretCertificate(ctx, k8Client, instance.Namespace, instance.GetTLSSecretName(tlsReplication))
+	if err != nil {
+		return nil, err
+	}
+
+	tlsConfig, password, err := getSentinelCredentials(ctx, k8Client, instance, tlsReplication)
+	if err != nil {
+		return nil, err
+	}
+
+	type sentinelReload struct {
+		index   int
+		serving bool
+		refused bool
+	}
+	port := instance.GetRedisPort()
+	results, err := probePods(ctx, instance.Spec.StatefulsetConfig.GetReplicas(), getProbeTimeout(), func(ctx context.Context, i int) (sentinelReload, bool, error) {
+		podDNS := instance.GetPodDNS(i)
+
+		err := clients.call(ctx, podDNS, port, tlsConfig, password, func(ctx context.Context, client *redis.Client) error {
+			return client.Ping(ctx).Err()
+		})
+		if err != nil {
+			return sentinelReload{}, false, nil // down, loads the certificate on start
+		}
+
+		if serving, err := IsServingCertificate(podDNS, port, tlsConfig, certificate); err == nil && serving {
+			return sentinelReload{index: i, serving: true}, true, nil
+		}
+
+		var serving bool
+		err = clients.call(ctx, podDNS, port, tlsConfig, password, func(ctx context.Context, client *redis.Client) error {
+			var err error
+			serving, err = ReloadCertificate(ctx, client, podDNS, port, tlsConfig, certificate)
+			return err
+		})
+		if isErrorReply(err) {
+			// older sentinels don't accept CONFIG SET for the tls settings
+			reqLogger.Info("sentinel refused to reload its certificate", "pod", podDNS, "error", err)
+			return sentinelReload{index: i, refused: true}, true, nil
+		}
+		if err != nil {
+			reqLogger.Info("failed to reload the certificate of the sentinel", "pod", podDNS, "error", err)
+		}
+		return sentinelReload{index: i, serving: serving}, true, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	reload := &SentinelCertificateReload{Reloaded: true, Refused: []int{}, Reachable: len(results)}
+	for _, result := range results {
+		if result.refused {
+			reload.Refused = append(reload.Refused, result.index)
+		}
+		if !result.serving {
+			reload.Reloaded = false
+		}
+	}
+	return reload, nil
+}
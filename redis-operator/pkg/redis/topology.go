@@ -0,0 +1,91 @@
+package k8sredis
+
+import (
+	"context"
+
+	"github.com/go-logr/logr"
+	"k8s.io/client-go/kubernetes"
+	v1 "redis.operator/api/v1"
+)
+
+// RedisTopologyClient reads and changes the replication and the sentinels of the redis pods of an instance. The
+// reconcilers go through it, so tests can replace the pods with the fake of pkg/redis/fake
+type RedisTopologyClient interface {
+	// GetReplicaInfo returns INFO replication of every reachable pod, ordered by pod index
+	GetReplicaInfo(ctx context.Context, instance *v1.RedisReplication, reqLogger logr.Logger) ([]RedisCommandInfo, error)
+	// SetReplicationMaster promotes masterDNS and makes every other pod replicate it
+	SetReplicationMaster(ctx context.Context, instance *v1.RedisReplication, masterDNS string, reqLogger logr.Logger) error
+	// SetReplicaPriority updates the replica-priority of every reachable pod, keyed by the pod index
+	SetReplicaPriority(ctx context.Context, instance *v1.RedisReplication, priorities map[int]int, reqLogger logr.Logger) error
+	// FenceStaleMasters fences every stale master of splitBrain, keyed by the dns of the pod
+	FenceStaleMasters(ctx context.Context, instance *v1.RedisReplication, splitBrain *SplitBrain, reqLogger logr.Logger) (map[string]*Fence, error)
+	// UnfenceMasters reverts the fences of FenceStaleMasters
+	UnfenceMasters(ctx context.Context, instance *v1.RedisReplication, fences map[string]*Fence, reqLogger logr.Logger) error
+	// ReloadReplicationCertificates reloads the certificate on every reachable pod, true once all of them serve the
+	// certificate of the secret
+	ReloadReplicationCertificates(ctx context.Context, instance *v1.RedisReplication, reqLogger logr.Logger) (bool, error)
+
+	// GetSentinelMasters returns the view of masterName of every reachable sentinel monitoring it
+	GetSentinelMasters(ctx context.Context, instance *v1.RedisSentinel, tlsReplication *v1.RedisReplication, masterName string) ([]SentinelMaster, error)
+	// UpdateSentinelMonitors converges the masters monitored by every reachable sentinel
+	UpdateSentinelMonitors(ctx context.Context, instance *v1.RedisSentinel, tlsReplication *v1.RedisReplication, monitors []SentinelMonitor, removed []string, reqLogger logr.Logger) (SentinelMonitorChanges, error)
+	// ResetSentinelMaster makes a sentinel forget the state of masterName
+	ResetSentinelMaster(ctx context.Context, instance *v1.RedisSentinel, tlsReplication *v1.RedisReplication, podIndex int, masterName string) error
+	// RemoveSentinelMaster makes a sentinel stop monitoring masterName
+	RemoveSentinelMaster(ctx context.Context, instance *v1.RedisSentinel, tlsReplication *v1.RedisReplication, podIndex int, masterName string) error
+	// ReloadSentinelCertificates reloads the certificate on every reachable sentinel and returns the ones refusing it
+	ReloadSentinelCertificates(ctx context.Context, instance *v1.RedisSentinel, tlsReplication *v1.RedisReplication, reqLogger logr.Logger) (*SentinelCertificateReload, error)
+}
+
+// redisTopologyClient reaches the pods through the cached go-redis clients, credentials are read with k8Client
+type redisTopologyClient struct {
+	k8Client kubernetes.Interface
+}
+
+func NewRedisTopologyClient(k8Client kubernetes.Interface) RedisTopologyClient {
+	return &redisTopologyClient{k8Client: k8Client}
+}
+
+func (c *redisTopologyClient) GetReplicaInfo(ctx context.Context, instance *v1.RedisReplication, reqLogger logr.Logger) ([]RedisCommandInfo, error) {
+	return GetReplicaInfo(ctx, c.k8Client, instance, reqLogger)
+}
+
+func (c *redisTopologyClient) SetReplicationMaster(ctx context.Context, instance *v1.RedisReplication, masterDNS string, reqLogger logr.Logger) error {
+	return SetReplicationMaster(ctx, c.k8Client, instance, masterDNS, reqLogger)
+}
+
+func (c *redisTopologyClient) SetReplicaPriority(ctx context.Context, instance *v1.RedisReplication, priorities map[int]int, reqLogger logr.Logger) error {
+	return SetReplicaPriority(ctx, c.k8Client, instance, priorities, reqLogger)
+}
+
+func (c *redisTopologyClient) FenceStaleMasters(ctx context.Context, instance *v1.RedisReplication, splitBrain *SplitBrain, reqLogger logr.Logger) (map[string]*Fence, error) {
+	return FenceStaleMasters(ctx, c.k8Client, instance, splitBrain, reqLogger)
+}
+
+func (c *redisTopologyClient) UnfenceMasters(ctx context.Context, instance *v1.RedisReplication, fences map[string]*Fence, reqLogger logr.Logger) error {
+	return UnfenceMasters(ctx, c.k8Client, instance, fences, reqLogger)
+}
+
+func (c *redisTopologyClient) ReloadReplicationCertificates(ctx context.Context, instance *v1.RedisReplication, reqLogger logr.Logger) (bool, error) {
+	return ReloadReplicationCertificates(ctx, c.k8Client, instance, reqLogger)
+}
+
+func (c *redisTopologyClient) GetSentinelMasters(ctx context.Context, instance *v1.RedisSentinel, tlsReplication *v1.RedisReplication, masterName string) ([]SentinelMaster, error) {
+	return GetSentinelMasters(ctx, c.k8Client, instance, tlsReplication, masterName)
+}
+
+func (c *redisTopologyClient) UpdateSentinelMonitors(ctx context.Context, instance *v1.RedisSentinel, tlsReplication *v1.RedisReplication, monitors []SentinelMonitor, removed []string, reqLogger logr.Logger) (SentinelMonitorChanges, error) {
+	return UpdateSentinelMonitors(ctx, c.k8Client, instance, tlsReplication, monitors, removed, reqLogger)
+}
+
+func (c *redisTopologyClient) ResetSentinelMaster(ctx context.Context, instance *v1.RedisSentinel, tlsReplication *v1.RedisReplication, podIndex int, masterName string) error {
+	return ResetSentinelMaster(ctx, c.k8Client, instance, tlsReplication, podIndex, masterName)
+}
+
+func (c *redisTopologyClient) RemoveSentinelMaster(ctx context.Context, instance *v1.RedisSentinel, tlsReplication *v1.RedisReplication, podIndex int, masterName string) error {
+	return RemoveSentinelMaster(ctx, c.k8Client, instance, tlsReplication, podIndex, masterName)
+}
+
+func (c *redisTopologyClient) ReloadSentinelCertificates(ctx context.Context, instance *v1.RedisSentinel, tlsReplication *v1.RedisReplication, reqLogger logr.Logger) (*SentinelCertificateReload, error) {
+	return ReloadSentinelCertificates(ctx, c.k8Client, instance, tlsReplication, reqLogger)
+}
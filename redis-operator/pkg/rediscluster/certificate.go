@@ -0,0 +1,28 @@
+package rediscluster
+
+import (
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	v1 "redis.operator/api/v1"
+	"redis.operator/pkg/kube/custom"
+	"redis.operator/pkg/kube/dns"
+)
+
+// GetCertificateDNSNames returns the SANs of the cluster. Nodes redirect clients to the hostnames they announce,
+// so the certificate covers the pods of the headless service as well
+func GetCertificateDNSNames(instance *v1.RedisCluster) []string {
+	dnsNames := custom.ServiceDNSNames(instance.Name, instance.Namespace)
+	return append(dnsNames, "*."+dns.GetServiceDNS(instance.GetHeadlessServiceName(), instance.Namespace))
+}
+
+// CreateCertificate returns the cert-manager Certificate for an instance with tls.issuerRef set
+func CreateCertificate(instance *v1.RedisCluster) *unstructured.Unstructured {
+	return custom.NewCertificate(
+		instance.GetCertificateName(),
+		instance.Namespace,
+		GetClusterServiceLabels(instance),
+		instance.GetOwnerReference(),
+		instance.GetTLSSecretName(),
+		GetCertificateDNSNames(instance),
+		instance.Spec.TLSConfig,
+	)
+}
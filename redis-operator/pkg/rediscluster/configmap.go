@@ -0,0 +1,48 @@
+package rediscluster
+
+import (
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	v1 "redis.operator/api/v1"
+	"redis.operator/pkg/kube/configmap"
+	k8sredis "redis.operator/pkg/redis"
+)
+
+// CreateConfigMap returns redis.conf of every node with cluster mode enabled. nodes.conf is kept on the data
+// volume so a restarted node rejoins with its node id
+func CreateConfigMap(instance *v1.RedisCluster) (*corev1.ConfigMap, error) {
+	configMap := configmap.NewBuilder().
+		SetName(instance.GetConfigName()).
+		SetNamespace(instance.Namespace).
+		SetLabels(GetClusterServiceLabels(instance)).
+		SetData(instance.Spec.RedisConfig.Data).
+		BuildWithOwner(instance.GetOwnerReference())
+
+	directives := [][2]string{
+		{"cluster-enabled", "yes"},
+		{"cluster-config-file", "/tmp/redis/nodes.conf"},
+	}
+	if instance.Spec.TLSConfig != nil {
+		directives = append(directives, [2]string{"tls-cluster", "yes"})
+	}
+	for _, directive := range directives {
+		if !configmap.SetConfigMapDirective(configMap, "redis.conf", directive[0], directive[1]) {
+			return nil, fmt.Errorf("redis.conf not found in configmap")
+		}
+	}
+
+	if instance.Spec.TLSConfig != nil {
+		// the cluster bus and the replication links always use tls, only clients may migrate
+		if err := k8sredis.UpdateConfigMapTLSSettings(configMap, "redis.conf", k8sredis.ServerTLSOptions{
+			Settings:        instance.Spec.TLSConfig,
+			Mode:            instance.Spec.TLSConfig.GetMode(),
+			ReplicationMode: v1.TLSModeTLS,
+			PlaintextPort:   instance.GetPlaintextPort(),
+			TLSPort:         instance.GetRedisPort(),
+		}); err != nil {
+			return nil, err
+		}
+	}
+	return configMap, nil
+}
@@ -0,0 +1,114 @@
+package rediscluster
+
+import (
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	v1 "redis.operator/api/v1"
+	"redis.operator/pkg/kube/container"
+	"redis.operator/pkg/kube/dns"
+	"redis.operator/pkg/redisexporter"
+	"redis.operator/pkg/redisreplication"
+)
+
+// CreateContainer returns the init container copying redis.conf to the data volume. Nodes announce the dns name
+// of their pod, so clients are redirected to a name matching the certificate and surviving ip changes
+func CreateContainer(instance *v1.RedisCluster) (corev1.Container, error) {
+
+	args := fmt.Sprintf(
+		`
+	mkdir -p /tmp/redis
+	cp tmp/redis.conf /tmp/redis/
+	echo "cluster-announce-hostname %s" >> /tmp/redis/redis.conf
+	echo "cluster-preferred-endpoint-type hostname" >> /tmp/redis/redis.conf
+	`, dns.GetPodDNS("${POD_NAME}", instance.GetHeadlessServiceName(), instance.Namespace))
+
+	initContainer := container.NewBuilder().
+		SetName(instance.Name + "-init").
+		SetImage("busybox").
+		SetCommand([]string{"/bin/sh", "-c"}).
+		SetEnvs([]corev1.EnvVar{
+			{
+				Name: "POD_NAME",
+				ValueFrom: &corev1.EnvVarSource{
+					FieldRef: &corev1.ObjectFieldSelector{
+						FieldPath: "metadata.name",
+					},
+				},
+			},
+		}).
+		SetArgs([]string{args}).
+		SetImagePullPolicy(corev1.PullIfNotPresent).
+		SetSecurityContext(redisreplication.GetSecurityContext()).
+		SetVolumeMounts([]corev1.VolumeMount{
+			{
+				Name:      instance.GetConfigName(),
+				MountPath: "/tmp",
+			},
+			{
+				Name:      "redis-data",
+				MountPath: "/tmp/redis",
+			},
+		})
+
+	return initContainer.Build(), nil
+}
+
+func CreateContainers(instance *v1.RedisCluster) ([]corev1.Container, error) {
+
+	livenessProbe, err := GetLivenessProbe(instance)
+	if err != nil {
+		return nil, err
+	}
+
+	readinessProbe, err := GetReadinessProbe(instance)
+	if err != nil {
+		return nil, err
+	}
+
+	containers := []corev1.Container{}
+
+	redisContainer := container.NewBuilder().
+		SetName(instance.Name).
+		SetImage(container.GetRedisReplicationImage()).
+		SetImagePullPolicy(corev1.PullIfNotPresent).
+		SetResourceRequirements(instance.Spec.Resources).
+		SetLivenessProbe(livenessProbe).
+		SetReadinessProbe(readinessProbe).
+		SetSecurityContext(redisreplication.GetSecurityContext()).
+		SetVolumeMounts(instance.Spec.VolumeMounts).
+		SetVolumeMount(corev1.VolumeMount{
+			Name:      "redis-data",
+			MountPath: "/tmp/redis",
+		}).
+		SetArgs([]string{"/tmp/redis/redis.conf"})
+
+	containers = append(containers, redisContainer.Build())
+
+	if instance.IsExporterEnabled() {
+		passwordSecret, err := GetExporterPasswordSecret(instance)
+		if err != nil {
+			return nil, err
+		}
+
+		var tlsConfig *v1.TLSConfig
+		if instance.Spec.TLSConfig != nil {
+			if tlsConfig, err = instance.Spec.RedisConfig.GetConfigMapTLS(); err != nil {
+				return nil, err
+			}
+		}
+
+		containers = append(containers, redisexporter.CreateContainer(redisexporter.Options{
+			Name:            instance.Name,
+			Config:          instance.Spec.Exporter,
+			HostName:        dns.GetPodDNS("$(POD_NAME)", instance.GetHeadlessServiceName(), instance.Namespace),
+			Port:            instance.GetRedisPort(),
+			PasswordSecret:  passwordSecret,
+			TLS:             tlsConfig,
+			VolumeMounts:    instance.Spec.VolumeMounts,
+			SecurityContext: redisreplication.GetSecurityContext(),
+		}))
+	}
+
+	return containers, nil
+}
@@ -0,0 +1,61 @@
+package rediscluster
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	v1 "redis.operator/api/v1"
+	"redis.operator/pkg/config"
+	"redis.operator/pkg/kube/probe"
+	"redis.operator/pkg/util/scripts"
+)
+
+func GetPingScript(instance *v1.RedisCluster) ([]string, error) {
+	if instance.Spec.TLSConfig != nil {
+		tlsParams, err := instance.Spec.RedisConfig.GetConfigMapTLS()
+		if err != nil {
+			return nil, err
+		}
+		return scripts.GetPingScriptAuth(instance.GetRedisPort(), tlsParams.Cert, tlsParams.Key, tlsParams.CACert, tlsParams.Password), nil
+	}
+
+	password, err := instance.Spec.RedisConfig.GetValue("redis.conf", "requirepass")
+	if err != nil {
+		return nil, err
+	}
+	return scripts.GetPingScript(instance.GetRedisPort(), password), nil
+}
+
+func GetLivenessProbe(instance *v1.RedisCluster) (*corev1.Probe, error) {
+	script, err := GetPingScript(instance)
+	if err != nil {
+		return nil, err
+	}
+
+	return probe.NewBuilder().
+		SetInitialDelaySeconds(10).
+		SetTimeoutSeconds(1).
+		SetPeriodSeconds(10).
+		SetSuccessThreshold(1).
+		SetFailureThreshold(3).
+		SetExecAction(script).
+		SetTiming(config.Get().Probes.Liveness).
+		Build(), nil
+}
+
+// GetReadinessProbe only pings the node. A node is ready before it joined the cluster, the operator needs
+// to reach it through the headless service to send CLUSTER MEET
+func GetReadinessProbe(instance *v1.RedisCluster) (*corev1.Probe, error) {
+	script, err := GetPingScript(instance)
+	if err != nil {
+		return nil, err
+	}
+
+	return probe.NewBuilder().
+		SetInitialDelaySeconds(5).
+		SetTimeoutSeconds(1).
+		SetPeriodSeconds(5).
+		SetSuccessThreshold(1).
+		SetFailureThreshold(3).
+		SetExecAction(script).
+		SetTiming(config.Get().Probes.Readiness).
+		Build(), nil
+}
@@ -0,0 +1,99 @@
+package rediscluster
+
+import (
+	"strconv"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	v1 "redis.operator/api/v1"
+	"redis.operator/pkg/kube/service"
+)
+
+// label selecting the pods of a single shard
+const ShardLabel = "redis.redis.operator/shard"
+
+func GetClusterServiceLabels(instance *v1.RedisCluster) map[string]string {
+
+	return map[string]string{
+		"app.kubernetes.io/name":       instance.Name + "-service",
+		"app.kubernetes.io/instance":   "redis",
+		"app.kubernetes.io/version":    "1.0",
+		"app.kubernetes.io/component":  "redis-database",
+		"app.kubernetes.io/part-of":    "rediscluster",
+		"app.kubernetes.io/managed-by": "redis-operator",
+	}
+}
+
+// GetShardLabels returns the labels of the pods of shard, the cluster labels plus the shard index
+func GetShardLabels(instance *v1.RedisCluster, shard int) map[string]string {
+	labels := GetClusterServiceLabels(instance)
+	labels[ShardLabel] = strconv.Itoa(shard)
+	return labels
+}
+
+// CreateHeadlessClusterService returns the headless service shared by the statefulsets of every shard
+func CreateHeadlessClusterService(instance *v1.RedisCluster) corev1.Service {
+
+	port := instance.GetRedisPortInt32()
+	labels := GetClusterServiceLabels(instance)
+
+	serviceBuilder := service.NewBuilder().
+		SetName(instance.GetHeadlessServiceName()).
+		SetNamespace(instance.Namespace).
+		SetSelector(labels).
+		SetLabels(labels).
+		SetServiceType(corev1.ServiceTypeClusterIP).
+		SetClusterIP("None").
+		SetPublishNotReadyAddresses(true).
+		SetOwnerReference(instance.GetOwnerReference()).
+		SetPort(corev1.ServicePort{
+			Name:       "redis-client",
+			Port:       port,
+			TargetPort: intstr.FromInt32(port),
+			Protocol:   corev1.ProtocolTCP,
+		}).
+		SetPort(getBusServicePort(instance))
+
+	if instance.IsExporterEnabled() {
+		exporterPort := instance.Spec.Exporter.GetPort()
+		serviceBuilder.SetPort(corev1.ServicePort{
+			Name:       "redis-exporter",
+			Port:       exporterPort,
+			TargetPort: intstr.FromInt32(exporterPort),
+			Protocol:   corev1.ProtocolTCP,
+		})
+	}
+	return serviceBuilder.Build()
+}
+
+// CreateClusterService returns the service cluster aware clients use to discover the nodes
+func CreateClusterService(instance *v1.RedisCluster) corev1.Service {
+	port := instance.GetRedisPortInt32()
+	labels := GetClusterServiceLabels(instance)
+
+	return service.NewBuilder().
+		SetName(instance.GetServiceName()).
+		SetNamespace(instance.Namespace).
+		SetSelector(labels).
+		SetLabels(labels).
+		SetServiceType(corev1.ServiceTypeClusterIP).
+		SetOwnerReference(instance.GetOwnerReference()).
+		SetPort(corev1.ServicePort{
+			Name:       "redis-client",
+			Port:       port,
+			TargetPort: intstr.FromInt32(port),
+			Protocol:   corev1.ProtocolTCP,
+		}).
+		Build()
+}
+
+// getBusServicePort returns the port of the cluster bus, the client port + 10000
+func getBusServicePort(instance *v1.RedisCluster) corev1.ServicePort {
+	port := instance.GetRedisPortInt32() + 10000
+	return corev1.ServicePort{
+		Name:       "redis-bus",
+		Port:       port,
+		TargetPort: intstr.FromInt32(port),
+		Protocol:   corev1.ProtocolTCP,
+	}
+}
@@ -0,0 +1,132 @@
+package rediscluster
+
+import (
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+	v1 "redis.operator/api/v1"
+	"redis.operator/pkg/redisexporter"
+)
+
+// CreateStatefulSet returns the statefulset of shard, running the shard master and its replicas. Which pod is
+// the master is decided by the cluster, the statefulset doesn't know about roles
+func CreateStatefulSet(instance *v1.RedisCluster, shard int, redisContainers []corev1.Container, initContainer corev1.Container) *appsv1.StatefulSet {
+
+	volumes := []corev1.Volume{
+		{
+			Name: instance.GetConfigName(),
+			VolumeSource: corev1.VolumeSource{
+				ConfigMap: &corev1.ConfigMapVolumeSource{
+					LocalObjectReference: corev1.LocalObjectReference{
+						Name: instance.GetConfigName(),
+					},
+				},
+			},
+		},
+		{
+			Name: "redis-data",
+			VolumeSource: corev1.VolumeSource{
+				EmptyDir: &corev1.EmptyDirVolumeSource{},
+			},
+		},
+	}
+
+	if instance.Spec.TLSConfig != nil {
+		volumes = append(volumes, corev1.Volume{
+			Name: instance.Spec.TLSConfig.Name,
+			VolumeSource: corev1.VolumeSource{
+				Secret: &corev1.SecretVolumeSource{
+					SecretName: instance.GetTLSSecretName(),
+				},
+			},
+		})
+	}
+
+	if volume := redisexporter.GetScriptVolume(instance.Name, instance.Spec.Exporter); volume != nil && instance.IsExporterEnabled() {
+		volumes = append(volumes, *volume)
+	}
+
+	annotations := map[string]string{}
+	if mode := instance.Spec.TLSConfig.GetMode(); mode != "" {
+		annotations[v1.TLSModeAnnotation] = mode
+	}
+
+	labels := GetShardLabels(instance, shard)
+	podSpec := instance.Spec.StatefulsetConfig.Wrapper.Spec.Template.Spec
+
+	statefulSet := &appsv1.StatefulSet{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "StatefulSet",
+			APIVersion: "apps/v1",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      instance.GetShardName(shard),
+			Namespace: instance.Namespace,
+			Labels:    labels,
+		},
+		Spec: appsv1.StatefulSetSpec{
+			Replicas: ptr.To(int32(instance.GetShardSize())),
+			Selector: &metav1.LabelSelector{
+				MatchLabels: labels,
+			},
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels:      labels,
+					Annotations: annotations,
+				},
+				Spec: corev1.PodSpec{
+					Volumes:                       append(volumes, podSpec.Volumes...),
+					InitContainers:                append([]corev1.Container{initContainer}, podSpec.InitContainers...),
+					Containers:                    redisContainers,
+					EphemeralContainers:           podSpec.EphemeralContainers,
+					RestartPolicy:                 podSpec.RestartPolicy,
+					TerminationGracePeriodSeconds: podSpec.TerminationGracePeriodSeconds,
+					ActiveDeadlineSeconds:         podSpec.ActiveDeadlineSeconds,
+					DNSPolicy:                     podSpec.DNSPolicy,
+					NodeSelector:                  podSpec.NodeSelector,
+					ServiceAccountName:            podSpec.ServiceAccountName,
+					DeprecatedServiceAccount:      podSpec.DeprecatedServiceAccount,
+					AutomountServiceAccountToken:  podSpec.AutomountServiceAccountToken,
+					NodeName:                      podSpec.NodeName,
+					HostNetwork:                   podSpec.HostNetwork,
+					HostPID:                       podSpec.HostPID,
+					HostIPC:                       podSpec.HostIPC,
+					ShareProcessNamespace:         podSpec.ShareProcessNamespace,
+					SecurityContext:               podSpec.SecurityContext,
+					ImagePullSecrets:              podSpec.ImagePullSecrets,
+					Hostname:                      podSpec.Hostname,
+					Subdomain:                     podSpec.Subdomain,
+					Affinity:                      podSpec.Affinity,
+					SchedulerName:                 podSpec.SchedulerName,
+					Tolerations:                   podSpec.Tolerations,
+					HostAliases:                   podSpec.HostAliases,
+					PriorityClassName:             podSpec.PriorityClassName,
+					Priority:                      podSpec.Priority,
+					ReadinessGates:                podSpec.ReadinessGates,
+					RuntimeClassName:              podSpec.RuntimeClassName,
+					EnableServiceLinks:            podSpec.EnableServiceLinks,
+					PreemptionPolicy:              podSpec.PreemptionPolicy,
+					Overhead:                      podSpec.Overhead,
+					TopologySpreadConstraints:     podSpec.TopologySpreadConstraints,
+					SetHostnameAsFQDN:             podSpec.SetHostnameAsFQDN,
+					OS:                            podSpec.OS,
+					HostUsers:                     podSpec.HostUsers,
+					SchedulingGates:               podSpec.SchedulingGates,
+					ResourceClaims:                podSpec.ResourceClaims,
+				},
+			},
+			VolumeClaimTemplates:                 instance.Spec.StatefulsetConfig.Wrapper.Spec.VolumeClaimTemplates,
+			ServiceName:                          instance.GetHeadlessServiceName(),
+			PodManagementPolicy:                  appsv1.ParallelPodManagement,
+			UpdateStrategy:                       instance.Spec.StatefulsetConfig.Wrapper.Spec.UpdateStrategy,
+			RevisionHistoryLimit:                 instance.Spec.StatefulsetConfig.Wrapper.Spec.RevisionHistoryLimit,
+			MinReadySeconds:                      instance.Spec.StatefulsetConfig.Wrapper.Spec.MinReadySeconds,
+			PersistentVolumeClaimRetentionPolicy: instance.Spec.StatefulsetConfig.Wrapper.Spec.PersistentVolumeClaimRetentionPolicy,
+		},
+	}
+
+	statefulSet.SetOwnerReferences(append(statefulSet.GetOwnerReferences(), instance.GetOwnerReference()))
+
+	return statefulSet
+}
@@ -0,0 +1,168 @@
+package redisexporter
+
+import (
+	"fmt"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	v1 "redis.operator/api/v1"
+	"redis.operator/pkg/kube/container"
+)
+
+const (
+	ScriptMountPath = "/etc/redis-exporter"
+	scriptFile      = "script.lua"
+)
+
+// Options describes the redis or sentinel instance scraped by the exporter sidecar
+type Options struct {
+	Name     string
+	Config   *v1.RedisExporterConfiguration
+	HostName string // resolvable name matching the server certificate, may reference $(POD_NAME)
+	Port     string
+	// nil when the exporter connects without a password
+	PasswordSecret *corev1.SecretKeySelector
+	// nil when the instance is not using tls
+	TLS             *v1.TLSConfig
+	VolumeMounts    []corev1.VolumeMount
+	SecurityContext *corev1.SecurityContext
+}
+
+func GetScriptVolumeName(name string) string {
+	return name + "-exporter-script"
+}
+
+// GetScriptVolume returns the volume holding the lua script of the exporter, nil if no script is configured
+func GetScriptVolume(name string, config *v1.RedisExporterConfiguration) *corev1.Volume {
+	if config == nil || config.Script == nil {
+		return nil
+	}
+
+	return &corev1.Volume{
+		Name: GetScriptVolumeName(name),
+		VolumeSource: corev1.VolumeSource{
+			ConfigMap: &corev1.ConfigMapVolumeSource{
+				LocalObjectReference: config.Script.LocalObjectReference,
+				Items: []corev1.KeyToPath{
+					{
+						Key:  config.Script.Key,
+						Path: scriptFile,
+					},
+				},
+				Optional: config.Script.Optional,
+			},
+		},
+	}
+}
+
+func CreateContainer(options Options) corev1.Container {
+	config := options.Config
+	if config == nil {
+		config = &v1.RedisExporterConfiguration{}
+	}
+
+	image := config.Image
+	if image == "" {
+		image = container.GetRedisExporterImage()
+	}
+	port := config.GetPort()
+
+	envs := []corev1.EnvVar{
+		{
+			// referenced by the redis address, needs to be declared first
+			Name: "POD_NAME",
+			ValueFrom: &corev1.EnvVarSource{
+				FieldRef: &corev1.ObjectFieldSelector{
+					FieldPath: "metadata.name",
+				},
+			},
+		},
+		{
+			Name:  "REDIS_EXPORTER_INCL_SYSTEM_METRICS",
+			Value: "true",
+		},
+		{
+			Name:  "REDIS_EXPORTER_WEB_LISTEN_ADDRESS",
+			Value: fmt.Sprintf(":%d", port),
+		},
+	}
+
+	if options.PasswordSecret != nil {
+		envs = append(envs, corev1.EnvVar{
+			Name: "REDIS_PASSWORD",
+			ValueFrom: &corev1.EnvVarSource{
+				SecretKeyRef: options.PasswordSecret,
+			},
+		})
+	}
+
+	if len(config.CheckKeys) > 0 {
+		envs = append(envs, corev1.EnvVar{
+			Name:  "REDIS_EXPORTER_CHECK_KEYS",
+			Value: strings.Join(config.CheckKeys, ","),
+		})
+	}
+
+	if options.TLS != nil {
+		// the server certificate is verified against the mounted CA, so the address has to match its SANs
+		envs = append(envs, []corev1.EnvVar{
+			{
+				Name:  "REDIS_ADDR",
+				Value: fmt.Sprintf("rediss://%s:%s", options.HostName, options.Port),
+			},
+			{
+				Name:  "REDIS_EXPORTER_TLS_CA_CERT_FILE",
+				Value: options.TLS.CACert,
+			},
+			{
+				Name:  "REDIS_EXPORTER_TLS_CLIENT_CERT_FILE",
+				Value: options.TLS.Cert,
+			},
+			{
+				Name:  "REDIS_EXPORTER_TLS_CLIENT_KEY_FILE",
+				Value: options.TLS.Key,
+			},
+		}...)
+	} else {
+		envs = append(envs, corev1.EnvVar{
+			Name:  "REDIS_ADDR",
+			Value: "redis://localhost:" + options.Port,
+		})
+	}
+
+	exporterContainer := container.NewBuilder().
+		SetName(options.Name + "-exporter").
+		SetImage(image).
+		SetImagePullPolicy(corev1.PullIfNotPresent).
+		SetVolumeMounts(options.VolumeMounts).
+		SetSecurityContext(options.SecurityContext).
+		SetResourceRequirements(config.Resources).
+		SetEnvs(envs).
+		SetPorts([]corev1.ContainerPort{
+			{
+				Name:          "redis-exporter",
+				ContainerPort: port,
+				Protocol:      corev1.ProtocolTCP,
+			},
+		})
+
+	if config.Script != nil {
+		exporterContainer.SetVolumeMount(corev1.VolumeMount{
+			Name:      GetScriptVolumeName(options.Name),
+			MountPath: ScriptMountPath,
+			ReadOnly:  true,
+		})
+		exporterContainer.SetEnvs([]corev1.EnvVar{
+			{
+				Name:  "REDIS_EXPORTER_SCRIPT",
+				Value: ScriptMountPath + "/" + scriptFile,
+			},
+		})
+	}
+
+	if len(config.ExtraArgs) > 0 {
+		exporterContainer.SetArgs(config.ExtraArgs)
+	}
+
+	return exporterContainer.Build()
+}
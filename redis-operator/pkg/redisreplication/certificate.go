@@ -0,0 +1,31 @@
+package redisreplication
+
+import (
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	v1 "redis.operator/api/v1"
+	"redis.operator/pkg/kube/custom"
+)
+
+// GetCertificateDNSNames returns the SANs of the replication and, if configured, its sentinel. Sentinels of another
+// namespace can't mount the secret and are left out
+func GetCertificateDNSNames(instance *v1.RedisReplication) []string {
+	dnsNames := custom.ServiceDNSNames(instance.Name, instance.Namespace)
+	if sentinel := instance.GetSentinelReference(); sentinel.Name != "" && sentinel.Namespace == instance.Namespace {
+		dnsNames = append(dnsNames, custom.ServiceDNSNames(sentinel.Name, sentinel.Namespace)...)
+	}
+	return dnsNames
+}
+
+// CreateCertificate returns the cert-manager Certificate for an instance with tls.issuerRef set. Sentinels without
+// tls settings of their own mount the secret of the replication, so the certificate covers both
+func CreateCertificate(instance *v1.RedisReplication) *unstructured.Unstructured {
+	return custom.NewCertificate(
+		instance.GetCertificateName(),
+		instance.Namespace,
+		GetReplicationServiceLabels(instance),
+		instance.GetOwnerReference(),
+		instance.GetTLSSecretName(),
+		GetCertificateDNSNames(instance),
+		instance.Spec.TLSConfig,
+	)
+}
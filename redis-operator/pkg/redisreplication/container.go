@@ -4,10 +4,11 @@ import (
 	"fmt"
 
 	corev1 "k8s.io/api/core/v1"
-	"k8s.io/apimachinery/pkg/api/resource"
 	"k8s.io/utils/ptr"
 	v1 "redis.operator/api/v1"
 	"redis.operator/pkg/kube/container"
+	"redis.operator/pkg/kube/dns"
+	"redis.operator/pkg/redisexporter"
 )
 
 func GetSecurityContext() *corev1.SecurityContext {
@@ -30,14 +31,20 @@ func CreateContainer(instance *v1.RedisReplication) (corev1.Container, error) {
 		}
 	}
 
+	// Replicas announce their pod ip when the operator is configured to, their hostname otherwise
+	announceIP := dns.GetPodDNS("${POD_NAME}", instance.GetHeadlessServiceName(), instance.Namespace)
+	if dns.IsAnnouncingPodIP() {
+		announceIP = "${POD_IP}"
+	}
+
 	args := fmt.Sprintf(
 		`
 	mkdir -p /tmp/redis
 	cp tmp/redis.conf /tmp/redis/
-	replica_announce_ip="${POD_NAME}.%s.%s.svc.cluster.local"
+	replica_announce_ip="%s"
 	echo "replica-announce-ip ${replica_announce_ip}" >> /tmp/redis/redis.conf
 	sleep %d
-	`, instance.GetHeadlessServiceName(), instance.Namespace, seconds)
+	`, announceIP, seconds)
 
 	initContainer := container.NewBuilder().
 		SetName(instance.Name + "-init").
@@ -52,6 +59,14 @@ func CreateContainer(instance *v1.RedisReplication) (corev1.Container, error) {
 					},
 				},
 			},
+			{
+				Name: "POD_IP",
+				ValueFrom: &corev1.EnvVarSource{
+					FieldRef: &corev1.ObjectFieldSelector{
+						FieldPath: "status.podIP",
+					},
+				},
+			},
 		}).
 		SetArgs([]string{args}).
 		SetImagePullPolicy(corev1.PullIfNotPresent).
@@ -102,92 +117,29 @@ func CreateContainers(instance *v1.RedisReplication) ([]corev1.Container, error)
 
 	containers = append(containers, redisContainer.Build())
 
-	if instance.Spec.EnableExporter {
-		exportContainer := container.NewBuilder().
-			SetName(instance.Name + "-exporter").
-			SetImage(container.GetRedisExporterImage()).
-			SetImagePullPolicy(corev1.PullIfNotPresent).
-			SetVolumeMounts(instance.Spec.VolumeMounts).
-			SetSecurityContext(GetSecurityContext()).
-			SetVolumeMount(corev1.VolumeMount{
-				Name:      "redis-data",
-				MountPath: "/tmp/redis",
-			}).
-			SetResourceRequirements(&corev1.ResourceRequirements{
-				Requests: corev1.ResourceList{
-					corev1.ResourceCPU:    resource.MustParse("100m"),
-					corev1.ResourceMemory: resource.MustParse("100Mi"),
-				},
-				Limits: corev1.ResourceList{
-					corev1.ResourceCPU:    resource.MustParse("100m"),
-					corev1.ResourceMemory: resource.MustParse("100Mi"),
-				},
-			}).
-			SetPorts([]corev1.ContainerPort{
-				{
-					Name:          "redis-exporter",
-					ContainerPort: 9121,
-					Protocol:      corev1.ProtocolTCP,
-				},
-			})
-
-		password, err := instance.Spec.RedisConfig.GetValue("redis.conf", "requirepass")
+	if instance.IsExporterEnabled() {
+		passwordSecret, err := GetExporterPasswordSecret(instance)
 		if err != nil {
 			return nil, err
 		}
 
-		exportContainer.SetEnvs([]corev1.EnvVar{
-			{
-				Name:  "REDIS_EXPORTER_INCL_SYSTEM_METRICS",
-				Value: "true",
-			},
-			{
-				Name:  "REDIS_PASSWORD",
-				Value: password,
-			},
-		})
-
+		var tlsConfig *v1.TLSConfig
 		if instance.Spec.TLSConfig != nil {
-
-			tlsConfig, err := instance.Spec.RedisConfig.GetConfigMapTLS()
-			if err != nil {
+			if tlsConfig, err = instance.Spec.RedisConfig.GetConfigMapTLS(); err != nil {
 				return nil, err
 			}
-			exportContainer.SetEnvs([]corev1.EnvVar{
-				{
-					Name:  "REDIS_EXPORTER_TLS_CLIENT_KEY_FILE",
-					Value: tlsConfig.Key,
-				},
-				{
-					Name:  "REDIS_EXPORTER_TLS_CA_CERT_FILE",
-					Value: tlsConfig.CACert,
-				},
-				{
-					Name:  "REDIS_EXPORTER_TLS_CLIENT_CERT_FILE",
-					Value: tlsConfig.Cert,
-				},
-				{
-					Name:  "REDIS_ADDR",
-					Value: "rediss://localhost:" + instance.GetRedisPort(),
-				},
-				{
-					Name:  "REDIS_EXPORTER_SKIP_TLS_VERIFICATION",
-					Value: "true",
-				},
-				{
-					Name:  "REDIS_EXPORTER_DEBUG",
-					Value: "true",
-				},
-			})
-		} else {
-			exportContainer.SetEnvs([]corev1.EnvVar{
-				{
-					Name:  "REDIS_ADDR",
-					Value: "redis://localhost:" + instance.GetRedisPort(),
-				},
-			})
 		}
-		containers = append(containers, exportContainer.Build())
+
+		containers = append(containers, redisexporter.CreateContainer(redisexporter.Options{
+			Name:            instance.Name,
+			Config:          instance.Spec.Exporter,
+			HostName:        dns.GetPodDNS("$(POD_NAME)", instance.GetHeadlessServiceName(), instance.Namespace),
+			Port:            instance.GetRedisPort(),
+			PasswordSecret:  passwordSecret,
+			TLS:             tlsConfig,
+			VolumeMounts:    instance.Spec.VolumeMounts,
+			SecurityContext: GetSecurityContext(),
+		}))
 	}
 
 	return containers, nil
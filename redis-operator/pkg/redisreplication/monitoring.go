@@ -0,0 +1,121 @@
+package redisreplication
+
+import (
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	v1 "redis.operator/api/v1"
+	"redis.operator/pkg/kube/custom"
+)
+
+func getMonitoringLabels(instance *v1.RedisReplication, extraLabels map[string]string) map[string]string {
+	labels := GetReplicationServiceLabels(instance)
+	for key, value := range extraLabels {
+		labels[key] = value
+	}
+	return labels
+}
+
+// CreateServiceMonitor scrapes the exporter port of the headless service
+func CreateServiceMonitor(instance *v1.RedisReplication) *unstructured.Unstructured {
+	config := &v1.ServiceMonitorConfiguration{}
+	if instance.Spec.Monitoring != nil && instance.Spec.Monitoring.ServiceMonitor != nil {
+		config = instance.Spec.Monitoring.ServiceMonitor
+	}
+
+	interval := config.Interval
+	if interval == "" {
+		interval = "30s"
+	}
+	scrapeTimeout := config.ScrapeTimeout
+	if scrapeTimeout == "" {
+		scrapeTimeout = "10s"
+	}
+
+	matchLabels := map[string]interface{}{}
+	for key, value := range GetReplicationServiceLabels(instance) {
+		matchLabels[key] = value
+	}
+
+	return custom.NewObject("monitoring.coreos.com/v1", "ServiceMonitor", instance.GetServiceMonitorName(), instance.Namespace,
+		getMonitoringLabels(instance, config.Labels), instance.GetOwnerReference(), map[string]interface{}{
+			"selector": map[string]interface{}{
+				"matchLabels": matchLabels,
+			},
+			"namespaceSelector": map[string]interface{}{
+				"matchNames": []interface{}{instance.Namespace},
+			},
+			"endpoints": []interface{}{
+				map[string]interface{}{
+					"port":          "redis-exporter",
+					"path":          "/metrics",
+					"interval":      interval,
+					"scrapeTimeout": scrapeTimeout,
+				},
+			},
+		})
+}
+
+// CreatePrometheusRule alerts on a missing master, broken replication links and memory close to maxmemory
+func CreatePrometheusRule(instance *v1.RedisReplication) *unstructured.Unstructured {
+	config := &v1.PrometheusRuleConfiguration{}
+	if instance.Spec.Monitoring != nil && instance.Spec.Monitoring.PrometheusRule != nil {
+		config = instance.Spec.Monitoring.PrometheusRule
+	}
+
+	memoryThreshold := 90
+	if config.MemoryThreshold != nil {
+		memoryThreshold = *config.MemoryThreshold
+	}
+	duration := config.For
+	if duration == "" {
+		duration = "5m"
+	}
+	severity := config.Severity
+	if severity == "" {
+		severity = "warning"
+	}
+
+	selector := fmt.Sprintf(`namespace="%s",service="%s"`, instance.Namespace, instance.GetHeadlessServiceName())
+	alertLabels := map[string]interface{}{
+		"severity": severity,
+	}
+
+	rule := func(alert string, expr string, summary string, description string) map[string]interface{} {
+		return map[string]interface{}{
+			"alert":  alert,
+			"expr":   expr,
+			"for":    duration,
+			"labels": alertLabels,
+			"annotations": map[string]interface{}{
+				"summary":     summary,
+				"description": description,
+			},
+		}
+	}
+
+	rules := []interface{}{
+		rule("RedisMasterMissing",
+			fmt.Sprintf(`absent(redis_instance_info{%s,role="master"})`, selector),
+			fmt.Sprintf("Redis replication %s/%s has no master", instance.Namespace, instance.Name),
+			"None of the scraped redis pods reports the master role."),
+		rule("RedisReplicationBroken",
+			fmt.Sprintf(`redis_master_link_up{%s} == 0`, selector),
+			fmt.Sprintf("Redis replication %s/%s has a broken replication link", instance.Namespace, instance.Name),
+			"Replica {{ $labels.pod }} is not connected to its master."),
+		rule("RedisMemoryNearMax",
+			fmt.Sprintf(`redis_memory_used_bytes{%[1]s} / (redis_memory_max_bytes{%[1]s} > 0) * 100 > %[2]d`, selector, memoryThreshold),
+			fmt.Sprintf("Redis replication %s/%s is close to maxmemory", instance.Namespace, instance.Name),
+			fmt.Sprintf("Pod {{ $labels.pod }} uses more than %d%% of maxmemory.", memoryThreshold)),
+	}
+
+	return custom.NewObject("monitoring.coreos.com/v1", "PrometheusRule", instance.GetPrometheusRuleName(), instance.Namespace,
+		getMonitoringLabels(instance, config.Labels), instance.GetOwnerReference(), map[string]interface{}{
+			"groups": []interface{}{
+				map[string]interface{}{
+					"name":  fmt.Sprintf("%s.%s.rules", instance.Namespace, instance.Name),
+					"rules": rules,
+				},
+			},
+		})
+}
@@ -3,6 +3,7 @@ package redisreplication
 import (
 	corev1 "k8s.io/api/core/v1"
 	v1 "redis.operator/api/v1"
+	"redis.operator/pkg/config"
 	"redis.operator/pkg/kube/probe"
 	"redis.operator/pkg/util/scripts"
 )
@@ -25,6 +26,10 @@ func GetLivenessScript(instance *v1.RedisReplication) ([]string, error) {
 }
 
 func GetReadinessScript(instance *v1.RedisReplication) ([]string, error) {
+	if instance.IsStandalone() {
+		return GetLivenessScript(instance) // no replication link to wait for
+	}
+
 	if instance.Spec.TLSConfig != nil {
 		tlsParams, err := instance.Spec.RedisConfig.GetConfigMapTLS()
 		if err != nil {
@@ -53,6 +58,7 @@ func GetLivenessProbe(instance *v1.RedisReplication) (*corev1.Probe, error) {
 		SetSuccessThreshold(1).
 		SetFailureThreshold(3).
 		SetExecAction(script).
+		SetTiming(config.Get().Probes.Liveness).
 		Build(), nil
 }
 
@@ -64,12 +70,18 @@ func GetReadinessProbe(instance *v1.RedisReplication) (*corev1.Probe, error) {
 		return nil, err
 	}
 
+	initialDelay := int32(30) // need a lengthy delay
+	if instance.IsStandalone() {
+		initialDelay = 5
+	}
+
 	return probe.NewBuilder().
-		SetInitialDelaySeconds(30). // need a lengthy delay
+		SetInitialDelaySeconds(initialDelay).
 		SetTimeoutSeconds(1).
 		SetPeriodSeconds(5).
 		SetSuccessThreshold(1).
 		SetFailureThreshold(3).
 		SetExecAction(script).
+		SetTiming(config.Get().Probes.Readiness).
 		Build(), nil
 }
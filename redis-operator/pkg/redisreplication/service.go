@@ -39,12 +39,16 @@ func CreateHeadlessReplicationService(instance *v1.RedisReplication) corev1.Serv
 			TargetPort: intstr.FromInt32(port),
 			Protocol:   corev1.ProtocolTCP,
 		})
+	if plaintextPort := getPlaintextServicePort(instance); plaintextPort != nil {
+		serviceBuilder.SetPort(*plaintextPort)
+	}
 
-	if instance.Spec.EnableExporter {
+	if instance.IsExporterEnabled() {
+		exporterPort := instance.Spec.Exporter.GetPort()
 		serviceBuilder.SetPort(corev1.ServicePort{
 			Name:       "redis-exporter",
-			Port:       9121,
-			TargetPort: intstr.FromInt(9121),
+			Port:       exporterPort,
+			TargetPort: intstr.FromInt32(exporterPort),
 			Protocol:   corev1.ProtocolTCP,
 		})
 	}
@@ -68,5 +72,22 @@ func CreateReplicationService(instance *v1.RedisReplication) corev1.Service {
 			TargetPort: intstr.FromInt32(port),
 			Protocol:   corev1.ProtocolTCP,
 		})
+	if plaintextPort := getPlaintextServicePort(instance); plaintextPort != nil {
+		serviceBuilder.SetPort(*plaintextPort)
+	}
 	return serviceBuilder.Build()
 }
+
+// getPlaintextServicePort returns the plaintext port exposed next to the tls port while clients migrate, nil outside of Dual mode
+func getPlaintextServicePort(instance *v1.RedisReplication) *corev1.ServicePort {
+	if !instance.Spec.TLSConfig.IsDualMode() {
+		return nil
+	}
+	port := instance.GetPlaintextPortInt32()
+	return &corev1.ServicePort{
+		Name:       "redis-plaintext",
+		Port:       port,
+		TargetPort: intstr.FromInt32(port),
+		Protocol:   corev1.ProtocolTCP,
+	}
+}
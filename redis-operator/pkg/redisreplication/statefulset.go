@@ -4,7 +4,9 @@ import (
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
 	v1 "redis.operator/api/v1"
+	"redis.operator/pkg/redisexporter"
 )
 
 func CreateStatefulSet(instance *v1.RedisReplication, redisContainers []corev1.Container, initContainer corev1.Container) *appsv1.StatefulSet {
@@ -33,12 +35,26 @@ func CreateStatefulSet(instance *v1.RedisReplication, redisContainers []corev1.C
 			Name: instance.Spec.TLSConfig.Name,
 			VolumeSource: corev1.VolumeSource{
 				Secret: &corev1.SecretVolumeSource{
-					SecretName: instance.Spec.TLSConfig.SecretName,
+					SecretName: instance.GetTLSSecretName(),
 				},
 			},
 		})
 	}
 
+	if volume := redisexporter.GetScriptVolume(instance.Name, instance.Spec.Exporter); volume != nil && instance.IsExporterEnabled() {
+		volumes = append(volumes, *volume)
+	}
+
+	replicas := instance.Spec.StatefulsetConfig.Wrapper.Spec.Replicas
+	if instance.IsStandalone() {
+		replicas = ptr.To(int32(1))
+	}
+
+	annotations := map[string]string{}
+	if mode := instance.Spec.TLSConfig.GetMode(); mode != "" {
+		annotations[v1.TLSModeAnnotation] = mode
+	}
+
 	statefulSet := &appsv1.StatefulSet{
 		TypeMeta: metav1.TypeMeta{
 			Kind:       "StatefulSet",
@@ -50,13 +66,14 @@ func CreateStatefulSet(instance *v1.RedisReplication, redisContainers []corev1.C
 			Labels:    GetReplicationServiceLabels(instance),
 		},
 		Spec: appsv1.StatefulSetSpec{
-			Replicas: instance.Spec.StatefulsetConfig.Wrapper.Spec.Replicas,
+			Replicas: replicas,
 			Selector: &metav1.LabelSelector{
 				MatchLabels: GetReplicationServiceLabels(instance),
 			},
 			Template: corev1.PodTemplateSpec{
 				ObjectMeta: metav1.ObjectMeta{
-					Labels: GetReplicationServiceLabels(instance),
+					Labels:      GetReplicationServiceLabels(instance),
+					Annotations: annotations,
 				},
 				Spec: corev1.PodSpec{
 					Volumes:                       append(volumes, instance.Spec.StatefulsetConfig.Wrapper.Spec.Template.Spec.Volumes...),
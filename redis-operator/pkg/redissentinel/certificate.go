@@ -0,0 +1,25 @@
+package redissentinel
+
+import (
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	v1 "redis.operator/api/v1"
+	"redis.operator/pkg/kube/custom"
+)
+
+// CreateCertificate returns the cert-manager Certificate for a sentinel with tls.issuerRef set
+func CreateCertificate(instance *v1.RedisSentinel) *unstructured.Unstructured {
+	secretName := instance.Spec.TLSConfig.SecretName
+	if secretName == "" {
+		secretName = instance.GetCertificateName()
+	}
+
+	return custom.NewCertificate(
+		instance.GetCertificateName(),
+		instance.Namespace,
+		GetSentinelServiceLabels(instance),
+		instance.GetOwnerReference(),
+		secretName,
+		custom.ServiceDNSNames(instance.Name, instance.Namespace),
+		instance.Spec.TLSConfig,
+	)
+}
@@ -1,48 +1,91 @@
 package redissentinel
 
 import (
-	"context"
 	"fmt"
+	"strconv"
+	"strings"
 
-	"github.com/go-logr/logr"
 	corev1 "k8s.io/api/core/v1"
-	"k8s.io/client-go/kubernetes"
 	v1 "redis.operator/api/v1"
 	"redis.operator/pkg/kube/configmap"
+	"redis.operator/pkg/kube/dns"
 	k8sredis "redis.operator/pkg/redis"
 )
 
-func UpdateConfigMap(ctx context.Context, sentinelInstance *v1.RedisSentinel, replicaInstance *v1.RedisReplication, k8Client kubernetes.Interface, configMap *corev1.ConfigMap, reqLogger logr.Logger) (error, bool) {
-
-	replicaPort := replicaInstance.GetRedisPort()
+// getMasterDirective returns the option and value of a "sentinel <option> <master> <value>" line of a monitored
+// master
+func getMasterDirective(fields []string, masters map[string]bool) (string, string, bool) {
+	if len(fields) < 3 || !strings.EqualFold(fields[0], "sentinel") || !masters[fields[2]] {
+		return "", "", false
+	}
+	return strings.ToLower(fields[1]), strings.Join(fields[3:], " "), true
+}
 
-	redisInfo, err := k8sredis.GetReplicaInfo(ctx, k8Client, replicaInstance, reqLogger)
-	if err != nil {
-		return err, true
+func getMonitoredMasters(sentinelInstance *v1.RedisSentinel) map[string]bool {
+	masters := map[string]bool{}
+	for _, monitor := range sentinelInstance.GetMonitors() {
+		masters[monitor.MasterName] = true
 	}
+	return masters
+}
 
-	masterDNS := []string{}
-	for _, info := range redisInfo {
-		if role, ok := info.Info["role"]; ok {
-			if role == "master" {
-				masterDNS = append(masterDNS, info.DNS)
-			}
+// GetMonitorSettings returns the options of a monitored master, applied with SENTINEL SET once it is monitored.
+// The lines of sentinel.conf are overridden by the settings of the sentinel, the downtime of the replication and
+// the settings of the monitor, in that order
+func GetMonitorSettings(sentinelInstance *v1.RedisSentinel, monitor v1.RedisSentinelMonitor, replicaInstance *v1.RedisReplication) map[string]string {
+	settings := map[string]string{}
+	for _, line := range strings.Split(sentinelInstance.Spec.RedisConfig.Data["sentinel.conf"], "\n") {
+		option, value, ok := getMasterDirective(strings.Fields(line), map[string]bool{monitor.MasterName: true})
+		if ok && option != "monitor" {
+			settings[option] = value
 		}
 	}
 
-	if len(masterDNS) != 1 {
-		return fmt.Errorf("failed to update configmap. uncertain master IP. retry later"), false
+	for option, value := range sentinelInstance.Spec.Settings.GetOptions() {
+		settings[option] = value
+	}
+	if sentinelConfig := replicaInstance.Spec.RedisSentinelConfig; sentinelConfig != nil && sentinelConfig.RedisSentinelDowntime != nil {
+		settings["down-after-milliseconds"] = strconv.Itoa(*sentinelConfig.RedisSentinelDowntime)
+	}
+	for option, value := range monitor.Settings.GetOptions() {
+		settings[option] = value
 	}
+	return settings
+}
 
-	if !configmap.UpdateConfigMapKey(configMap, "sentinel.conf", "sentinel monitor", fmt.Sprintf("sentinel monitor %s %s %s %d", sentinelInstance.Spec.MasterName, masterDNS[0], replicaPort, sentinelInstance.Spec.RedisSentinelQuorum)) {
-		return fmt.Errorf("failed to update configmap"), true
+// UpdateConfigMap writes the settings shared by every monitored master to sentinel.conf. The masters are added at
+// runtime, their lines are removed because sentinels refuse options of masters they do not monitor yet
+func UpdateConfigMap(sentinelInstance *v1.RedisSentinel, replicaInstance *v1.RedisReplication, configMap *corev1.ConfigMap) error {
+	masters := getMonitoredMasters(sentinelInstance)
+	if !configmap.RemoveConfigMapLines(configMap, "sentinel.conf", func(fields []string) bool {
+		_, _, ok := getMasterDirective(fields, masters)
+		return ok
+	}) {
+		return fmt.Errorf("failed to update configmap")
 	}
+
 	if !configmap.UpdateConfigMapKey(configMap, "sentinel.conf", "SENTINEL resolve-hostnames", "SENTINEL resolve-hostnames yes") {
-		return fmt.Errorf("failed to update configmap"), true
+		return fmt.Errorf("failed to update configmap")
+	}
+	announceHostnames := "SENTINEL announce-hostnames yes"
+	if dns.IsAnnouncingPodIP() {
+		announceHostnames = "SENTINEL announce-hostnames no"
 	}
-	if !configmap.UpdateConfigMapKey(configMap, "sentinel.conf", "SENTINEL announce-hostnames", "SENTINEL announce-hostnames yes") {
-		return fmt.Errorf("failed to update configmap"), true
+	if !configmap.UpdateConfigMapKey(configMap, "sentinel.conf", "SENTINEL announce-hostnames", announceHostnames) {
+		return fmt.Errorf("failed to update configmap")
+	}
+
+	if sentinelInstance.GetTLSConfig(replicaInstance) != nil {
+		if err := k8sredis.UpdateConfigMapTLSSettings(configMap, "sentinel.conf", k8sredis.ServerTLSOptions{
+			Settings:        sentinelInstance.Spec.TLSConfig,
+			Mode:            sentinelInstance.GetTLSConfig(replicaInstance).GetMode(),
+			ReplicationMode: replicaInstance.Spec.TLSConfig.GetMode(),
+			PlaintextPort:   sentinelInstance.GetPlaintextPort(),
+			TLSPort:         sentinelInstance.GetRedisPort(),
+		}); err != nil {
+			return err
+		}
 	}
 
-	return nil, true
+	return nil
 }
@@ -5,6 +5,8 @@ import (
 	"k8s.io/utils/ptr"
 	v1 "redis.operator/api/v1"
 	"redis.operator/pkg/kube/container"
+	"redis.operator/pkg/kube/dns"
+	"redis.operator/pkg/redisexporter"
 )
 
 func GetSecurityContext() *corev1.SecurityContext {
@@ -62,13 +64,36 @@ func CreateContainer(instance *v1.RedisSentinel, replicaInstance *v1.RedisReplic
 		}).
 		SetArgs([]string{"/tmp/redis/sentinel.conf", "--sentinel"})
 
-	if replicaInstance.Spec.TLSConfig != nil {
-		for _, volume := range replicaInstance.Spec.VolumeMounts {
-			if volume.Name == replicaInstance.Spec.TLSConfig.Name {
-				sentinelContainer.SetVolumeMount(volume)
-			}
+	sentinelContainer.SetVolumeMounts(instance.Spec.VolumeMounts)
+	if instance.Spec.TLSConfig == nil {
+		// borrow the tls files of the replication
+		sentinelContainer.SetVolumeMounts(GetTLSVolumeMounts(instance, replicaInstance))
+	}
+
+	containers := []corev1.Container{sentinelContainer.Build()}
+
+	if instance.IsExporterEnabled() {
+		passwordSecret, err := GetExporterPasswordSecret(instance)
+		if err != nil {
+			return nil, err
 		}
+
+		tlsConfig, err := GetTLSFiles(instance, replicaInstance)
+		if err != nil {
+			return nil, err
+		}
+
+		containers = append(containers, redisexporter.CreateContainer(redisexporter.Options{
+			Name:            instance.Name,
+			Config:          instance.Spec.Exporter,
+			HostName:        dns.GetPodDNS("$(POD_NAME)", instance.GetHeadlessServiceName(), instance.Namespace),
+			Port:            instance.GetRedisPort(),
+			PasswordSecret:  passwordSecret,
+			TLS:             tlsConfig,
+			VolumeMounts:    GetTLSVolumeMounts(instance, replicaInstance),
+			SecurityContext: GetSecurityContext(),
+		}))
 	}
 
-	return []corev1.Container{sentinelContainer.Build()}, nil
+	return containers, nil
 }
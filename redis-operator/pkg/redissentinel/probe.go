@@ -3,15 +3,16 @@ package redissentinel
 import (
 	corev1 "k8s.io/api/core/v1"
 	v1 "redis.operator/api/v1"
+	"redis.operator/pkg/config"
 	"redis.operator/pkg/kube/configmap"
 	"redis.operator/pkg/kube/probe"
 	"redis.operator/pkg/util/scripts"
 )
 
 func GetLivenessScript(instance *v1.RedisSentinel, replicaInstance *v1.RedisReplication) ([]string, error) {
-	if replicaInstance.Spec.TLSConfig != nil {
+	if instance.GetTLSConfig(replicaInstance) != nil {
 
-		tlsParams, err := replicaInstance.Spec.RedisConfig.GetConfigMapTLS()
+		tlsParams, err := GetTLSFiles(instance, replicaInstance)
 		if err != nil {
 			return nil, err
 		}
@@ -28,9 +29,9 @@ func GetLivenessScript(instance *v1.RedisSentinel, replicaInstance *v1.RedisRepl
 }
 
 func GetReadinessScript(instance *v1.RedisSentinel, replicaInstance *v1.RedisReplication) ([]string, error) {
-	if replicaInstance.Spec.TLSConfig != nil {
+	if instance.GetTLSConfig(replicaInstance) != nil {
 
-		tlsParams, err := replicaInstance.Spec.RedisConfig.GetConfigMapTLS()
+		tlsParams, err := GetTLSFiles(instance, replicaInstance)
 		if err != nil {
 			return nil, err
 		}
@@ -58,6 +59,7 @@ func GetLivenessProbe(instance *v1.RedisSentinel, replicaInstance *v1.RedisRepli
 		SetSuccessThreshold(1).
 		SetFailureThreshold(3).
 		SetExecAction(script).
+		SetTiming(config.Get().Probes.Liveness).
 		Build(), nil
 }
 
@@ -74,5 +76,6 @@ func GetReadinessProbe(instance *v1.RedisSentinel, replicaInstance *v1.RedisRepl
 		SetSuccessThreshold(1).
 		SetFailureThreshold(3).
 		SetExecAction(script).
+		SetTiming(config.Get().Probes.Readiness).
 		Build(), nil
 }
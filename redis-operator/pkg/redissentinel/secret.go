@@ -0,0 +1,51 @@
+package redissentinel
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	v1 "redis.operator/api/v1"
+)
+
+const AuthSecretPasswordKey = "password"
+
+// GetExporterPasswordSecret returns the secret key holding the password of the exporter, nil without requirepass
+func GetExporterPasswordSecret(instance *v1.RedisSentinel) (*corev1.SecretKeySelector, error) {
+	if instance.Spec.Exporter != nil && instance.Spec.Exporter.PasswordSecret != nil {
+		return instance.Spec.Exporter.PasswordSecret, nil
+	}
+
+	password, err := instance.Spec.RedisConfig.GetValue("sentinel.conf", "requirepass")
+	if err != nil || password == "" {
+		return nil, err
+	}
+
+	return &corev1.SecretKeySelector{
+		LocalObjectReference: corev1.LocalObjectReference{Name: instance.GetAuthSecretName()},
+		Key:                  AuthSecretPasswordKey,
+	}, nil
+}
+
+// CreateAuthSecret keeps requirepass in a secret so it doesn't end up as a literal in the pod spec
+func CreateAuthSecret(instance *v1.RedisSentinel) (*corev1.Secret, error) {
+	password, err := instance.Spec.RedisConfig.GetValue("sentinel.conf", "requirepass")
+	if err != nil {
+		return nil, err
+	}
+
+	return &corev1.Secret{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "Secret",
+			APIVersion: "v1",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            instance.GetAuthSecretName(),
+			Namespace:       instance.Namespace,
+			Labels:          GetSentinelServiceLabels(instance),
+			OwnerReferences: []metav1.OwnerReference{instance.GetOwnerReference()},
+		},
+		Type: corev1.SecretTypeOpaque,
+		StringData: map[string]string{
+			AuthSecretPasswordKey: password,
+		},
+	}, nil
+}
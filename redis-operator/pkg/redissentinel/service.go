@@ -19,7 +19,7 @@ func GetSentinelServiceLabels(instance *v1.RedisSentinel) map[string]string {
 	}
 }
 
-func CreateSentinelHeadlessService(instance *v1.RedisSentinel) corev1.Service {
+func CreateSentinelHeadlessService(instance *v1.RedisSentinel, replicaInstance *v1.RedisReplication) corev1.Service {
 
 	port := instance.GetRedisPortInt32()
 	labels := GetSentinelServiceLabels(instance)
@@ -39,11 +39,24 @@ func CreateSentinelHeadlessService(instance *v1.RedisSentinel) corev1.Service {
 			TargetPort: intstr.FromInt32(port),
 			Protocol:   corev1.ProtocolTCP,
 		})
+	if plaintextPort := getPlaintextServicePort(instance, replicaInstance); plaintextPort != nil {
+		serviceBuilder.SetPort(*plaintextPort)
+	}
+
+	if instance.IsExporterEnabled() {
+		exporterPort := instance.Spec.Exporter.GetPort()
+		serviceBuilder.SetPort(corev1.ServicePort{
+			Name:       "redis-exporter",
+			Port:       exporterPort,
+			TargetPort: intstr.FromInt32(exporterPort),
+			Protocol:   corev1.ProtocolTCP,
+		})
+	}
 
 	return serviceBuilder.Build()
 }
 
-func CreateSentinelService(instance *v1.RedisSentinel) corev1.Service {
+func CreateSentinelService(instance *v1.RedisSentinel, replicaInstance *v1.RedisReplication) corev1.Service {
 	port := instance.GetRedisPortInt32()
 	labels := GetSentinelServiceLabels(instance)
 
@@ -60,5 +73,22 @@ func CreateSentinelService(instance *v1.RedisSentinel) corev1.Service {
 			TargetPort: intstr.FromInt32(port),
 			Protocol:   corev1.ProtocolTCP,
 		})
+	if plaintextPort := getPlaintextServicePort(instance, replicaInstance); plaintextPort != nil {
+		serviceBuilder.SetPort(*plaintextPort)
+	}
 	return serviceBuilder.Build()
 }
+
+// getPlaintextServicePort returns the plaintext port exposed next to the tls port while clients migrate, nil outside of Dual mode
+func getPlaintextServicePort(instance *v1.RedisSentinel, replicaInstance *v1.RedisReplication) *corev1.ServicePort {
+	if !instance.GetTLSConfig(replicaInstance).IsDualMode() {
+		return nil
+	}
+	port := instance.GetPlaintextPortInt32()
+	return &corev1.ServicePort{
+		Name:       "sentinel-plaintext",
+		Port:       port,
+		TargetPort: intstr.FromInt32(port),
+		Protocol:   corev1.ProtocolTCP,
+	}
+}
@@ -5,6 +5,7 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	v1 "redis.operator/api/v1"
+	"redis.operator/pkg/redisexporter"
 )
 
 func CreateStatefulSet(instance *v1.RedisSentinel, replicaInstance *v1.RedisReplication, redisContainers []corev1.Container, initContainer corev1.Container) *appsv1.StatefulSet {
@@ -28,17 +29,30 @@ func CreateStatefulSet(instance *v1.RedisSentinel, replicaInstance *v1.RedisRepl
 		},
 	}
 
-	if replicaInstance.Spec.TLSConfig != nil {
+	if tlsConfig := instance.GetTLSConfig(replicaInstance); tlsConfig != nil {
 		volumes = append(volumes, corev1.Volume{
-			Name: replicaInstance.Spec.TLSConfig.Name,
+			Name: tlsConfig.Name,
 			VolumeSource: corev1.VolumeSource{
 				Secret: &corev1.SecretVolumeSource{
-					SecretName: replicaInstance.Spec.TLSConfig.SecretName,
+					SecretName: instance.GetTLSSecretName(replicaInstance),
 				},
 			},
 		})
 	}
 
+	if volume := redisexporter.GetScriptVolume(instance.Name, instance.Spec.Exporter); volume != nil && instance.IsExporterEnabled() {
+		volumes = append(volumes, *volume)
+	}
+
+	// sentinels roll for their own ports and when the replication flips tls-replication
+	annotations := map[string]string{}
+	if mode := instance.GetTLSConfig(replicaInstance).GetMode(); mode != "" {
+		annotations[v1.TLSModeAnnotation] = mode
+	}
+	if mode := replicaInstance.Spec.TLSConfig.GetMode(); mode != "" {
+		annotations[v1.TLSReplicationModeAnnotation] = mode
+	}
+
 	statefulSet := &appsv1.StatefulSet{
 		TypeMeta: metav1.TypeMeta{
 			Kind:       "StatefulSet",
@@ -56,7 +70,8 @@ func CreateStatefulSet(instance *v1.RedisSentinel, replicaInstance *v1.RedisRepl
 			},
 			Template: corev1.PodTemplateSpec{
 				ObjectMeta: metav1.ObjectMeta{
-					Labels: GetSentinelServiceLabels(instance),
+					Labels:      GetSentinelServiceLabels(instance),
+					Annotations: annotations,
 				},
 				Spec: corev1.PodSpec{
 					Volumes:                       append(volumes, instance.Spec.StatefulsetConfig.Wrapper.Spec.Template.Spec.Volumes...),
@@ -0,0 +1,39 @@
+package redissentinel
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	v1 "redis.operator/api/v1"
+)
+
+// GetTLSFiles returns the paths of the tls files used by the sentinel, nil without tls. Sentinels without tls
+// settings of their own use the files of the replication
+func GetTLSFiles(instance *v1.RedisSentinel, replicaInstance *v1.RedisReplication) (*v1.TLSConfig, error) {
+	if instance.Spec.TLSConfig != nil {
+		return instance.Spec.RedisConfig.GetSentinelConfigMapTLS()
+	}
+	if replicaInstance.Spec.TLSConfig != nil {
+		return replicaInstance.Spec.RedisConfig.GetConfigMapTLS()
+	}
+	return nil, nil
+}
+
+// GetTLSVolumeMounts returns the volume mounts holding the tls files of the sentinel
+func GetTLSVolumeMounts(instance *v1.RedisSentinel, replicaInstance *v1.RedisReplication) []corev1.VolumeMount {
+	tlsConfig := instance.GetTLSConfig(replicaInstance)
+	if tlsConfig == nil {
+		return nil
+	}
+
+	volumeMounts := replicaInstance.Spec.VolumeMounts
+	if instance.Spec.TLSConfig != nil {
+		volumeMounts = instance.Spec.VolumeMounts
+	}
+
+	tlsVolumeMounts := []corev1.VolumeMount{}
+	for _, volume := range volumeMounts {
+		if volume.Name == tlsConfig.Name {
+			tlsVolumeMounts = append(tlsVolumeMounts, volume)
+		}
+	}
+	return tlsVolumeMounts
+}